@@ -7,6 +7,14 @@ package configs
 // AWSConfigs defines authentication and credential settings for AWS services.
 // It contains the necessary parameters to authenticate with AWS APIs.
 type AWSConfigs struct {
+	// Region defines the default AWS region of the services
+	Region string
+	// Endpoint overrides the endpoint of the services (useful for LocalStack and local development)
+	Endpoint string
+	// Profile selects a named profile of the shared AWS configuration files
+	Profile string
+	// RoleARN is the ARN of the IAM role assumed through STS, when set
+	RoleARN string
 	// AccessKeyID is the AWS access key part of the credential pair
 	AccessKeyID string
 	// SecretAccessKey is the AWS secret key part of the credential pair
@@ -17,11 +25,16 @@ type AWSConfigs struct {
 
 // DynamoDBConfigs provides configuration settings specific to Amazon DynamoDB.
 // It contains connection and targeting parameters for DynamoDB operations.
+// Region, Endpoint, Profile and RoleARN fall back to the AWS settings when not set for DynamoDB.
 type DynamoDBConfigs struct {
 	// Endpoint specifies the DynamoDB service endpoint URL (useful for local development)
 	Endpoint string
 	// Region defines the AWS region where the DynamoDB table is located
 	Region string
+	// Profile selects a named profile of the shared AWS configuration files
+	Profile string
+	// RoleARN is the ARN of the IAM role assumed through STS to access DynamoDB, when set
+	RoleARN string
 	// Table specifies the default DynamoDB table name to use
 	Table string
 }
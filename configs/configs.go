@@ -17,9 +17,13 @@ type Configs struct {
 	Logger *zap.Logger
 
 	// Custom holds any application-specific configuration values
-	// that don't fit into the predefined categories
+	// that don't fit into the predefined categories, as bound by the configs builder
 	Custom map[string]string
 
+	// Provenance maps each configuration key read by the configs builder
+	// to the name of the source it was resolved from (e.g. env, flags, file:config.yaml)
+	Provenance map[string]string
+
 	// AppConfigs contains basic application configurations like environment and name
 	AppConfigs *AppConfigs
 	// HTTPConfigs provides configuration for HTTP servers/clients
@@ -18,6 +18,36 @@ type MQTTConfigs struct {
 	// Protocol specifies the MQTT protocol version to use (e.g., "mqtt", "mqtts")
 	Protocol string
 
+	// CleanSession controls whether the broker discards the session state (subscriptions and
+	// pending QoS 1/2 messages) when the client disconnects
+	CleanSession bool
+	// ClientIDSuffix is appended to the application name to build the MQTT client ID,
+	// allowing multiple replicas to keep their own persistent sessions
+	ClientIDSuffix string
+	// StorePath is the directory used to persist in-flight QoS 1/2 messages across restarts.
+	// When empty, the in-flight messages are kept in memory
+	StorePath string
+
+	// WillTopic is the topic where the broker publishes the Last Will and Testament message
+	// when the client disconnects ungracefully. The will is disabled when empty
+	WillTopic string
+	// WillPayload is the payload of the Last Will and Testament message
+	WillPayload string
+	// WillQoS is the QoS level (0, 1 or 2) used to publish the Last Will and Testament message
+	WillQoS byte
+	// WillRetain indicates if the broker should retain the Last Will and Testament message
+	WillRetain bool
+
+	// BirthTopic is the topic where the client publishes the birth message after every
+	// successful connection. The birth message is disabled when empty
+	BirthTopic string
+	// BirthPayload is the payload of the birth message
+	BirthPayload string
+	// BirthQoS is the QoS level (0, 1 or 2) used to publish the birth message
+	BirthQoS byte
+	// BirthRetain indicates if the broker should retain the birth message
+	BirthRetain bool
+
 	// RootCaPath is the file path to the root CA certificate for TLS verification
 	RootCaPath string
 	// CertPath is the file path to the client certificate for mutual TLS authentication
@@ -4,10 +4,14 @@
 
 package configs
 
+import "time"
+
 // SQLConfigs defines configuration parameters for SQL database connections.
 // It contains all the necessary information to establish and maintain
 // connections to SQL databases like PostgreSQL, MySQL, etc.
 type SQLConfigs struct {
+	// DSN is a full connection string or URL, used as it is instead of the connection settings below
+	DSN string
 	// Host specifies the database server hostname or IP address
 	Host string
 	// Port defines the network port on which the database server is listening
@@ -18,6 +22,35 @@ type SQLConfigs struct {
 	Password string
 	// DbName specifies the name of the database to connect to
 	DbName string
+
+	// SSLMode defines the SSL negotiation with the server (disable, allow, prefer, require, verify-ca or verify-full)
+	SSLMode string
+	// SSLRootCert is the path of the certificate authorities file used to verify the server certificate
+	SSLRootCert string
+	// SSLCert is the path of the client certificate file
+	SSLCert string
+	// SSLKey is the path of the client certificate private key file
+	SSLKey string
+
+	// ConnectTimeout limits the time to establish a connection, zero waits indefinitely
+	ConnectTimeout time.Duration
+	// StatementTimeout aborts the statements running longer than the timeout, zero disables it
+	StatementTimeout time.Duration
+	// SearchPath sets the schema search path of the connections
+	SearchPath string
+
+	// MaxOpenConns limits the number of open connections of the pool, zero means unlimited
+	MaxOpenConns int
+	// MaxIdleConns limits the number of idle connections kept by the pool, zero keeps the database/sql default
+	MaxIdleConns int
+	// ConnMaxLifetime closes the connections older than the duration, zero keeps them open indefinitely
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime closes the connections idle for longer than the duration, zero keeps them open indefinitely
+	ConnMaxIdleTime time.Duration
+
+	// ReplicaHosts lists the read replicas, as host or host:port, sharing the settings of the primary
+	ReplicaHosts []string
+
 	// SecondsToPing defines the interval in seconds between health check pings
 	// to verify the database connection remains active
 	SecondsToPing int
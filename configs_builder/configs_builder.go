@@ -3,18 +3,28 @@
 // All rights reserved.
 
 // Package configsbuilder provides a fluent interface for building application configurations.
-// It simplifies the process of loading configurations from environment variables and .env files
-// for various components of an application such as HTTP, messaging, databases, etc.
+// It simplifies the process of loading configurations from layered sources (defaults, files,
+// .env files, environment variables, command line flags and secrets) for various components
+// of an application such as HTTP, messaging, databases, etc.
 package configsbuilder
 
 import (
-	"github.com/joho/godotenv"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"time"
+
 	"github.com/ralvescosta/gokit/configs"
 	"github.com/ralvescosta/gokit/logging"
+	secretsmanager "github.com/ralvescosta/gokit/secrets_manager"
 	"go.uber.org/zap"
 
 	"github.com/ralvescosta/gokit/configs_builder/errors"
 	"github.com/ralvescosta/gokit/configs_builder/internal"
+	"github.com/ralvescosta/gokit/configs_builder/keys"
+	"github.com/ralvescosta/gokit/configs_builder/sources"
 )
 
 type (
@@ -35,12 +45,32 @@ type (
 		MQTT() ConfigsBuilder
 		// RabbitMQ enables RabbitMQ configuration loading
 		RabbitMQ() ConfigsBuilder
+		// Kafka enables Kafka configuration loading
+		Kafka() ConfigsBuilder
 		// AWS enables AWS configuration loading
 		AWS() ConfigsBuilder
 		// DynamoDB enables DynamoDB configuration loading
 		DynamoDB() ConfigsBuilder
+		// Custom binds the application-specific configurations into the struct pointed by target
+		Custom(target any) ConfigsBuilder
+		// WithDefaults sets the default values, overridden by every other source
+		WithDefaults(values map[string]string) ConfigsBuilder
+		// WithFiles adds YAML, TOML or JSON configuration files, the last files taking precedence
+		WithFiles(paths ...string) ConfigsBuilder
+		// WithFlags sets the command line arguments read as flags, e.g. os.Args[1:]
+		WithFlags(args []string) ConfigsBuilder
+		// WithSecrets sets the secret client overlaying the credentials, instead of the AWS Secrets Manager client
+		WithSecrets(client sources.SecretClient) ConfigsBuilder
+		// WithSecretKeys maps additional configuration keys to the names of the secrets holding them
+		WithSecretKeys(keys map[string]string) ConfigsBuilder
+		// WithWatchInterval sets the interval the Watcher checks the configuration files for changes
+		WithWatchInterval(interval time.Duration) ConfigsBuilder
 		// Build processes all enabled configurations and returns the complete config object
 		Build() (*configs.Configs, error)
+		// Watch builds the configurations and rebuilds them on SIGHUP or when the configuration files change
+		Watch(ctx context.Context) (Watcher, error)
+		// Validate prints the keys read by the enabled configurations, without building them
+		Validate() error
 	}
 
 	// configsBuilder implements the ConfigsBuilder interface and tracks which configurations to load
@@ -54,11 +84,41 @@ type (
 		identity bool
 		mqtt     bool
 		rabbitmq bool
+		kafka    bool
 		aws      bool
 		dynamoDB bool
+		custom   []any
+
+		defaults   map[string]string
+		files      []string
+		args       []string
+		secrets    sources.SecretClient
+		secretKeys map[string]string
+		interval   time.Duration
+
+		// exported holds the dotenv values exported to the process environment
+		exported map[string]string
+	}
+
+	// secretsLoader is implemented by the secret clients loading the secrets before they are read,
+	// such as the secrets_manager clients
+	secretsLoader interface {
+		LoadSecrets(ctx context.Context) error
 	}
 )
 
+// defaultSecretKeys maps the credentials overlaid by the secrets to the names of the secrets holding them
+var defaultSecretKeys = map[string]string{
+	keys.SQLDbPasswordEnvKey:        keys.SQLDbPasswordEnvKey,
+	keys.RabbitUserEnvKey:           keys.RabbitUserEnvKey,
+	keys.RabbitPasswordEnvKey:       keys.RabbitPasswordEnvKey,
+	keys.MQTTUserEnvKey:             keys.MQTTUserEnvKey,
+	keys.MQTTPasswordEnvKey:         keys.MQTTPasswordEnvKey,
+	keys.KafkaUserEnvKey:            keys.KafkaUserEnvKey,
+	keys.KafkaPasswordEnvKey:        keys.KafkaPasswordEnvKey,
+	keys.IdentityClientSecretEnvKey: keys.IdentityClientSecretEnvKey,
+}
+
 // NewConfigsBuilder creates a new instance of ConfigsBuilder with no configurations enabled
 func NewConfigsBuilder() ConfigsBuilder {
 	return &configsBuilder{}
@@ -106,6 +166,12 @@ func (b *configsBuilder) RabbitMQ() ConfigsBuilder {
 	return b
 }
 
+// Kafka enables Kafka configuration loading in the builder
+func (b *configsBuilder) Kafka() ConfigsBuilder {
+	b.kafka = true
+	return b
+}
+
 // AWS enables AWS configuration loading in the builder
 func (b *configsBuilder) AWS() ConfigsBuilder {
 	b.aws = true
@@ -118,102 +184,300 @@ func (b *configsBuilder) DynamoDB() ConfigsBuilder {
 	return b
 }
 
-// Build processes all enabled configurations and returns the complete configs object.
-// It reads environment variables, loads .env files, and constructs the configuration
-// based on the enabled features. Returns an error if any configuration fails to load.
-func (b *configsBuilder) Build() (*configs.Configs, error) {
-	// Determine the runtime environment
-	env := internal.ReadEnvironment()
-	if env == configs.UnknownEnv {
-		return nil, errors.ErrUnknownEnv
+// Custom adds a struct to be bound with the application-specific configurations.
+// The fields are bound from the configuration sources through the struct tags:
+//
+//	type OrdersConfigs struct {
+//		BatchSize int            `env:"ORDERS_BATCH_SIZE" default:"100" required:"true"`
+//		Timeout   time.Duration  `env:"ORDERS_TIMEOUT" default:"30s"`
+//		Regions   []string       `env:"ORDERS_REGIONS"`               // eu-west-1,us-east-1
+//		Limits    map[string]int `env:"ORDERS_LIMITS" separator:";"` // gold:100;silver:10
+//		Retry     RetryConfigs   `env:"ORDERS_RETRY"`                // ORDERS_RETRY_<field key>
+//	}
+//
+// Durations, slices, maps, pointers, nested structs and types implementing encoding.TextUnmarshaler
+// are supported. Structs implementing Validate() error are validated after binding. The bound values
+// are also recorded in Configs.Custom. Build returns the errors in the same format as the other
+// configurations, e.g. "configs builder error - ORDERS_BATCH_SIZE is required".
+func (b *configsBuilder) Custom(target any) ConfigsBuilder {
+	b.custom = append(b.custom, target)
+	return b
+}
+
+// WithDefaults sets the default values in the builder, with the lowest precedence
+func (b *configsBuilder) WithDefaults(values map[string]string) ConfigsBuilder {
+	b.defaults = values
+	return b
+}
+
+// WithFiles adds configuration files in the builder. The files are layered after the defaults,
+// the last files taking precedence over the first ones
+func (b *configsBuilder) WithFiles(paths ...string) ConfigsBuilder {
+	b.files = append(b.files, paths...)
+	return b
+}
+
+// WithFlags sets the command line arguments in the builder, taking precedence over the environment variables
+func (b *configsBuilder) WithFlags(args []string) ConfigsBuilder {
+	b.args = args
+	return b
+}
+
+// WithSecrets sets the secret client in the builder, used instead of the AWS Secrets Manager client.
+// The secrets overlay the credentials whenever a client is set, with the highest precedence
+func (b *configsBuilder) WithSecrets(client sources.SecretClient) ConfigsBuilder {
+	b.secrets = client
+	return b
+}
+
+// WithSecretKeys maps configuration keys to the names of the secrets holding them, in addition to
+// the default credentials: SQL_DB_PASSWORD, RABBIT_USER, RABBIT_PASSWORD, MQTT_USER, MQTT_PASSWORD,
+// KAFKA_USER, KAFKA_PASSWORD and IDENTITY_CLIENT_SECRET, each read from the secret of the same name
+func (b *configsBuilder) WithSecretKeys(keys map[string]string) ConfigsBuilder {
+	if b.secretKeys == nil {
+		b.secretKeys = map[string]string{}
 	}
 
-	// Load environment-specific .env file
-	err := dotEnvConfig(".env." + env.ToString())
+	maps.Copy(b.secretKeys, keys)
+	return b
+}
+
+// WithWatchInterval sets the interval the Watcher checks the configuration and dotenv files for changes,
+// DefaultWatchInterval when not set
+func (b *configsBuilder) WithWatchInterval(interval time.Duration) ConfigsBuilder {
+	b.interval = interval
+	return b
+}
+
+// Build processes all enabled configurations and returns the complete configs object.
+// It layers the configuration sources, reads the values of the enabled features and records
+// the source of every value in Configs.Provenance. Returns an error if the sources cannot be loaded,
+// or an *errors.ValidationErrors listing every missing or invalid value of the enabled features.
+func (b *configsBuilder) Build() (*configs.Configs, error) {
+	cfgs, reader, err := b.read(b.custom)
 	if err != nil {
 		return nil, err
 	}
 
-	cfgs := configs.Configs{}
-
-	// Load application base configurations
-	cfgs.AppConfigs = internal.ReadAppConfigs()
-	cfgs.AppConfigs.GoEnv = env
+	if err := reader.Err(); err != nil {
+		return nil, err
+	}
 
 	// Initialize the logger
-	logger, err := logging.NewDefaultLogger(&cfgs)
+	logger, err := logging.NewDefaultLogger(cfgs)
 	if err != nil {
 		return nil, err
 	}
 
 	cfgs.Logger = logger.(*zap.Logger)
 
-	// Load component-specific configurations based on what was enabled
+	return cfgs, nil
+}
+
+// Validate reads the configurations of the enabled features as Build does, without initializing them,
+// and prints a table of every key read with its requirement, resolved value, default value, source and
+// status. The values of sensitive keys, such as passwords, are masked. Returns the same errors as Build.
+func (b *configsBuilder) Validate() error {
+	_, reader, err := b.read(b.custom)
+	if err != nil {
+		return err
+	}
+
+	if err := reader.WriteTable(validationOutput); err != nil {
+		return err
+	}
+
+	return reader.Err()
+}
+
+// read loads the configuration sources and reads the configurations of the enabled features,
+// binding the custom configurations into the targets.
+// The missing and invalid values are collected by the returned reader rather than failing on the first one.
+func (b *configsBuilder) read(targets []any) (*configs.Configs, *internal.Reader, error) {
+	src, env, err := b.loadSources()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader := internal.NewReader(src)
+	cfgs := configs.Configs{}
+
+	// Load application base configurations
+	cfgs.AppConfigs = internal.ReadAppConfigs(reader)
+	cfgs.AppConfigs.GoEnv = env
+
+	// Load component-specific configurations based on what was enabled.
+	// The errors of each component are aggregated by the reader.
 	if b.http {
-		cfgs.HTTPConfigs, err = internal.ReadHTTPConfigs()
-		if err != nil {
-			return nil, err
-		}
+		cfgs.HTTPConfigs, _ = internal.ReadHTTPConfigs(reader)
 	}
 
 	if b.metrics {
-		cfgs.MetricsConfigs, err = internal.ReadMetricsConfigs()
-		if err != nil {
-			return nil, err
-		}
+		cfgs.MetricsConfigs, _ = internal.ReadMetricsConfigs(reader)
 	}
 
 	if b.tracing {
-		cfgs.TracingConfigs, err = internal.ReadTracingConfigs()
-		if err != nil {
-			return nil, err
-		}
+		cfgs.TracingConfigs, _ = internal.ReadTracingConfigs(reader)
 	}
 
 	if b.sql {
-		cfgs.SQLConfigs, err = internal.ReadSQLDatabaseConfigs()
-		if err != nil {
-			return nil, err
-		}
+		cfgs.SQLConfigs, _ = internal.ReadSQLDatabaseConfigs(reader)
 	}
 
 	if b.identity {
-		cfgs.IdentityConfigs, err = internal.ReadIdentityConfigs()
-		if err != nil {
-			return nil, err
-		}
+		cfgs.IdentityConfigs, _ = internal.ReadIdentityConfigs(reader)
 	}
 
 	if b.mqtt {
-		cfgs.MQTTConfigs, err = internal.ReadMQTTConfigs()
-		if err != nil {
-			return nil, err
-		}
+		cfgs.MQTTConfigs, _ = internal.ReadMQTTConfigs(reader)
 	}
 
 	if b.rabbitmq {
-		cfgs.RabbitMQConfigs, err = internal.ReadRabbitMQConfigs()
-		if err != nil {
-			return nil, err
-		}
+		cfgs.RabbitMQConfigs, _ = internal.ReadRabbitMQConfigs(reader)
+	}
+
+	if b.kafka {
+		cfgs.KafkaConfigs, _ = internal.ReadKafkaConfigs(reader)
 	}
 
 	if b.aws {
-		cfgs.AWSConfigs, err = internal.ReadAWSConfigs()
+		cfgs.AWSConfigs, _ = internal.ReadAWSConfigs(reader)
+	}
+
+	if b.dynamoDB {
+		cfgs.DynamoDBConfigs, _ = internal.ReadDynamoDBConfigs(reader)
+	}
+
+	if len(targets) > 0 {
+		cfgs.Custom = map[string]string{}
+	}
+
+	for _, target := range targets {
+		_ = internal.ReadCustomConfigs(reader, target, cfgs.Custom)
+	}
+
+	cfgs.Provenance = src.Provenance()
+
+	// The custom values not set by any source come from the default struct tags
+	for key := range cfgs.Custom {
+		if _, ok := cfgs.Provenance[key]; !ok {
+			cfgs.Provenance[key] = sources.DefaultsSourceName
+		}
+	}
+
+	return &cfgs, reader, nil
+}
+
+// loadSources layers the configuration sources, from the lowest to the highest precedence:
+// defaults, files, the .env.<GO_ENV> dotenv file, environment variables, flags and secrets.
+// The runtime environment selecting the dotenv file is read from the other sources.
+// The dotenv values are also exported to the process environment, without overriding it,
+// so the libraries reading the environment directly (e.g. the AWS SDK) still see them.
+// The exported values are not read back as environment variables, so the dotenv changes
+// are applied when the sources are loaded again by the Watcher.
+func (b *configsBuilder) loadSources() (*sources.Layered, configs.Environment, error) {
+	files := make([]sources.Source, 0, len(b.files))
+	for _, path := range b.files {
+		file, err := sources.File(path)
 		if err != nil {
-			return nil, err
+			return nil, configs.UnknownEnv, err
 		}
+		files = append(files, file)
 	}
 
-	if b.dynamoDB {
-		cfgs.DynamoDBConfigs, err = internal.ReadDynamoDBConfigs()
+	var defaults, flags sources.Source
+	if b.defaults != nil {
+		defaults = sources.Defaults(b.defaults)
+	}
+	if b.args != nil {
+		flags = sources.Flags(b.args)
+	}
+
+	envValues := sources.Values(sources.Env())
+	for k, v := range b.exported {
+		if envValues[k] == v {
+			delete(envValues, k)
+		}
+	}
+	env := sources.Map(sources.EnvSourceName, envValues)
+
+	// Determine the runtime environment
+	goEnv := internal.ReadEnvironment(sources.NewLayered(append(append([]sources.Source{defaults}, files...), env, flags)...))
+	if goEnv == configs.UnknownEnv {
+		return nil, configs.UnknownEnv, errors.ErrUnknownEnv
+	}
+
+	// Load environment-specific .env file
+	dotEnv, err := sources.DotEnv(".env." + goEnv.ToString())
+	if err != nil {
+		return nil, configs.UnknownEnv, err
+	}
+
+	if b.exported == nil {
+		b.exported = map[string]string{}
+	}
+
+	for k, v := range sources.Values(dotEnv) {
+		if _, ok := envValues[k]; !ok {
+			_ = os.Setenv(k, v)
+			b.exported[k] = v
+		}
+	}
+
+	layers := append(append([]sources.Source{defaults}, files...), dotEnv, env, flags)
+
+	secrets, err := b.loadSecrets(sources.NewLayered(layers...), goEnv)
+	if err != nil {
+		return nil, configs.UnknownEnv, err
+	}
+
+	return sources.NewLayered(append(layers, secrets)...), goEnv, nil
+}
+
+// loadSecrets creates the source overlaying the credentials with the secrets, when a secret client
+// was set or USE_SECRET_MANAGER is true. Without a client set, the AWS Secrets Manager client is created,
+// reading the <GO_ENV>/<SECRET_KEY> secret. Clients implementing LoadSecrets are loaded before being read.
+// Returns a nil source when the secrets are disabled.
+func (b *configsBuilder) loadSecrets(src *sources.Layered, env configs.Environment) (sources.Source, error) {
+	appConfigs := internal.ReadAppConfigs(internal.NewReader(src))
+	if b.secrets == nil && !appConfigs.UseSecretManager {
+		return nil, nil
+	}
+
+	client := b.secrets
+	if client == nil {
+		appConfigs.GoEnv = env
+		cfgs := &configs.Configs{AppConfigs: appConfigs}
+
+		logger, err := logging.NewDefaultLogger(cfgs)
 		if err != nil {
 			return nil, err
 		}
+
+		cfgs.Logger = logger.(*zap.Logger)
+
+		awsClient, err := newSecretClient(cfgs)
+		if err != nil {
+			return nil, errors.NewConfigsError(fmt.Sprintf("failure to create the secret client: %s", err))
+		}
+
+		client = awsClient
+	}
+
+	if loader, ok := client.(secretsLoader); ok {
+		if err := loader.LoadSecrets(context.Background()); err != nil {
+			return nil, errors.NewConfigsError(fmt.Sprintf("failure to load the secrets: %s", err))
+		}
 	}
 
-	return &cfgs, nil
+	secretKeys := maps.Clone(defaultSecretKeys)
+	maps.Copy(secretKeys, b.secretKeys)
+
+	return sources.Secrets(client, secretKeys), nil
 }
 
-// dotEnvConfig is a variable containing the godotenv.Load function, which allows for testing
-var dotEnvConfig = godotenv.Load
+// newSecretClient is a variable containing the secretsmanager.NewAwsSecretClient function, which allows for testing
+var newSecretClient = secretsmanager.NewAwsSecretClient
+
+// validationOutput is the writer of the Validate table, which allows for testing
+var validationOutput io.Writer = os.Stdout
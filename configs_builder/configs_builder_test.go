@@ -0,0 +1,182 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package configsbuilder
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/ralvescosta/gokit/configs"
+	secretsmanager "github.com/ralvescosta/gokit/secrets_manager"
+	"github.com/stretchr/testify/suite"
+
+	cerrors "github.com/ralvescosta/gokit/configs_builder/errors"
+	"github.com/ralvescosta/gokit/configs_builder/sources"
+)
+
+type (
+	ConfigsBuilderTestSuite struct {
+		suite.Suite
+	}
+
+	secretClientFake struct {
+		secrets map[string]string
+		loaded  bool
+		err     error
+	}
+)
+
+func TestConfigsBuilderTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigsBuilderTestSuite))
+}
+
+func (f *secretClientFake) LoadSecrets(_ context.Context) error {
+	f.loaded = true
+	return f.err
+}
+
+func (f *secretClientFake) GetSecret(_ context.Context, key string) (string, error) {
+	if v, ok := f.secrets[key]; ok {
+		return v, nil
+	}
+
+	return "", errors.New("secret was not found")
+}
+
+func (s *ConfigsBuilderTestSuite) SetupTest() {
+	s.T().Setenv("GO_ENV", "local")
+	s.T().Setenv("LOG_LEVEL", "error")
+	s.T().Setenv("SQL_DB_HOST", "localhost")
+	s.T().Setenv("SQL_DB_PORT", "5432")
+	s.T().Setenv("SQL_DB_USER", "user")
+	s.T().Setenv("SQL_DB_PASSWORD", "env-password")
+	s.T().Setenv("SQL_DB_NAME", "db")
+	s.T().Setenv("SQL_DB_SECONDS_TO_PING", "10")
+	s.T().Setenv("RABBIT_HOST", "localhost")
+	s.T().Setenv("RABBIT_PORT", "5672")
+	s.T().Setenv("RABBIT_USER", "guest")
+	s.T().Setenv("RABBIT_PASSWORD", "guest")
+}
+
+func (s *ConfigsBuilderTestSuite) TestBuild() {
+	cfgs, err := NewConfigsBuilder().
+		WithDefaults(map[string]string{"APP_NAME": "orders"}).
+		WithFlags([]string{"--sql-db-name=orders"}).
+		SQLDatabase().
+		Build()
+
+	s.Require().NoError(err)
+	s.Equal(configs.LocalEnv, cfgs.AppConfigs.GoEnv)
+	s.Equal("orders", cfgs.AppConfigs.AppName)
+	s.Equal("orders", cfgs.SQLConfigs.DbName)
+	s.Equal("env-password", cfgs.SQLConfigs.Password)
+	s.NotNil(cfgs.Logger)
+
+	s.Equal(sources.DefaultsSourceName, cfgs.Provenance["APP_NAME"])
+	s.Equal(sources.FlagsSourceName, cfgs.Provenance["SQL_DB_NAME"])
+	s.Equal(sources.EnvSourceName, cfgs.Provenance["SQL_DB_HOST"])
+}
+
+func (s *ConfigsBuilderTestSuite) TestBuildAggregatesErrors() {
+	s.T().Setenv("SQL_DB_HOST", "")
+	s.T().Setenv("RABBIT_PORT", "")
+
+	_, err := NewConfigsBuilder().
+		SQLDatabase().
+		RabbitMQ().
+		Build()
+
+	var validationErrs *cerrors.ValidationErrors
+	s.Require().ErrorAs(err, &validationErrs)
+	s.Equal([]*cerrors.ConfigError{
+		cerrors.NewRequiredConfigError("sql", "SQL_DB_HOST"),
+		cerrors.NewRequiredConfigError("rabbitmq", "RABBIT_PORT"),
+	}, validationErrs.Errors)
+}
+
+func (s *ConfigsBuilderTestSuite) TestValidate() {
+	out := bytes.Buffer{}
+	validationOutput = &out
+	defer func() { validationOutput = os.Stdout }()
+
+	s.T().Setenv("RABBIT_HOST", "")
+
+	err := NewConfigsBuilder().RabbitMQ().Validate()
+
+	s.Error(err)
+	s.Contains(out.String(), "RABBIT_HOST")
+	s.Contains(out.String(), "is required")
+	s.Contains(out.String(), "******")
+}
+
+func (s *ConfigsBuilderTestSuite) TestSecretsOverlay() {
+	s.T().Setenv("USE_SECRET_MANAGER", "true")
+	s.T().Setenv("SECRET_KEY", "orders")
+
+	client := &secretClientFake{secrets: map[string]string{
+		"SQL_DB_PASSWORD": "secret-password",
+		"rabbit_password": "secret-rabbit",
+		"SQL_DB_HOST":     "not-overlaid",
+	}}
+
+	newSecretClient = func(cfgs *configs.Configs) (secretsmanager.SecretClient, error) {
+		s.Equal("orders", cfgs.AppConfigs.SecretKey)
+		s.NotNil(cfgs.Logger)
+		return client, nil
+	}
+	defer func() { newSecretClient = secretsmanager.NewAwsSecretClient }()
+
+	cfgs, err := NewConfigsBuilder().
+		WithSecretKeys(map[string]string{"RABBIT_PASSWORD": "rabbit_password"}).
+		SQLDatabase().
+		RabbitMQ().
+		Build()
+
+	s.Require().NoError(err)
+	s.True(client.loaded)
+	s.Equal("secret-password", cfgs.SQLConfigs.Password)
+	s.Equal("secret-rabbit", cfgs.RabbitMQConfigs.Password)
+	s.Equal("localhost", cfgs.SQLConfigs.Host)
+	s.Equal(sources.SecretsSourceName, cfgs.Provenance["SQL_DB_PASSWORD"])
+}
+
+func (s *ConfigsBuilderTestSuite) TestSecretsWithClient() {
+	client := &secretClientFake{secrets: map[string]string{"SQL_DB_PASSWORD": "secret-password"}}
+
+	cfgs, err := NewConfigsBuilder().
+		WithSecrets(client).
+		SQLDatabase().
+		Build()
+
+	s.Require().NoError(err)
+	s.True(client.loaded)
+	s.Equal("secret-password", cfgs.SQLConfigs.Password)
+}
+
+func (s *ConfigsBuilderTestSuite) TestSecretsDisabled() {
+	client := &secretClientFake{secrets: map[string]string{"SQL_DB_PASSWORD": "secret-password"}}
+
+	newSecretClient = func(_ *configs.Configs) (secretsmanager.SecretClient, error) {
+		return client, nil
+	}
+	defer func() { newSecretClient = secretsmanager.NewAwsSecretClient }()
+
+	cfgs, err := NewConfigsBuilder().SQLDatabase().Build()
+
+	s.Require().NoError(err)
+	s.False(client.loaded)
+	s.Equal("env-password", cfgs.SQLConfigs.Password)
+}
+
+func (s *ConfigsBuilderTestSuite) TestSecretsLoadFailure() {
+	client := &secretClientFake{err: errors.New("access denied")}
+
+	_, err := NewConfigsBuilder().WithSecrets(client).SQLDatabase().Build()
+
+	s.EqualError(err, "configs builder error - failure to load the secrets: access denied")
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package errors
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Reasons of the configuration errors
+const (
+	// RequiredReason is the reason of a missing required configuration
+	RequiredReason = "is required"
+	// InvalidReason prefixes the reason of a configuration with an invalid value
+	InvalidReason = "is invalid"
+)
+
+type (
+	// ConfigError describes a missing or invalid configuration value of a component
+	ConfigError struct {
+		// Key is the configuration key, e.g. SQL_DB_HOST
+		Key string
+		// Component is the configuration component reading the key, e.g. sql
+		Component string
+		// Reason describes the failure, e.g. "is required"
+		Reason string
+	}
+
+	// ValidationErrors aggregates every missing or invalid configuration value found
+	// while building the configurations, so they can all be fixed at once
+	ValidationErrors struct {
+		Errors []*ConfigError
+	}
+)
+
+// NewRequiredConfigError creates a ConfigError for a missing required configuration
+func NewRequiredConfigError(component, key string) *ConfigError {
+	return &ConfigError{Key: key, Component: component, Reason: RequiredReason}
+}
+
+// NewInvalidConfigError creates a ConfigError for a configuration with an invalid value
+func NewInvalidConfigError(component, key, reason string) *ConfigError {
+	return &ConfigError{Key: key, Component: component, Reason: fmt.Sprintf("%s: %s", InvalidReason, reason)}
+}
+
+// Error implements the error interface for ConfigError, in the same format as NewErrRequiredConfig
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("configs builder error - %s", e.describe())
+}
+
+// describe returns the key followed by the reason, or only the reason when the error is not related to a key
+func (e *ConfigError) describe() string {
+	if e.Key == "" {
+		return e.Reason
+	}
+
+	return fmt.Sprintf("%s %s", e.Key, e.Reason)
+}
+
+// Error implements the error interface for ValidationErrors, listing one error per line
+func (e *ValidationErrors) Error() string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "configs builder error - %d invalid configurations:", len(e.Errors))
+
+	for _, err := range e.Errors {
+		fmt.Fprintf(&b, "\n\t- [%s] %s", err.Component, err.describe())
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the aggregated errors, supporting errors.Is and errors.As
+func (e *ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
@@ -4,11 +4,75 @@
 
 package internal
 
-import "github.com/ralvescosta/gokit/configs"
+import (
+	"net/url"
+	"strings"
 
-// ReadAWSConfigs retrieves AWS configuration from environment variables.
-// Currently a placeholder that will be implemented in future versions to provide
-// comprehensive AWS service configuration.
-func ReadAWSConfigs() (*configs.AWSConfigs, error) {
-	return nil, nil
+	"github.com/ralvescosta/gokit/configs"
+	keys "github.com/ralvescosta/gokit/configs_builder/keys"
+)
+
+// ReadAWSConfigs retrieves AWS configuration from the configuration sources.
+// Every value is optional, the AWS SDK resolving the missing ones from its default chain
+// (shared configuration files, instance roles, etc.). Reports an invalid endpoint or role ARN,
+// and static credentials missing the access key ID or the secret access key.
+func ReadAWSConfigs(r *Reader) (*configs.AWSConfigs, error) {
+	c := r.component(AWSComponent)
+	awsConfigs := configs.AWSConfigs{}
+
+	awsConfigs.Region = c.optional(keys.AWSRegionEnvKey, "")
+	awsConfigs.Endpoint = readEndpoint(c, keys.AWSEndpointEnvKey, "")
+	awsConfigs.Profile = c.optional(keys.AWSProfileEnvKey, "")
+	awsConfigs.RoleARN = readRoleARN(c, keys.AWSRoleARNEnvKey, "")
+
+	// Static credentials, both the access key ID and the secret access key must be set
+	awsConfigs.AccessKeyID = c.optional(keys.AWSAccessKeyIDEnvKey, "")
+	awsConfigs.SecretAccessKey = c.optional(keys.AWSSecretAccessKeyEnvKey, "")
+	awsConfigs.SessionToken = c.optional(keys.AWSSessionTokenEnvKey, "")
+
+	if awsConfigs.AccessKeyID != "" && awsConfigs.SecretAccessKey == "" {
+		c.invalid(keys.AWSSecretAccessKeyEnvKey, "must be set along with "+keys.AWSAccessKeyIDEnvKey)
+	}
+
+	if awsConfigs.AccessKeyID == "" && awsConfigs.SecretAccessKey != "" {
+		c.invalid(keys.AWSAccessKeyIDEnvKey, "must be set along with "+keys.AWSSecretAccessKeyEnvKey)
+	}
+
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+
+	return &awsConfigs, nil
+}
+
+// readEndpoint reads an optional endpoint override, reporting the key as invalid
+// if the value is not an absolute URL.
+func readEndpoint(c *componentReader, key, def string) string {
+	value := c.optional(key, def)
+	if value == "" {
+		return ""
+	}
+
+	if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+		c.invalid(key, "must be an absolute URL")
+		return ""
+	}
+
+	return value
+}
+
+// readRoleARN reads an optional IAM role ARN, reporting the key as invalid
+// if the value is not an ARN.
+func readRoleARN(c *componentReader, key, def string) string {
+	value := c.optional(key, def)
+	if value == "" {
+		return ""
+	}
+
+	if !strings.HasPrefix(value, "arn:") {
+		c.invalid(key, "must be an IAM role ARN")
+		return ""
+	}
+
+	return value
 }
@@ -0,0 +1,248 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package internal
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs_builder/errors"
+)
+
+// Struct tags read when binding custom configurations
+const (
+	// EnvTag names the configuration key of a field, or the key prefix of a nested struct
+	EnvTag = "env"
+	// DefaultTag sets the value used when no source sets the key
+	DefaultTag = "default"
+	// RequiredTag marks the field as required when set to true
+	RequiredTag = "required"
+	// SeparatorTag sets the separator of slice items and map entries, a comma by default
+	SeparatorTag = "separator"
+)
+
+type validator interface {
+	Validate() error
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ReadCustomConfigs binds the configuration sources into the struct pointed by target, using the
+// env, default, required and separator struct tags. Nested structs are bound recursively, prefixing
+// their keys with the env tag of the struct field, when present. The bound keys and values are
+// recorded in values. After binding, structs implementing Validate() error are validated.
+// Returns an error listing the required values that are missing, the values that cannot be parsed
+// and the validation failures.
+func ReadCustomConfigs(r *Reader, target any, values map[string]string) error {
+	c := r.component(CustomComponent)
+
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		c.fail(&errors.ConfigError{Component: c.name, Reason: fmt.Sprintf("must be a non-nil pointer to a struct, got %T", target)})
+		return c.err()
+	}
+
+	bindStruct(c, "", v.Elem(), values)
+
+	return c.err()
+}
+
+// bindStruct binds the exported fields of the struct, then validates it.
+func bindStruct(c *componentReader, prefix string, v reflect.Value, values map[string]string) {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, tagged := field.Tag.Lookup(EnvTag)
+		if key == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		if isNestedStruct(field.Type) {
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					fv.Set(reflect.New(field.Type.Elem()))
+				}
+				fv = fv.Elem()
+			}
+
+			nestedPrefix := prefix
+			if tagged && key != "" {
+				nestedPrefix = prefix + key + "_"
+			}
+
+			bindStruct(c, nestedPrefix, fv, values)
+			continue
+		}
+
+		if !tagged || key == "" {
+			continue
+		}
+
+		key = prefix + key
+
+		required, _ := strconv.ParseBool(field.Tag.Get(RequiredTag))
+
+		value := c.read(key, required, field.Tag.Get(DefaultTag))
+		if value == "" {
+			continue
+		}
+
+		separator := field.Tag.Get(SeparatorTag)
+		if separator == "" {
+			separator = ","
+		}
+
+		if err := setValue(fv, value, separator); err != nil {
+			c.invalid(key, err.Error())
+			continue
+		}
+
+		values[key] = value
+	}
+
+	if val, ok := v.Addr().Interface().(validator); ok {
+		if err := val.Validate(); err != nil {
+			c.fail(&errors.ConfigError{Component: c.name, Reason: fmt.Sprintf("%s validation failed: %s", t.Name(), err)})
+		}
+	}
+}
+
+// isNestedStruct reports whether the type is a struct, or a pointer to a struct, bound field by field.
+// Structs unmarshaling themselves from text, such as time.Time, are bound as single values.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	return !reflect.PointerTo(t).Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem())
+}
+
+// setValue parses the raw value into v, according to its type.
+func setValue(v reflect.Value, raw, separator string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), raw, separator); err != nil {
+			return err
+		}
+
+		v.Set(ptr)
+		return nil
+	}
+
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(raw))
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return invalidValue(raw, v.Type())
+		}
+
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return invalidValue(raw, v.Type())
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return invalidValue(raw, v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return invalidValue(raw, v.Type())
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return invalidValue(raw, v.Type())
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		items := split(raw, separator)
+		slice := reflect.MakeSlice(v.Type(), 0, len(items))
+
+		for _, item := range items {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, item, separator); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+
+		v.Set(slice)
+	case reflect.Map:
+		m := reflect.MakeMap(v.Type())
+
+		for _, entry := range split(raw, separator) {
+			k, val, ok := strings.Cut(entry, ":")
+			if !ok {
+				return fmt.Errorf("%q is not a key:value entry", entry)
+			}
+
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := setValue(key, strings.TrimSpace(k), separator); err != nil {
+				return err
+			}
+
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(elem, strings.TrimSpace(val), separator); err != nil {
+				return err
+			}
+
+			m.SetMapIndex(key, elem)
+		}
+
+		v.Set(m)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+// split splits the raw value by the separator, trimming the items and ignoring the empty ones.
+func split(raw, separator string) []string {
+	items := []string{}
+
+	for _, item := range strings.Split(raw, separator) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+func invalidValue(raw string, t reflect.Type) error {
+	return fmt.Errorf("%q is not a valid %s", raw, t)
+}
@@ -0,0 +1,153 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package internal
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	cerrors "github.com/ralvescosta/gokit/configs_builder/errors"
+	"github.com/ralvescosta/gokit/configs_builder/sources"
+)
+
+type (
+	CustomConfigsTestSuite struct {
+		suite.Suite
+	}
+
+	retryConfigs struct {
+		Attempts int           `env:"ATTEMPTS" default:"3"`
+		Backoff  time.Duration `env:"BACKOFF" default:"1s"`
+	}
+
+	ordersConfigs struct {
+		BatchSize int               `env:"ORDERS_BATCH_SIZE" default:"100" required:"true"`
+		Timeout   time.Duration     `env:"ORDERS_TIMEOUT"`
+		Enabled   bool              `env:"ORDERS_ENABLED"`
+		Ratio     float64           `env:"ORDERS_RATIO"`
+		Regions   []string          `env:"ORDERS_REGIONS"`
+		Ports     []uint16          `env:"ORDERS_PORTS" separator:";"`
+		Limits    map[string]int    `env:"ORDERS_LIMITS"`
+		Labels    map[string]string `env:"ORDERS_LABELS"`
+		IP        net.IP            `env:"ORDERS_IP"`
+		Owner     *string           `env:"ORDERS_OWNER"`
+		Retry     retryConfigs      `env:"ORDERS_RETRY"`
+		Fallback  *retryConfigs     `env:"ORDERS_FALLBACK"`
+		Ignored   string            `env:"-"`
+		Untagged  string
+		internal  string
+	}
+
+	requiredConfigs struct {
+		APIKey string `env:"API_KEY" required:"true"`
+	}
+
+	validatedConfigs struct {
+		Min int `env:"MIN"`
+		Max int `env:"MAX"`
+	}
+)
+
+func (c *validatedConfigs) Validate() error {
+	if c.Min > c.Max {
+		return errors.New("MIN must not be greater than MAX")
+	}
+
+	return nil
+}
+
+func TestCustomConfigsTestSuite(t *testing.T) {
+	suite.Run(t, new(CustomConfigsTestSuite))
+}
+
+func (s *CustomConfigsTestSuite) TestBind() {
+	src := sources.NewLayered(sources.Map("test", map[string]string{
+		"ORDERS_TIMEOUT":          "5m",
+		"ORDERS_ENABLED":          "true",
+		"ORDERS_RATIO":            "0.5",
+		"ORDERS_REGIONS":          "eu-west-1, us-east-1,",
+		"ORDERS_PORTS":            "80;443",
+		"ORDERS_LIMITS":           "gold:100,silver:10",
+		"ORDERS_LABELS":           "team:orders,url:http://orders",
+		"ORDERS_IP":               "10.0.0.1",
+		"ORDERS_OWNER":            "orders-team",
+		"ORDERS_RETRY_ATTEMPTS":   "5",
+		"ORDERS_FALLBACK_BACKOFF": "10s",
+		"Ignored":                 "ignored",
+		"Untagged":                "ignored",
+	}))
+
+	cfg := ordersConfigs{}
+	values := map[string]string{}
+
+	s.Require().NoError(ReadCustomConfigs(NewReader(src), &cfg, values))
+
+	s.Equal(100, cfg.BatchSize)
+	s.Equal(5*time.Minute, cfg.Timeout)
+	s.True(cfg.Enabled)
+	s.Equal(0.5, cfg.Ratio)
+	s.Equal([]string{"eu-west-1", "us-east-1"}, cfg.Regions)
+	s.Equal([]uint16{80, 443}, cfg.Ports)
+	s.Equal(map[string]int{"gold": 100, "silver": 10}, cfg.Limits)
+	s.Equal(map[string]string{"team": "orders", "url": "http://orders"}, cfg.Labels)
+	s.Equal("10.0.0.1", cfg.IP.String())
+	s.Require().NotNil(cfg.Owner)
+	s.Equal("orders-team", *cfg.Owner)
+	s.Equal(retryConfigs{Attempts: 5, Backoff: time.Second}, cfg.Retry)
+	s.Require().NotNil(cfg.Fallback)
+	s.Equal(retryConfigs{Attempts: 3, Backoff: 10 * time.Second}, *cfg.Fallback)
+	s.Empty(cfg.Ignored)
+	s.Empty(cfg.Untagged)
+
+	s.Equal("100", values["ORDERS_BATCH_SIZE"])
+	s.Equal("5", values["ORDERS_RETRY_ATTEMPTS"])
+	s.Equal("1s", values["ORDERS_RETRY_BACKOFF"])
+	s.NotContains(values, "ORDERS_OWNER_")
+}
+
+func (s *CustomConfigsTestSuite) TestRequired() {
+	err := ReadCustomConfigs(NewReader(sources.NewLayered()), &requiredConfigs{}, map[string]string{})
+
+	var configErr *cerrors.ConfigError
+	s.Require().ErrorAs(err, &configErr)
+	s.EqualError(configErr, "configs builder error - API_KEY is required")
+	s.Equal(CustomComponent, configErr.Component)
+}
+
+func (s *CustomConfigsTestSuite) TestInvalidValues() {
+	src := sources.NewLayered(sources.Map("test", map[string]string{
+		"ORDERS_BATCH_SIZE": "many",
+		"ORDERS_TIMEOUT":    "many",
+		"ORDERS_LIMITS":     "many",
+		"ORDERS_PORTS":      "many",
+	}))
+
+	err := ReadCustomConfigs(NewReader(src), &ordersConfigs{}, map[string]string{})
+
+	var validationErrs *cerrors.ValidationErrors
+	s.Require().ErrorAs(err, &validationErrs)
+	s.Equal([]*cerrors.ConfigError{
+		{Key: "ORDERS_BATCH_SIZE", Component: CustomComponent, Reason: `is invalid: "many" is not a valid int`},
+		{Key: "ORDERS_TIMEOUT", Component: CustomComponent, Reason: `is invalid: "many" is not a valid time.Duration`},
+		{Key: "ORDERS_PORTS", Component: CustomComponent, Reason: `is invalid: "many" is not a valid uint16`},
+		{Key: "ORDERS_LIMITS", Component: CustomComponent, Reason: `is invalid: "many" is not a key:value entry`},
+	}, validationErrs.Errors)
+}
+
+func (s *CustomConfigsTestSuite) TestValidate() {
+	src := sources.NewLayered(sources.Map("test", map[string]string{"MIN": "10", "MAX": "1"}))
+
+	err := ReadCustomConfigs(NewReader(src), &validatedConfigs{}, map[string]string{})
+	s.EqualError(err, "configs builder error - 1 invalid configurations:\n\t- [custom] validatedConfigs validation failed: MIN must not be greater than MAX")
+}
+
+func (s *CustomConfigsTestSuite) TestInvalidTarget() {
+	s.Error(ReadCustomConfigs(NewReader(sources.NewLayered()), ordersConfigs{}, map[string]string{}))
+	s.Error(ReadCustomConfigs(NewReader(sources.NewLayered()), (*ordersConfigs)(nil), map[string]string{}))
+}
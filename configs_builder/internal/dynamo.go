@@ -4,11 +4,28 @@
 
 package internal
 
-import "github.com/ralvescosta/gokit/configs"
+import (
+	"github.com/ralvescosta/gokit/configs"
+	keys "github.com/ralvescosta/gokit/configs_builder/keys"
+)
 
-// ReadDynamoDBConfigs retrieves DynamoDB configuration from environment variables.
-// Currently a placeholder that will be implemented in future versions to provide
-// comprehensive DynamoDB configuration and connection settings.
-func ReadDynamoDBConfigs() (*configs.DynamoDBConfigs, error) {
-	return nil, nil
+// ReadDynamoDBConfigs retrieves DynamoDB configuration from the configuration sources.
+// The table is required. The region, endpoint, profile and role ARN default to the
+// AWS configuration values, so they only need to be set when DynamoDB differs from the other services.
+func ReadDynamoDBConfigs(r *Reader) (*configs.DynamoDBConfigs, error) {
+	c := r.component(DynamoDBComponent)
+	src := r.Sources()
+	dynamoConfigs := configs.DynamoDBConfigs{}
+
+	dynamoConfigs.Region = c.optional(keys.DynamoDBRegionEnvKey, src.Get(keys.AWSRegionEnvKey))
+	dynamoConfigs.Endpoint = readEndpoint(c, keys.DynamoDBEndpointEnvKey, src.Get(keys.AWSEndpointEnvKey))
+	dynamoConfigs.Profile = c.optional(keys.DynamoDBProfileEnvKey, src.Get(keys.AWSProfileEnvKey))
+	dynamoConfigs.RoleARN = readRoleARN(c, keys.DynamoDBRoleARNEnvKey, src.Get(keys.AWSRoleARNEnvKey))
+	dynamoConfigs.Table = c.required(keys.DynamoDBTableEnvKey)
+
+	if err := c.err(); err != nil {
+		return nil, err
+	}
+
+	return &dynamoConfigs, nil
 }
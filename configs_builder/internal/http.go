@@ -6,37 +6,29 @@ package internal
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/ralvescosta/gokit/configs"
-	"github.com/ralvescosta/gokit/configs_builder/errors"
 	keys "github.com/ralvescosta/gokit/configs_builder/keys"
 )
 
-// ReadHTTPConfigs retrieves and validates HTTP server configuration from environment variables.
-// Returns an error if required configuration values are missing.
-func ReadHTTPConfigs() (*configs.HTTPConfigs, error) {
+// ReadHTTPConfigs retrieves and validates HTTP server configuration from the configuration sources.
+// Returns an error listing the required configuration values that are missing.
+func ReadHTTPConfigs(r *Reader) (*configs.HTTPConfigs, error) {
+	c := r.component(HTTPComponent)
 	httpConfigs := configs.HTTPConfigs{}
 
-	// Get and validate HTTP port
-	httpConfigs.Port = os.Getenv(keys.HTTPPortEnvKey)
-	if httpConfigs.Port == "" {
-		return nil, errors.NewErrRequiredConfig(keys.HTTPPortEnvKey)
-	}
-
-	// Get and validate HTTP host
-	httpConfigs.Host = os.Getenv(keys.HTTPHostEnvKey)
-	if httpConfigs.Host == "" {
-		return nil, errors.NewErrRequiredConfig(keys.HTTPHostEnvKey)
-	}
+	// Get and validate HTTP port and host
+	httpConfigs.Port = c.required(keys.HTTPPortEnvKey)
+	httpConfigs.Host = c.required(keys.HTTPHostEnvKey)
 
 	// Construct full address string from host and port
 	httpConfigs.Addr = fmt.Sprintf("%s:%s", httpConfigs.Host, httpConfigs.Port)
 
 	// Check if profiling is enabled
-	profiling := os.Getenv(keys.HTTPEnableProfilingEnvKey)
-	if profiling == "true" {
-		httpConfigs.EnableProfiling = true
+	httpConfigs.EnableProfiling = c.optional(keys.HTTPEnableProfilingEnvKey, "false") == "true"
+
+	if err := c.err(); err != nil {
+		return nil, err
 	}
 
 	return &httpConfigs, nil
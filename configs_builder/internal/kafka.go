@@ -5,40 +5,30 @@
 package internal
 
 import (
-	"os"
-	"strconv"
-
 	"github.com/ralvescosta/gokit/configs"
-	"github.com/ralvescosta/gokit/configs_builder/errors"
 	"github.com/ralvescosta/gokit/configs_builder/keys"
 )
 
-// ReadKafkaConfigs retrieves Kafka connection configuration from environment variables.
-// Validates required connection parameters and returns an error if any required
-// configuration is missing.
-func ReadKafkaConfigs() (*configs.KafkaConfigs, error) {
+// ReadKafkaConfigs retrieves Kafka connection configuration from the configuration sources.
+// Validates required connection parameters and returns an error listing every missing
+// or invalid configuration.
+func ReadKafkaConfigs(r *Reader) (*configs.KafkaConfigs, error) {
+	c := r.component(KafkaComponent)
 	cfgs := &configs.KafkaConfigs{}
 
-	// Get and validate Kafka broker host
-	cfgs.Host = os.Getenv(keys.KafkaHostEnvKey)
-	if cfgs.Host == "" {
-		return nil, errors.NewErrRequiredConfig(keys.KafkaHostEnvKey)
-	}
-
-	// Get and validate Kafka broker port
-	port := os.Getenv(keys.KafkaPortEnvKey)
-	if port == "" {
-		return nil, errors.NewErrRequiredConfig(keys.KafkaPortEnvKey)
-	}
-
-	// Parse port string to integer
-	cfgs.Port, _ = strconv.Atoi(port)
+	// Get and validate Kafka broker host and port
+	cfgs.Host = c.required(keys.KafkaHostEnvKey)
+	cfgs.Port = c.integer(keys.KafkaPortEnvKey, true, "")
 
 	// Get optional security settings
-	cfgs.SecurityProtocol = os.Getenv(keys.KafkaSecurityProtocolEnvKey)
-	cfgs.SASLMechanisms = os.Getenv(keys.KafkaSASLMechanismsEnvKey)
-	cfgs.User = os.Getenv(keys.KafkaUserEnvKey)
-	cfgs.Password = os.Getenv(keys.KafkaPasswordEnvKey)
+	cfgs.SecurityProtocol = c.optional(keys.KafkaSecurityProtocolEnvKey, "")
+	cfgs.SASLMechanisms = c.optional(keys.KafkaSASLMechanismsEnvKey, "")
+	cfgs.User = c.optional(keys.KafkaUserEnvKey, "")
+	cfgs.Password = c.optional(keys.KafkaPasswordEnvKey, "")
+
+	if err := c.err(); err != nil {
+		return nil, err
+	}
 
 	return cfgs, nil
 }
@@ -5,16 +5,15 @@
 package internal
 
 import (
-	"os"
-
 	"github.com/ralvescosta/gokit/configs"
 	keys "github.com/ralvescosta/gokit/configs_builder/keys"
 )
 
-// ReadMetricsConfigs retrieves metrics collection configuration from environment variables.
+// ReadMetricsConfigs retrieves metrics collection configuration from the configuration sources.
 // Determines whether metrics collection is enabled and loads endpoint information for metrics export.
-func ReadMetricsConfigs() (*configs.MetricsConfigs, error) {
-	enabled := os.Getenv(keys.MetricsEnabledEnvKey)
+func ReadMetricsConfigs(r *Reader) (*configs.MetricsConfigs, error) {
+	c := r.component(MetricsComponent)
+	enabled := c.optional(keys.MetricsEnabledEnvKey, "")
 
 	metricsConfigs := configs.MetricsConfigs{}
 
@@ -24,11 +23,11 @@ func ReadMetricsConfigs() (*configs.MetricsConfigs, error) {
 	}
 
 	// Load OTLP (OpenTelemetry Protocol) endpoint and API key for metrics
-	metricsConfigs.OtlpEndpoint = os.Getenv(keys.MetricsOtlpEndpointEnvKey)
-	metricsConfigs.OtlpAPIKey = os.Getenv(keys.MetricsOtlpAPIKeyEnvKey)
+	metricsConfigs.OtlpEndpoint = c.optional(keys.MetricsOtlpEndpointEnvKey, "")
+	metricsConfigs.OtlpAPIKey = c.optional(keys.MetricsOtlpAPIKeyEnvKey, "")
 
 	// Determine which metrics system to use based on environment configuration
-	kind := os.Getenv(keys.MetricsKindEnvKey)
+	kind := c.optional(keys.MetricsKindEnvKey, "")
 	if kind == "prometheus" {
 		metricsConfigs.Kind = configs.Prometheus
 	}
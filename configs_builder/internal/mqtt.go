@@ -5,40 +5,66 @@
 package internal
 
 import (
-	"os"
 	"strconv"
 
 	"github.com/ralvescosta/gokit/configs"
 	"github.com/ralvescosta/gokit/configs_builder/keys"
 )
 
-// ReadMQTTConfigs retrieves MQTT connection configuration from environment variables.
+// ReadMQTTConfigs retrieves MQTT connection configuration from the configuration sources.
 // Loads protocol, host, port, credentials, and other settings needed to connect to an
 // MQTT broker. Uses default values for optional parameters when not specified.
-func ReadMQTTConfigs() (*configs.MQTTConfigs, error) {
+func ReadMQTTConfigs(r *Reader) (*configs.MQTTConfigs, error) {
+	c := r.component(MQTTComponent)
 	mqttConfigs := &configs.MQTTConfigs{}
 
 	// Get MQTT protocol (mqtt, mqtts)
-	mqttConfigs.Protocol = os.Getenv(keys.MQTTProtocolEnvKey)
+	mqttConfigs.Protocol = c.optional(keys.MQTTProtocolEnvKey, "")
 	// Get MQTT broker host
-	mqttConfigs.Host = os.Getenv(keys.MQTTHostEnvKey)
+	mqttConfigs.Host = c.optional(keys.MQTTHostEnvKey, "")
 
 	// Get MQTT port with default fallback to 1883
-	portEnv := os.Getenv(keys.MQTTPortEnvKey)
-	if portEnv == "" {
-		portEnv = "1883"
-	}
+	mqttConfigs.Port = c.integer(keys.MQTTPortEnvKey, false, "1883")
+
+	// Get MQTT authentication credentials (optional)
+	mqttConfigs.User = c.optional(keys.MQTTUserEnvKey, "")
+	mqttConfigs.Password = c.optional(keys.MQTTPasswordEnvKey, "")
+
+	// Clean session is enabled unless explicitly disabled
+	mqttConfigs.CleanSession = c.optional(keys.MQTTCleanSessionEnvKey, "true") != "false"
+	// Get session persistence settings (optional)
+	mqttConfigs.ClientIDSuffix = c.optional(keys.MQTTClientIDSuffixEnvKey, "")
+	mqttConfigs.StorePath = c.optional(keys.MQTTStorePathEnvKey, "")
 
-	// Parse port string to integer
-	port, err := strconv.Atoi(portEnv)
-	if err != nil {
+	// Get Last Will and Testament settings (optional)
+	mqttConfigs.WillTopic = c.optional(keys.MQTTWillTopicEnvKey, "")
+	mqttConfigs.WillPayload = c.optional(keys.MQTTWillPayloadEnvKey, "")
+	mqttConfigs.WillRetain = c.optional(keys.MQTTWillRetainEnvKey, "false") == "true"
+	mqttConfigs.WillQoS = readMQTTQoS(c, keys.MQTTWillQoSEnvKey)
+
+	// Get birth message settings (optional)
+	mqttConfigs.BirthTopic = c.optional(keys.MQTTBirthTopicEnvKey, "")
+	mqttConfigs.BirthPayload = c.optional(keys.MQTTBirthPayloadEnvKey, "")
+	mqttConfigs.BirthRetain = c.optional(keys.MQTTBirthRetainEnvKey, "false") == "true"
+	mqttConfigs.BirthQoS = readMQTTQoS(c, keys.MQTTBirthQoSEnvKey)
+
+	if err := c.err(); err != nil {
 		return nil, err
 	}
 
-	mqttConfigs.Port = port
-	// Get MQTT authentication credentials (optional)
-	mqttConfigs.User = os.Getenv(keys.MQTTUserEnvKey)
-	mqttConfigs.Password = os.Getenv(keys.MQTTPasswordEnvKey)
-
 	return mqttConfigs, nil
 }
+
+// readMQTTQoS reads a QoS level from the given key, defaulting to 0.
+// Reports the key as invalid if the value is not 0, 1 or 2.
+func readMQTTQoS(c *componentReader, key string) byte {
+	qosEnv := c.optional(key, "0")
+
+	qos, err := strconv.Atoi(qosEnv)
+	if err != nil || qos < 0 || qos > 2 {
+		c.invalid(key, "must be 0, 1 or 2")
+		return 0
+	}
+
+	return byte(qos)
+}
@@ -5,51 +5,32 @@
 package internal
 
 import (
-	"os"
-
 	"github.com/ralvescosta/gokit/configs"
-	"github.com/ralvescosta/gokit/configs_builder/errors"
 	keys "github.com/ralvescosta/gokit/configs_builder/keys"
 )
 
-// ReadRabbitMQConfigs retrieves RabbitMQ connection configuration from environment variables.
+// ReadRabbitMQConfigs retrieves RabbitMQ connection configuration from the configuration sources.
 // Validates that all required connection parameters are provided and returns an error
-// if any required configuration is missing. Uses default values for optional parameters.
-func ReadRabbitMQConfigs() (*configs.RabbitMQConfigs, error) {
+// listing every missing configuration. Uses default values for optional parameters.
+func ReadRabbitMQConfigs(r *Reader) (*configs.RabbitMQConfigs, error) {
+	c := r.component(RabbitMQComponent)
 	rabbitmqConfigs := configs.RabbitMQConfigs{}
 
 	// Get connection schema with default fallback to "amqp"
-	rabbitmqConfigs.Schema = os.Getenv(keys.RabbitSchemaEnvKey)
-	if rabbitmqConfigs.Schema == "" {
-		rabbitmqConfigs.Schema = "amqp"
-	}
+	rabbitmqConfigs.Schema = c.optional(keys.RabbitSchemaEnvKey, "amqp")
 
-	// Get and validate RabbitMQ host
-	rabbitmqConfigs.Host = os.Getenv(keys.RabbitHostEnvKey)
-	if rabbitmqConfigs.Host == "" {
-		return nil, errors.NewErrRequiredConfig(keys.RabbitHostEnvKey)
-	}
-
-	// Get and validate RabbitMQ port
-	rabbitmqConfigs.Port = os.Getenv(keys.RabbitPortEnvKey)
-	if rabbitmqConfigs.Port == "" {
-		return nil, errors.NewErrRequiredConfig(keys.RabbitPortEnvKey)
-	}
+	// Get and validate RabbitMQ connection parameters
+	rabbitmqConfigs.Host = c.required(keys.RabbitHostEnvKey)
+	rabbitmqConfigs.Port = c.required(keys.RabbitPortEnvKey)
+	rabbitmqConfigs.User = c.required(keys.RabbitUserEnvKey)
+	rabbitmqConfigs.Password = c.required(keys.RabbitPasswordEnvKey)
 
-	// Get and validate RabbitMQ user
-	rabbitmqConfigs.User = os.Getenv(keys.RabbitUserEnvKey)
-	if rabbitmqConfigs.User == "" {
-		return nil, errors.NewErrRequiredConfig(keys.RabbitUserEnvKey)
-	}
+	// Get RabbitMQ virtual host (optional)
+	rabbitmqConfigs.VHost = c.optional(keys.RabbitVHostEnvKey, "")
 
-	// Get and validate RabbitMQ password
-	rabbitmqConfigs.Password = os.Getenv(keys.RabbitPasswordEnvKey)
-	if rabbitmqConfigs.Password == "" {
-		return nil, errors.NewErrRequiredConfig(keys.RabbitPasswordEnvKey)
+	if err := c.err(); err != nil {
+		return nil, err
 	}
 
-	// Get RabbitMQ virtual host (optional)
-	rabbitmqConfigs.VHost = os.Getenv(keys.RabbitVHostEnvKey)
-
 	return &rabbitmqConfigs, nil
 }
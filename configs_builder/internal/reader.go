@@ -0,0 +1,268 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs_builder/errors"
+	"github.com/ralvescosta/gokit/configs_builder/sources"
+)
+
+// Configuration components, as reported in the errors and the validation table
+const (
+	AppComponent      = "app"
+	HTTPComponent     = "http"
+	MetricsComponent  = "metrics"
+	TracingComponent  = "tracing"
+	SQLComponent      = "sql"
+	IdentityComponent = "identity"
+	MQTTComponent     = "mqtt"
+	RabbitMQComponent = "rabbitmq"
+	KafkaComponent    = "kafka"
+	AWSComponent      = "aws"
+	DynamoDBComponent = "dynamodb"
+	CustomComponent   = "custom"
+)
+
+type (
+	// KeySpec describes a configuration key read by a component and its resolved value
+	KeySpec struct {
+		Component string
+		Key       string
+		Required  bool
+		Default   string
+		// Value is the value resolved from the configuration sources, empty when no source sets the key
+		Value string
+		// Source is the name of the source the value came from
+		Source string
+		// Reason is the reason of the failure when the value is missing or invalid
+		Reason string
+	}
+
+	// Reader reads the configuration keys from the configuration sources, recording every key read
+	// and collecting the missing and invalid values of all the components instead of failing on the first one
+	Reader struct {
+		src *sources.Layered
+
+		mu    sync.Mutex
+		specs []*KeySpec
+		errs  []*errors.ConfigError
+	}
+
+	// componentReader reads the keys of a single component
+	componentReader struct {
+		*Reader
+		name     string
+		failures []*errors.ConfigError
+	}
+)
+
+// NewReader creates a Reader over the configuration sources
+func NewReader(src *sources.Layered) *Reader {
+	return &Reader{src: src}
+}
+
+// Sources returns the configuration sources read by the Reader
+func (r *Reader) Sources() *sources.Layered {
+	return r.src
+}
+
+// Keys returns the keys read so far, in reading order
+func (r *Reader) Keys() []KeySpec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]KeySpec, 0, len(r.specs))
+	for _, spec := range r.specs {
+		keys = append(keys, *spec)
+	}
+
+	return keys
+}
+
+// Err returns the missing and invalid values of all the components as *errors.ValidationErrors,
+// or nil when every value is valid
+func (r *Reader) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.errs) == 0 {
+		return nil
+	}
+
+	return &errors.ValidationErrors{Errors: append([]*errors.ConfigError{}, r.errs...)}
+}
+
+// WriteTable writes the keys read so far as a table with their requirement, resolved value,
+// default value, source and status. The values of sensitive keys, such as passwords, are masked.
+func (r *Reader) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "COMPONENT\tKEY\tREQUIRED\tVALUE\tDEFAULT\tSOURCE\tSTATUS")
+
+	for _, spec := range r.Keys() {
+		value, def := spec.Value, spec.Default
+		if isSensitive(spec.Key) {
+			value, def = mask(value), mask(def)
+		}
+
+		status := "ok"
+		if spec.Reason != "" {
+			status = spec.Reason
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%t\t%s\t%s\t%s\t%s\n",
+			spec.Component, spec.Key, spec.Required, orDash(value), orDash(def), orDash(spec.Source), status)
+	}
+
+	return tw.Flush()
+}
+
+// component creates the reader of the keys of a component
+func (r *Reader) component(name string) *componentReader {
+	return &componentReader{Reader: r, name: name}
+}
+
+// required reads a required key, reporting it when missing
+func (c *componentReader) required(key string) string {
+	return c.read(key, true, "")
+}
+
+// optional reads an optional key, returning the default value when no source sets it
+func (c *componentReader) optional(key, def string) string {
+	return c.read(key, false, def)
+}
+
+// integer reads a key as an integer, reporting it when missing or not a number
+func (c *componentReader) integer(key string, required bool, def string) int {
+	value := c.read(key, required, def)
+	if value == "" {
+		return 0
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		c.invalid(key, fmt.Sprintf("%q is not a valid int", value))
+		return 0
+	}
+
+	return i
+}
+
+// duration reads a key as a duration, e.g. 5s, reporting it when missing or not a valid duration
+func (c *componentReader) duration(key string, required bool, def string) time.Duration {
+	value := c.read(key, required, def)
+	if value == "" {
+		return 0
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		c.invalid(key, fmt.Sprintf("%q is not a valid duration", value))
+		return 0
+	}
+
+	return d
+}
+
+// list reads a key as a comma-separated list, ignoring the empty items
+func (c *componentReader) list(key string, required bool, def string) []string {
+	value := c.read(key, required, def)
+	if value == "" {
+		return nil
+	}
+
+	return split(value, ",")
+}
+
+// read resolves the key and records it
+func (c *componentReader) read(key string, required bool, def string) string {
+	v := c.src.Resolve(key)
+
+	spec := &KeySpec{Component: c.name, Key: key, Required: required, Default: def, Value: v.Value, Source: v.Source}
+
+	c.mu.Lock()
+	c.specs = append(c.specs, spec)
+	c.mu.Unlock()
+
+	value := v.Value
+	if value == "" {
+		value = def
+	}
+
+	if value == "" && required {
+		c.fail(errors.NewRequiredConfigError(c.name, key))
+	}
+
+	return value
+}
+
+// invalid reports an invalid value of the key
+func (c *componentReader) invalid(key, reason string) {
+	c.fail(errors.NewInvalidConfigError(c.name, key, reason))
+}
+
+// fail records the error of the component, and its reason in the spec of the key
+func (c *componentReader) fail(err *errors.ConfigError) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.errs = append(c.errs, err)
+	c.failures = append(c.failures, err)
+
+	for i := len(c.specs) - 1; i >= 0; i-- {
+		if c.specs[i].Key == err.Key && c.specs[i].Component == err.Component {
+			c.specs[i].Reason = err.Reason
+			break
+		}
+	}
+}
+
+// err returns the missing and invalid values of the component as *errors.ValidationErrors,
+// or nil when every value is valid
+func (c *componentReader) err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.failures) == 0 {
+		return nil
+	}
+
+	return &errors.ValidationErrors{Errors: append([]*errors.ConfigError{}, c.failures...)}
+}
+
+// isSensitive reports whether the key holds a credential, whose value must not be printed
+func isSensitive(key string) bool {
+	for _, s := range []string{"PASSWORD", "SECRET", "TOKEN", "API_KEY", "CREDENTIAL"} {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return "******"
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
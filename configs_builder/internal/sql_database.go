@@ -5,57 +5,76 @@
 package internal
 
 import (
-	"os"
-	"strconv"
+	"slices"
+	"strings"
 
 	"github.com/ralvescosta/gokit/configs"
-	"github.com/ralvescosta/gokit/configs_builder/errors"
 	keys "github.com/ralvescosta/gokit/configs_builder/keys"
 )
 
-// ReadSQLDatabaseConfigs retrieves SQL database configuration from environment variables.
+// sslModes are the SSL modes accepted by the SQL_DB_SSL_MODE key
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// ReadSQLDatabaseConfigs retrieves SQL database configuration from the configuration sources.
 // Validates that all required database connection parameters are provided and returns
-// an error if any required configuration is missing.
-func ReadSQLDatabaseConfigs() (*configs.SQLConfigs, error) {
+// an error listing every missing or invalid configuration. When a full DSN is set, the
+// connection parameters become optional, the DSN being used as it is.
+func ReadSQLDatabaseConfigs(r *Reader) (*configs.SQLConfigs, error) {
+	c := r.component(SQLComponent)
 	sqlConfigs := configs.SQLConfigs{}
 
-	// Get and validate database host
-	sqlConfigs.Host = os.Getenv(keys.SQLDbHostEnvKey)
-	if sqlConfigs.Host == "" {
-		return nil, errors.NewErrRequiredConfig(keys.SQLDbHostEnvKey)
-	}
+	// Get the DSN overriding the connection parameters
+	sqlConfigs.DSN = c.optional(keys.SQLDbDSNEnvKey, "")
+	required := sqlConfigs.DSN == ""
 
-	// Get and validate database port
-	sqlConfigs.Port = os.Getenv(keys.SQLDbPortEnvKey)
-	if sqlConfigs.Port == "" {
-		return nil, errors.NewErrRequiredConfig(keys.SQLDbPortEnvKey)
-	}
+	// Get and validate the database connection parameters
+	sqlConfigs.Host = c.read(keys.SQLDbHostEnvKey, required, "")
+	sqlConfigs.Port = c.read(keys.SQLDbPortEnvKey, required, "")
+	sqlConfigs.User = c.read(keys.SQLDbUserEnvKey, required, "")
+	sqlConfigs.Password = c.read(keys.SQLDbPasswordEnvKey, required, "")
+	sqlConfigs.DbName = c.read(keys.SQLDbNameEnvKey, required, "")
 
-	// Get and validate database user
-	sqlConfigs.User = os.Getenv(keys.SQLDbUserEnvKey)
-	if sqlConfigs.User == "" {
-		return nil, errors.NewErrRequiredConfig(keys.SQLDbUserEnvKey)
+	// Get the SSL settings, SSL is disabled by default
+	sqlConfigs.SSLMode = strings.ToLower(c.optional(keys.SQLDbSSLModeEnvKey, "disable"))
+	if !slices.Contains(sslModes, sqlConfigs.SSLMode) {
+		c.invalid(keys.SQLDbSSLModeEnvKey, "must be one of "+strings.Join(sslModes, ", "))
 	}
 
-	// Get and validate database password
-	sqlConfigs.Password = os.Getenv(keys.SQLDbPasswordEnvKey)
-	if sqlConfigs.Password == "" {
-		return nil, errors.NewErrRequiredConfig(keys.SQLDbPasswordEnvKey)
-	}
+	sqlConfigs.SSLRootCert = c.optional(keys.SQLDbSSLRootCertEnvKey, "")
+	sqlConfigs.SSLCert = c.optional(keys.SQLDbSSLCertEnvKey, "")
+	sqlConfigs.SSLKey = c.optional(keys.SQLDbSSLKeyEnvKey, "")
 
-	// Get and validate database name
-	sqlConfigs.DbName = os.Getenv(keys.SQLDbNameEnvKey)
-	if sqlConfigs.DbName == "" {
-		return nil, errors.NewErrRequiredConfig(keys.SQLDbNameEnvKey)
-	}
+	// Get the session settings
+	sqlConfigs.ConnectTimeout = c.duration(keys.SQLDbConnectTimeoutEnvKey, false, "")
+	sqlConfigs.StatementTimeout = c.duration(keys.SQLDbStatementTimeoutEnvKey, false, "")
+	sqlConfigs.SearchPath = c.optional(keys.SQLDbSearchPathEnvKey, "")
+
+	// Get the connection pool settings
+	sqlConfigs.MaxOpenConns = readNonNegative(c, keys.SQLDbMaxOpenConnsEnvKey)
+	sqlConfigs.MaxIdleConns = readNonNegative(c, keys.SQLDbMaxIdleConnsEnvKey)
+	sqlConfigs.ConnMaxLifetime = c.duration(keys.SQLDbConnMaxLifetimeEnvKey, false, "")
+	sqlConfigs.ConnMaxIdleTime = c.duration(keys.SQLDbConnMaxIdleTimeEnvKey, false, "")
+
+	// Get the read replicas
+	sqlConfigs.ReplicaHosts = c.list(keys.SQLDbReplicaHostsEnvKey, false, "")
 
 	// Parse and set the database ping interval
-	p, err := strconv.Atoi(os.Getenv(keys.SQLDbSecondsToPingEnvKey))
-	if err != nil {
+	sqlConfigs.SecondsToPing = c.integer(keys.SQLDbSecondsToPingEnvKey, true, "")
+
+	if err := c.err(); err != nil {
 		return nil, err
 	}
 
-	sqlConfigs.SecondsToPing = p
-
 	return &sqlConfigs, nil
 }
+
+// readNonNegative reads an optional integer, reporting the key as invalid if the value is negative
+func readNonNegative(c *componentReader, key string) int {
+	value := c.integer(key, false, "")
+	if value < 0 {
+		c.invalid(key, "must not be negative")
+		return 0
+	}
+
+	return value
+}
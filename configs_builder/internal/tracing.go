@@ -5,16 +5,15 @@
 package internal
 
 import (
-	"os"
-
 	"github.com/ralvescosta/gokit/configs"
 	keys "github.com/ralvescosta/gokit/configs_builder/keys"
 )
 
-// ReadTracingConfigs retrieves OpenTelemetry tracing configuration from environment variables.
+// ReadTracingConfigs retrieves OpenTelemetry tracing configuration from the configuration sources.
 // Determines whether tracing is enabled and loads endpoint information for trace collection.
-func ReadTracingConfigs() (*configs.TracingConfigs, error) {
-	enabled := os.Getenv(keys.TracingEnabledEnvKey)
+func ReadTracingConfigs(r *Reader) (*configs.TracingConfigs, error) {
+	c := r.component(TracingComponent)
+	enabled := c.optional(keys.TracingEnabledEnvKey, "")
 
 	configs := configs.TracingConfigs{}
 
@@ -24,8 +23,8 @@ func ReadTracingConfigs() (*configs.TracingConfigs, error) {
 	}
 
 	// Load OTLP (OpenTelemetry Protocol) endpoint and API key
-	configs.OtlpEndpoint = os.Getenv(keys.TracingOtlpEndpointEnvKey)
-	configs.OtlpAPIKey = os.Getenv(keys.TracingOtlpAPIKeyEnvKey)
+	configs.OtlpEndpoint = c.optional(keys.TracingOtlpEndpointEnvKey, "")
+	configs.OtlpAPIKey = c.optional(keys.TracingOtlpAPIKeyEnvKey, "")
 
 	return &configs, nil
 }
@@ -25,6 +25,20 @@ const (
 	SQLDbNameEnvKey          = "SQL_DB_NAME"            // SQL database name
 	SQLDbSecondsToPingEnvKey = "SQL_DB_SECONDS_TO_PING" // Interval for database health check pings
 
+	SQLDbDSNEnvKey              = "SQL_DB_DSN"                // Full connection string or URL, overriding the connection settings
+	SQLDbSSLModeEnvKey          = "SQL_DB_SSL_MODE"           // SSL mode (disable, allow, prefer, require, verify-ca, verify-full)
+	SQLDbSSLRootCertEnvKey      = "SQL_DB_SSL_ROOT_CERT"      // Path of the certificate authorities file
+	SQLDbSSLCertEnvKey          = "SQL_DB_SSL_CERT"           // Path of the client certificate file
+	SQLDbSSLKeyEnvKey           = "SQL_DB_SSL_KEY"            // Path of the client certificate private key file
+	SQLDbConnectTimeoutEnvKey   = "SQL_DB_CONNECT_TIMEOUT"    // Connection timeout, as a duration (e.g. 5s)
+	SQLDbStatementTimeoutEnvKey = "SQL_DB_STATEMENT_TIMEOUT"  // Statement timeout, as a duration (e.g. 30s)
+	SQLDbSearchPathEnvKey       = "SQL_DB_SEARCH_PATH"        // Schema search path
+	SQLDbMaxOpenConnsEnvKey     = "SQL_DB_MAX_OPEN_CONNS"     // Maximum number of open connections
+	SQLDbMaxIdleConnsEnvKey     = "SQL_DB_MAX_IDLE_CONNS"     // Maximum number of idle connections
+	SQLDbConnMaxLifetimeEnvKey  = "SQL_DB_CONN_MAX_LIFETIME"  // Maximum lifetime of a connection, as a duration
+	SQLDbConnMaxIdleTimeEnvKey  = "SQL_DB_CONN_MAX_IDLE_TIME" // Maximum idle time of a connection, as a duration
+	SQLDbReplicaHostsEnvKey     = "SQL_DB_REPLICA_HOSTS"      // Comma-separated read replicas, as host or host:port
+
 	// RabbitMQ configuration
 	RabbitSchemaEnvKey   = "RABBIT_SCHEMA"   // RabbitMQ connection schema (amqp, amqps)
 	RabbitHostEnvKey     = "RABBIT_HOST"     // RabbitMQ host
@@ -40,6 +54,19 @@ const (
 	MQTTUserEnvKey     = "MQTT_USER"     // MQTT username
 	MQTTPasswordEnvKey = "MQTT_PASSWORD" // MQTT password
 
+	MQTTCleanSessionEnvKey   = "MQTT_CLEAN_SESSION"    // Whether the broker should discard the session on disconnect
+	MQTTClientIDSuffixEnvKey = "MQTT_CLIENT_ID_SUFFIX" // Suffix appended to the app name to build the client ID
+	MQTTStorePathEnvKey      = "MQTT_STORE_PATH"       // Directory used to persist in-flight QoS 1/2 messages
+
+	MQTTWillTopicEnvKey    = "MQTT_WILL_TOPIC"    // Topic of the Last Will and Testament message
+	MQTTWillPayloadEnvKey  = "MQTT_WILL_PAYLOAD"  // Payload of the Last Will and Testament message
+	MQTTWillQoSEnvKey      = "MQTT_WILL_QOS"      // QoS of the Last Will and Testament message
+	MQTTWillRetainEnvKey   = "MQTT_WILL_RETAIN"   // Whether the Last Will and Testament message is retained
+	MQTTBirthTopicEnvKey   = "MQTT_BIRTH_TOPIC"   // Topic of the birth message published on every connection
+	MQTTBirthPayloadEnvKey = "MQTT_BIRTH_PAYLOAD" // Payload of the birth message
+	MQTTBirthQoSEnvKey     = "MQTT_BIRTH_QOS"     // QoS of the birth message
+	MQTTBirthRetainEnvKey  = "MQTT_BIRTH_RETAIN"  // Whether the birth message is retained
+
 	// Kafka configuration
 	KafkaHostEnvKey             = "KAFKA_HOST"              // Kafka broker host
 	KafkaPortEnvKey             = "KAFKA_PORT"              // Kafka broker port
@@ -48,6 +75,22 @@ const (
 	KafkaUserEnvKey             = "KAFKA_USER"              // Kafka username
 	KafkaPasswordEnvKey         = "KAFKA_PASSWORD"          // Kafka password
 
+	// AWS configuration
+	AWSRegionEnvKey          = "AWS_REGION"            // Default AWS region of the services
+	AWSEndpointEnvKey        = "AWS_ENDPOINT_URL"      // Endpoint override of the services (LocalStack, etc.)
+	AWSProfileEnvKey         = "AWS_PROFILE"           // Named profile of the shared AWS configuration files
+	AWSRoleARNEnvKey         = "AWS_ASSUME_ROLE_ARN"   // ARN of the IAM role assumed through STS
+	AWSAccessKeyIDEnvKey     = "AWS_ACCESS_KEY_ID"     // AWS access key ID
+	AWSSecretAccessKeyEnvKey = "AWS_SECRET_ACCESS_KEY" // AWS secret access key
+	AWSSessionTokenEnvKey    = "AWS_SESSION_TOKEN"     // AWS session token of temporary credentials
+
+	// DynamoDB configuration, falling back to the AWS configuration when not set
+	DynamoDBRegionEnvKey   = "DYNAMO_REGION"   // DynamoDB region
+	DynamoDBEndpointEnvKey = "DYNAMO_ENDPOINT" // DynamoDB endpoint override (DynamoDB Local, etc.)
+	DynamoDBProfileEnvKey  = "DYNAMO_PROFILE"  // Named profile used to access DynamoDB
+	DynamoDBRoleARNEnvKey  = "DYNAMO_ROLE_ARN" // ARN of the IAM role assumed to access DynamoDB
+	DynamoDBTableEnvKey    = "DYNAMO_TABLE"    // Default DynamoDB table
+
 	// Default values
 	DefaultAppName = "app"    // Default application name if not specified
 	DefaultLogPath = "/logs/" // Default log file path if not specified
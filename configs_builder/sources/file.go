@@ -0,0 +1,128 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ralvescosta/gokit/configs_builder/errors"
+)
+
+// File creates a source from a YAML, TOML or JSON file, selected by the file extension.
+// Nested objects are flattened into keys joined by underscores, and lists into comma separated
+// values, so that the following YAML sets SQL_DB_HOST and KAFKA_BROKERS:
+//
+//	sql:
+//	  db_host: localhost
+//	kafka:
+//	  brokers: [broker-1, broker-2]
+//
+// Returns an error if the file cannot be read or parsed, or its extension is not supported.
+func File(path string) (Source, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.NewConfigsError(fmt.Sprintf("failure to read the configuration file %s: %s", path, err))
+	}
+
+	tree := map[string]any{}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	case ".json":
+		err = json.Unmarshal(content, &tree)
+	default:
+		return nil, errors.NewConfigsError(fmt.Sprintf("unsupported configuration file format %s", path))
+	}
+
+	if err != nil {
+		return nil, errors.NewConfigsError(fmt.Sprintf("failure to parse the configuration file %s: %s", path, err))
+	}
+
+	values := map[string]string{}
+	flatten("", tree, values)
+
+	return Map("file:"+path, values), nil
+}
+
+// DotEnv creates a source from a dotenv file. A missing file results in an empty source,
+// since the dotenv files are optional.
+// Returns an error if the file exists but cannot be parsed.
+func DotEnv(path string) (Source, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return Map("dotenv:"+path, map[string]string{}), nil
+	}
+
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, errors.NewConfigsError(fmt.Sprintf("failure to parse the dotenv file %s: %s", path, err))
+	}
+
+	return Map("dotenv:"+path, values), nil
+}
+
+// Values returns the values held by a source created by Map, File, DotEnv or Defaults.
+func Values(source Source) map[string]string {
+	s, ok := source.(*mapSource)
+	if !ok {
+		return nil
+	}
+
+	values := make(map[string]string, len(s.values))
+	for k, v := range s.values {
+		values[k] = v
+	}
+
+	return values
+}
+
+// flatten converts the parsed file into keys, joining the nested keys with underscores.
+func flatten(prefix string, node any, values map[string]string) {
+	switch v := node.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			flatten(join(prefix, k), v[k], values)
+		}
+	case map[any]any:
+		for k, child := range v {
+			flatten(join(prefix, fmt.Sprint(k)), child, values)
+		}
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, fmt.Sprint(item))
+		}
+		values[NormalizeKey(prefix)] = strings.Join(items, ",")
+	case nil:
+		values[NormalizeKey(prefix)] = ""
+	default:
+		values[NormalizeKey(prefix)] = fmt.Sprint(v)
+	}
+}
+
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "_" + key
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sources
+
+import "strings"
+
+// Flags creates a source from the command line arguments, usually os.Args[1:].
+// Flags are given as --name=value or --name value, and flags without a value are set to true.
+// The names are normalized to the configuration keys, so --sql-db-host sets SQL_DB_HOST.
+// Arguments that are not flags are ignored, as well as the ones after the -- terminator.
+func Flags(args []string) Source {
+	values := map[string]string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if n, value, ok := strings.Cut(name, "="); ok {
+			values[NormalizeKey(n)] = value
+			continue
+		}
+
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			values[NormalizeKey(name)] = args[i+1]
+			i++
+			continue
+		}
+
+		values[NormalizeKey(name)] = "true"
+	}
+
+	return Map(FlagsSourceName, values)
+}
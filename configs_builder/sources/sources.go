@@ -0,0 +1,220 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package sources provides the configuration sources read by the configs builder and the
+// layering that merges them. Each source provides values by key (e.g. SQL_DB_HOST), and the
+// layered sources resolve every key from the source with the highest precedence, reporting
+// which source each value came from.
+//
+// The builder layers the sources in the following precedence, from the lowest to the highest:
+// defaults, YAML/TOML/JSON files, the .env.<GO_ENV> dotenv file, environment variables,
+// command line flags and the secrets manager.
+package sources
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+)
+
+type (
+	// Source provides configuration values by key.
+	Source interface {
+		// Name identifies the source in the provenance of the values, e.g. env or file:config.yaml.
+		Name() string
+		// Lookup returns the value of the key and whether the source sets it.
+		Lookup(key string) (string, bool)
+	}
+
+	// Value is a configuration value resolved from the layered sources.
+	Value struct {
+		// Key is the configuration key.
+		Key string
+		// Value is the resolved value, empty when no source sets the key.
+		Value string
+		// Source is the name of the source the value came from, empty when no source sets the key.
+		Source string
+	}
+
+	// Layered resolves the configuration keys from a list of sources, the last sources
+	// taking precedence over the first ones. It records every resolved key, so the origin
+	// of the values read by the builder can be reported.
+	Layered struct {
+		sources []Source
+
+		mu       sync.Mutex
+		resolved map[string]Value
+	}
+
+	// SecretClient retrieves secrets by key. It is implemented by the secrets_manager clients.
+	SecretClient interface {
+		GetSecret(ctx context.Context, key string) (string, error)
+	}
+
+	mapSource struct {
+		name   string
+		values map[string]string
+	}
+
+	secretsSource struct {
+		client SecretClient
+		keys   map[string]string
+	}
+)
+
+// Source names of the builtin sources.
+const (
+	DefaultsSourceName = "defaults"
+	EnvSourceName      = "env"
+	FlagsSourceName    = "flags"
+	SecretsSourceName  = "secrets"
+)
+
+// NewLayered creates the layered sources. The sources are given from the lowest to the highest
+// precedence; nil sources are ignored.
+func NewLayered(sources ...Source) *Layered {
+	l := &Layered{resolved: map[string]Value{}}
+
+	for _, s := range sources {
+		if s != nil {
+			l.sources = append(l.sources, s)
+		}
+	}
+
+	return l
+}
+
+// Name returns the name of the layered sources.
+func (l *Layered) Name() string {
+	return "layered"
+}
+
+// Lookup returns the value of the key from the source with the highest precedence that sets it.
+func (l *Layered) Lookup(key string) (string, bool) {
+	v := l.Resolve(key)
+	return v.Value, v.Source != ""
+}
+
+// Get returns the value of the key, or an empty string when no source sets it.
+func (l *Layered) Get(key string) string {
+	return l.Resolve(key).Value
+}
+
+// Resolve returns the value of the key and the source it came from.
+func (l *Layered) Resolve(key string) Value {
+	v := Value{Key: key}
+
+	for i := len(l.sources) - 1; i >= 0; i-- {
+		if value, ok := l.sources[i].Lookup(key); ok {
+			v.Value = value
+			v.Source = l.sources[i].Name()
+			break
+		}
+	}
+
+	l.mu.Lock()
+	l.resolved[key] = v
+	l.mu.Unlock()
+
+	return v
+}
+
+// Resolved returns the keys resolved so far, with the values and the sources they came from.
+func (l *Layered) Resolved() map[string]Value {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	resolved := make(map[string]Value, len(l.resolved))
+	for k, v := range l.resolved {
+		resolved[k] = v
+	}
+
+	return resolved
+}
+
+// Provenance returns the name of the source of each resolved key that is set by a source.
+func (l *Layered) Provenance() map[string]string {
+	provenance := map[string]string{}
+
+	for k, v := range l.Resolved() {
+		if v.Source != "" {
+			provenance[k] = v.Source
+		}
+	}
+
+	return provenance
+}
+
+// Sources returns the layered sources, from the lowest to the highest precedence.
+func (l *Layered) Sources() []Source {
+	return append([]Source{}, l.sources...)
+}
+
+// Map creates a source holding the given values.
+func Map(name string, values map[string]string) Source {
+	return &mapSource{name: name, values: values}
+}
+
+// Defaults creates the source of the default values, with the lowest precedence.
+func Defaults(values map[string]string) Source {
+	return Map(DefaultsSourceName, values)
+}
+
+// Env creates a source holding a snapshot of the process environment variables.
+func Env() Source {
+	values := map[string]string{}
+
+	for _, kv := range os.Environ() {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			values[k] = v
+		}
+	}
+
+	return Map(EnvSourceName, values)
+}
+
+// Secrets creates a source reading the keys from the secret client. The keys map the configuration
+// keys to the names of the secrets holding them, and restricts the source to these keys;
+// a nil map reads every configuration key from the secret of the same name.
+// Keys the client fails to retrieve are considered unset.
+func Secrets(client SecretClient, keys map[string]string) Source {
+	return &secretsSource{client: client, keys: keys}
+}
+
+func (s *mapSource) Name() string {
+	return s.name
+}
+
+func (s *mapSource) Lookup(key string) (string, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+func (s *secretsSource) Name() string {
+	return SecretsSourceName
+}
+
+func (s *secretsSource) Lookup(key string) (string, bool) {
+	name := key
+	if s.keys != nil {
+		var ok bool
+		if name, ok = s.keys[key]; !ok {
+			return "", false
+		}
+	}
+
+	v, err := s.client.GetSecret(context.Background(), name)
+	if err != nil {
+		return "", false
+	}
+
+	return v, true
+}
+
+// NormalizeKey converts a file path or flag name to a configuration key:
+// upper case, with dots, dashes and spaces replaced by underscores (e.g. sql.db-host to SQL_DB_HOST).
+func NormalizeKey(name string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_", " ", "_").Replace(name))
+}
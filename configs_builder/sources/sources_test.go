@@ -0,0 +1,144 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sources
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	SourcesTestSuite struct {
+		suite.Suite
+	}
+
+	secretClientMock map[string]string
+)
+
+func TestSourcesTestSuite(t *testing.T) {
+	suite.Run(t, new(SourcesTestSuite))
+}
+
+func (m secretClientMock) GetSecret(_ context.Context, key string) (string, error) {
+	if v, ok := m[key]; ok {
+		return v, nil
+	}
+
+	return "", errors.New("secret not found")
+}
+
+func (s *SourcesTestSuite) TestLayeredPrecedence() {
+	layered := NewLayered(
+		Defaults(map[string]string{"SQL_DB_HOST": "default", "SQL_DB_PORT": "5432", "APP_NAME": "app"}),
+		nil,
+		Map("file:config.yaml", map[string]string{"SQL_DB_HOST": "file", "SQL_DB_PORT": "5433"}),
+		Flags([]string{"--sql-db-host=flag"}),
+		Secrets(secretClientMock{"SQL_DB_PASSWORD": "secret"}, nil),
+	)
+
+	s.Equal("flag", layered.Get("SQL_DB_HOST"))
+	s.Equal("5433", layered.Get("SQL_DB_PORT"))
+	s.Equal("app", layered.Get("APP_NAME"))
+	s.Equal("secret", layered.Get("SQL_DB_PASSWORD"))
+	s.Empty(layered.Get("SQL_DB_USER"))
+	s.Len(layered.Sources(), 4)
+
+	s.Equal(map[string]string{
+		"SQL_DB_HOST":     FlagsSourceName,
+		"SQL_DB_PORT":     "file:config.yaml",
+		"APP_NAME":        DefaultsSourceName,
+		"SQL_DB_PASSWORD": SecretsSourceName,
+	}, layered.Provenance())
+
+	s.Equal(Value{Key: "SQL_DB_USER"}, layered.Resolved()["SQL_DB_USER"])
+}
+
+func (s *SourcesTestSuite) TestSecretKeys() {
+	source := Secrets(secretClientMock{"db_password": "secret", "SQL_DB_USER": "user"}, map[string]string{"SQL_DB_PASSWORD": "db_password"})
+
+	v, ok := source.Lookup("SQL_DB_PASSWORD")
+	s.True(ok)
+	s.Equal("secret", v)
+
+	_, ok = source.Lookup("SQL_DB_USER")
+	s.False(ok)
+}
+
+func (s *SourcesTestSuite) TestEnv() {
+	s.T().Setenv("SOURCES_TEST_KEY", "a=b")
+
+	v, ok := Env().Lookup("SOURCES_TEST_KEY")
+	s.True(ok)
+	s.Equal("a=b", v)
+}
+
+func (s *SourcesTestSuite) TestFile() {
+	files := map[string]string{
+		"config.yaml": "sql:\n  db_host: localhost\n  db-port: 5432\nkafka:\n  brokers: [broker-1, broker-2]\n",
+		"config.toml": "[sql]\ndb_host = \"localhost\"\ndb-port = 5432\n[kafka]\nbrokers = [\"broker-1\", \"broker-2\"]\n",
+		"config.json": `{"sql": {"db_host": "localhost", "db-port": 5432}, "kafka": {"brokers": ["broker-1", "broker-2"]}}`,
+	}
+
+	for name, content := range files {
+		path := s.writeFile(name, content)
+
+		source, err := File(path)
+		s.Require().NoError(err, name)
+
+		s.Equal("file:"+path, source.Name())
+		s.Equal(map[string]string{
+			"SQL_DB_HOST":   "localhost",
+			"SQL_DB_PORT":   "5432",
+			"KAFKA_BROKERS": "broker-1,broker-2",
+		}, Values(source), name)
+	}
+}
+
+func (s *SourcesTestSuite) TestFileErrors() {
+	_, err := File(filepath.Join(s.T().TempDir(), "missing.yaml"))
+	s.Error(err)
+
+	_, err = File(s.writeFile("config.ini", "key=value"))
+	s.Error(err)
+
+	_, err = File(s.writeFile("config.json", "{"))
+	s.Error(err)
+}
+
+func (s *SourcesTestSuite) TestDotEnv() {
+	source, err := DotEnv(filepath.Join(s.T().TempDir(), ".env.missing"))
+	s.Require().NoError(err)
+	s.Empty(Values(source))
+
+	path := s.writeFile(".env.local", "APP_NAME=app\nSQL_DB_HOST=localhost\n")
+
+	source, err = DotEnv(path)
+	s.Require().NoError(err)
+	s.Equal("dotenv:"+path, source.Name())
+	s.Equal(map[string]string{"APP_NAME": "app", "SQL_DB_HOST": "localhost"}, Values(source))
+}
+
+func (s *SourcesTestSuite) TestFlags() {
+	source := Flags([]string{"serve", "--app-name=app", "--sql.db.host", "localhost", "-debug", "--port", "--", "--ignored=true"})
+
+	s.Equal(map[string]string{
+		"APP_NAME":    "app",
+		"SQL_DB_HOST": "localhost",
+		"DEBUG":       "true",
+		"PORT":        "true",
+	}, Values(source))
+}
+
+func (s *SourcesTestSuite) writeFile(name, content string) string {
+	path := filepath.Join(s.T().TempDir(), name)
+	s.Require().NoError(os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
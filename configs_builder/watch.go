@@ -0,0 +1,232 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package configsbuilder
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/logging"
+	"go.uber.org/zap"
+)
+
+// DefaultWatchInterval is the default interval the Watcher checks the configuration files for changes
+const DefaultWatchInterval = 5 * time.Second
+
+type (
+	// Watcher holds the current configurations and rebuilds them from the configuration sources
+	// on SIGHUP or when the configuration or dotenv files change. Each build is an immutable snapshot,
+	// atomically swapped, so the snapshots must not be modified by their readers.
+	Watcher interface {
+		// Configs returns the current configurations snapshot
+		Configs() *configs.Configs
+		// OnChange subscribes to the configuration changes. The subscribers are called
+		// with the previous and the new snapshots, after the new one is swapped in
+		OnChange(fn func(old, new *configs.Configs))
+		// Reload rebuilds the configurations, keeping the current snapshot when it fails
+		Reload() error
+		// Close stops watching the configuration changes
+		Close()
+	}
+
+	// configsWatcher implements the Watcher interface
+	configsWatcher struct {
+		builder  *configsBuilder
+		interval time.Duration
+		current  atomic.Pointer[configs.Configs]
+
+		// mu serializes the reloads and guards the subscribers
+		mu          sync.Mutex
+		files       map[string]fileState
+		subscribers []func(old, new *configs.Configs)
+
+		cancel context.CancelFunc
+		done   chan struct{}
+	}
+
+	// fileState is the state of a watched file, compared to detect its changes
+	fileState struct {
+		modTime time.Time
+		size    int64
+		exists  bool
+	}
+)
+
+// Watch builds the configurations as Build does, then watches for changes until the context is done
+// or the Watcher is closed. The configurations are rebuilt when the process receives SIGHUP, or when the
+// files given to WithFiles or the .env.<GO_ENV> dotenv file change, checked every watch interval.
+// Every source is read again, including the secrets.
+//
+// A new snapshot keeps the logger of the first build, and applies its log level to this logger with
+// logging.SetLevel. The custom configuration structs given to Custom are bound once by the first build;
+// the later snapshots provide the custom values in Configs.Custom.
+// Returns the errors of Build.
+func (b *configsBuilder) Watch(ctx context.Context) (Watcher, error) {
+	cfgs, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	w := &configsWatcher{
+		builder:  b,
+		interval: b.interval,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	if w.interval <= 0 {
+		w.interval = DefaultWatchInterval
+	}
+
+	w.current.Store(cfgs)
+	w.files = w.stat()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go w.watch(ctx, signals)
+
+	return w, nil
+}
+
+// Configs returns the current configurations snapshot
+func (w *configsWatcher) Configs() *configs.Configs {
+	return w.current.Load()
+}
+
+// OnChange subscribes to the configuration changes
+func (w *configsWatcher) OnChange(fn func(old, new *configs.Configs)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload rebuilds the configurations and swaps the current snapshot. The subscribers are notified
+// only when the configurations changed, without holding the lock, so they may subscribe or reload
+// in turn. Returns the build errors, keeping the current snapshot.
+func (w *configsWatcher) Reload() error {
+	old, cfgs, subscribers, err := w.reload()
+	if err != nil {
+		return err
+	}
+
+	for _, fn := range subscribers {
+		fn(old, cfgs)
+	}
+
+	return nil
+}
+
+// reload rebuilds the configurations and swaps the current snapshot while holding the lock.
+// Returns the previous and new snapshots with a copy of the subscribers to notify, none when
+// the configurations did not change, or the build errors.
+func (w *configsWatcher) reload() (*configs.Configs, *configs.Configs, []func(old, new *configs.Configs), error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	old := w.current.Load()
+
+	// The files are read once per change, whether the reload succeeds or not
+	w.files = w.stat()
+
+	// Bind the custom configurations into new structs, leaving the ones read by the application untouched
+	targets := make([]any, 0, len(w.builder.custom))
+	for _, target := range w.builder.custom {
+		if t := reflect.TypeOf(target); t != nil && t.Kind() == reflect.Pointer {
+			target = reflect.New(t.Elem()).Interface()
+		}
+		targets = append(targets, target)
+	}
+
+	cfgs, reader, err := w.builder.read(targets)
+	if err == nil {
+		err = reader.Err()
+	}
+
+	if err != nil {
+		old.Logger.Error("failure to reload the configurations, keeping the current ones", zap.Error(err))
+		return nil, nil, nil, err
+	}
+
+	cfgs.Logger = old.Logger
+	logging.SetLevel(old.Logger, cfgs.AppConfigs.LogLevel)
+
+	w.current.Store(cfgs)
+
+	if reflect.DeepEqual(old, cfgs) {
+		return old, cfgs, nil, nil
+	}
+
+	old.Logger.Info("configurations reloaded")
+
+	return old, cfgs, slices.Clone(w.subscribers), nil
+}
+
+// Close stops watching the configuration changes
+func (w *configsWatcher) Close() {
+	w.cancel()
+	<-w.done
+}
+
+// watch reloads the configurations on SIGHUP and when the watched files change
+func (w *configsWatcher) watch(ctx context.Context, signals chan os.Signal) {
+	defer close(w.done)
+	defer signal.Stop(signals)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+			_ = w.Reload()
+		case <-ticker.C:
+			if w.changed() {
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+// changed reports whether any watched file changed since the last reload
+func (w *configsWatcher) changed() bool {
+	files := w.stat()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return !reflect.DeepEqual(files, w.files)
+}
+
+// stat returns the state of the configuration files and the dotenv file of the current environment
+func (w *configsWatcher) stat() map[string]fileState {
+	paths := append([]string{".env." + w.current.Load().AppConfigs.GoEnv.ToString()}, w.builder.files...)
+
+	files := make(map[string]fileState, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			files[path] = fileState{}
+			continue
+		}
+
+		files[path] = fileState{modTime: info.ModTime(), size: info.Size(), exists: true}
+	}
+
+	return files
+}
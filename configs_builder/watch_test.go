@@ -0,0 +1,137 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package configsbuilder
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/logging"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type WatcherTestSuite struct {
+	suite.Suite
+
+	file string
+}
+
+func TestWatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(WatcherTestSuite))
+}
+
+func (s *WatcherTestSuite) SetupTest() {
+	s.T().Setenv("GO_ENV", "local")
+	s.file = filepath.Join(s.T().TempDir(), "config.yaml")
+	s.writeConfig("log_level: error\nhttp:\n  host: localhost\n  port: 3000\n")
+}
+
+func (s *WatcherTestSuite) TestReloadOnFileChange() {
+	watcher, err := NewConfigsBuilder().
+		WithFiles(s.file).
+		WithWatchInterval(10 * time.Millisecond).
+		HTTP().
+		Watch(context.Background())
+	s.Require().NoError(err)
+	defer watcher.Close()
+
+	first := watcher.Configs()
+	s.Equal("localhost:3000", first.HTTPConfigs.Addr)
+	s.False(first.Logger.Core().Enabled(zap.InfoLevel))
+
+	changes := make(chan [2]*configs.Configs, 1)
+	watcher.OnChange(func(old, new *configs.Configs) {
+		changes <- [2]*configs.Configs{old, new}
+	})
+
+	s.writeConfig("log_level: debug\nhttp:\n  host: 0.0.0.0\n  port: 8080\n")
+
+	select {
+	case change := <-changes:
+		s.Same(first, change[0])
+		s.Equal("0.0.0.0:8080", change[1].HTTPConfigs.Addr)
+		s.Same(change[1], watcher.Configs())
+		s.Same(first.Logger, change[1].Logger)
+		s.True(first.Logger.Core().Enabled(zap.DebugLevel))
+		level, ok := logging.AtomicLevel(first.Logger)
+		s.True(ok)
+		s.Equal(zap.DebugLevel, level.Level())
+	case <-time.After(2 * time.Second):
+		s.Fail("configurations not reloaded")
+	}
+
+	// The first snapshot is immutable
+	s.Equal("localhost:3000", first.HTTPConfigs.Addr)
+}
+
+func (s *WatcherTestSuite) TestReloadKeepsSnapshotOnFailure() {
+	watcher, err := NewConfigsBuilder().
+		WithFiles(s.file).
+		WithWatchInterval(time.Hour).
+		HTTP().
+		Watch(context.Background())
+	s.Require().NoError(err)
+	defer watcher.Close()
+
+	notified := false
+	watcher.OnChange(func(_, _ *configs.Configs) { notified = true })
+
+	first := watcher.Configs()
+
+	s.writeConfig("http:\n  port: 8080\n")
+
+	s.Error(watcher.Reload())
+	s.Same(first, watcher.Configs())
+	s.False(notified)
+}
+
+func (s *WatcherTestSuite) TestReloadWithoutChanges() {
+	watcher, err := NewConfigsBuilder().
+		WithFiles(s.file).
+		WithWatchInterval(time.Hour).
+		HTTP().
+		Watch(context.Background())
+	s.Require().NoError(err)
+	defer watcher.Close()
+
+	notified := false
+	watcher.OnChange(func(_, _ *configs.Configs) { notified = true })
+
+	s.NoError(watcher.Reload())
+	s.False(notified)
+}
+
+func (s *WatcherTestSuite) TestSubscriberMaySubscribe() {
+	watcher, err := NewConfigsBuilder().
+		WithFiles(s.file).
+		WithWatchInterval(time.Hour).
+		HTTP().
+		Watch(context.Background())
+	s.Require().NoError(err)
+	defer watcher.Close()
+
+	notified := 0
+	watcher.OnChange(func(_, _ *configs.Configs) {
+		notified++
+		watcher.OnChange(func(_, _ *configs.Configs) { notified++ })
+	})
+
+	s.writeConfig("log_level: error\nhttp:\n  host: localhost\n  port: 8080\n")
+	s.NoError(watcher.Reload())
+	s.Equal(1, notified)
+
+	s.writeConfig("log_level: error\nhttp:\n  host: localhost\n  port: 9090\n")
+	s.NoError(watcher.Reload())
+	s.Equal(3, notified)
+}
+
+func (s *WatcherTestSuite) writeConfig(content string) {
+	s.Require().NoError(os.WriteFile(s.file, []byte(content), 0o600))
+}
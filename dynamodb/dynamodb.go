@@ -0,0 +1,95 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package dynamodb provides Amazon DynamoDB integration for the GoKit framework.
+// It creates DynamoDB clients from the application configurations, instrumented with
+// OpenTelemetry when tracing is enabled, and a typed repository with optimistic locking on top of them.
+package dynamodb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/ralvescosta/gokit/configs"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+// API is the subset of the DynamoDB client operations used by the Repository.
+// It is implemented by *dynamodb.Client and allows replacing the client in tests.
+type API interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error)
+}
+
+const (
+	// FailureClientErrorMessage is the standard error message used when the client cannot be created
+	FailureClientErrorMessage = "[DynamoDB::NewClient] failure to load the aws configs"
+
+	// tracerName is the instrumentation name of the DynamoDB spans
+	tracerName = "github.com/ralvescosta/gokit/dynamodb"
+)
+
+// loadDefaultConfig is a variable containing the config.LoadDefaultConfig function, which allows for testing
+var loadDefaultConfig = config.LoadDefaultConfig
+
+// NewClient creates a DynamoDB client from the DynamoDB configurations.
+// The region, profile and endpoint override are taken from the DynamoDBConfigs, the static
+// credentials from the AWSConfigs when set, and the remaining settings from the AWS SDK default chain.
+// When a role ARN is configured, the role is assumed through STS and its credentials cached until they expire.
+// When tracing is enabled, every DynamoDB call is recorded as an OpenTelemetry client span.
+//
+// Parameters:
+//   - cfgs: Application configurations including the DynamoDB, AWS and tracing settings
+//
+// Returns:
+//   - A DynamoDB client and any error that occurred loading the AWS configurations
+func NewClient(cfgs *configs.Configs) (*dynamodb.Client, error) {
+	dynamoCfgs := cfgs.DynamoDBConfigs
+	if dynamoCfgs == nil {
+		dynamoCfgs = &configs.DynamoDBConfigs{}
+	}
+
+	opts := []func(*config.LoadOptions) error{}
+
+	if dynamoCfgs.Region != "" {
+		opts = append(opts, config.WithRegion(dynamoCfgs.Region))
+	}
+
+	if dynamoCfgs.Profile != "" {
+		opts = append(opts, config.WithSharedConfigProfile(dynamoCfgs.Profile))
+	}
+
+	if awsCfgs := cfgs.AWSConfigs; awsCfgs != nil && awsCfgs.AccessKeyID != "" {
+		opts = append(opts, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(awsCfgs.AccessKeyID, awsCfgs.SecretAccessKey, awsCfgs.SessionToken),
+		))
+	}
+
+	awsCfg, err := loadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		cfgs.Logger.Error(FailureClientErrorMessage, zap.Error(err))
+		return nil, err
+	}
+
+	if dynamoCfgs.RoleARN != "" {
+		awsCfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(sts.NewFromConfig(awsCfg), dynamoCfgs.RoleARN))
+	}
+
+	return dynamodb.NewFromConfig(awsCfg, func(o *dynamodb.Options) {
+		if dynamoCfgs.Endpoint != "" {
+			o.BaseEndpoint = aws.String(dynamoCfgs.Endpoint)
+		}
+
+		if cfgs.TracingConfigs != nil && cfgs.TracingConfigs.Enabled {
+			o.APIOptions = append(o.APIOptions, tracingMiddleware(otel.Tracer(tracerName)))
+		}
+	}), nil
+}
@@ -0,0 +1,197 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package dynamodb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.uber.org/zap"
+)
+
+type (
+	DynamoDBTestSuite struct {
+		suite.Suite
+
+		stub *dynamoStub
+	}
+
+	// stubRequest is a DynamoDB API call received by the stub
+	stubRequest struct {
+		Operation string
+		Body      map[string]any
+	}
+
+	// dynamoStub is an HTTP server answering the DynamoDB API calls with the response set for each operation
+	dynamoStub struct {
+		*httptest.Server
+
+		mu        sync.Mutex
+		requests  []stubRequest
+		responses map[string][]string
+	}
+)
+
+func TestDynamoDBTestSuite(t *testing.T) {
+	suite.Run(t, new(DynamoDBTestSuite))
+}
+
+func (s *DynamoDBTestSuite) SetupTest() {
+	s.stub = newDynamoStub()
+}
+
+func (s *DynamoDBTestSuite) TearDownTest() {
+	s.stub.Close()
+}
+
+// newDynamoStub starts a stub server. Operations without a response set answer with an empty JSON object.
+func newDynamoStub() *dynamoStub {
+	stub := &dynamoStub{responses: map[string][]string{}}
+
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operation := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "DynamoDB_20120810.")
+
+		raw, _ := io.ReadAll(r.Body)
+		body := map[string]any{}
+		_ = json.Unmarshal(raw, &body)
+
+		stub.mu.Lock()
+		stub.requests = append(stub.requests, stubRequest{Operation: operation, Body: body})
+
+		response := "{}"
+		if queued := stub.responses[operation]; len(queued) > 0 {
+			response, stub.responses[operation] = queued[0], queued[1:]
+		}
+		stub.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.Header().Set("X-Amzn-Requestid", "request-id")
+		w.Header().Set("X-Amz-Crc32", strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(response))), 10))
+
+		if strings.Contains(response, "__type") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+
+		_, _ = w.Write([]byte(response))
+	}))
+
+	return stub
+}
+
+// respond queues the responses of the operation, answered in order
+func (s *dynamoStub) respond(operation string, responses ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.responses[operation] = append(s.responses[operation], responses...)
+}
+
+// received returns the calls received so far
+func (s *dynamoStub) received() []stubRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]stubRequest{}, s.requests...)
+}
+
+// configs returns the configurations of a client calling the stub
+func (s *dynamoStub) configs(tracing bool) *configs.Configs {
+	return &configs.Configs{
+		Logger:          zap.NewNop(),
+		TracingConfigs:  &configs.TracingConfigs{Enabled: tracing},
+		AWSConfigs:      &configs.AWSConfigs{AccessKeyID: "key", SecretAccessKey: "secret"},
+		DynamoDBConfigs: &configs.DynamoDBConfigs{Region: "us-east-1", Endpoint: s.URL, Table: "orders"},
+	}
+}
+
+func (s *DynamoDBTestSuite) TestNewClient() {
+	client, err := NewClient(s.stub.configs(false))
+	s.Require().NoError(err)
+
+	s.Equal("us-east-1", client.Options().Region)
+	s.Equal(s.stub.URL, aws.ToString(client.Options().BaseEndpoint))
+
+	creds, err := client.Options().Credentials.Retrieve(context.Background())
+	s.Require().NoError(err)
+	s.Equal("key", creds.AccessKeyID)
+
+	_, err = client.ListTables(context.Background(), &dynamodb.ListTablesInput{})
+	s.NoError(err)
+	s.Equal("ListTables", s.stub.received()[0].Operation)
+}
+
+func (s *DynamoDBTestSuite) TestNewClientWithRole() {
+	cfgs := s.stub.configs(false)
+	cfgs.DynamoDBConfigs.RoleARN = "arn:aws:iam::123456789012:role/orders"
+
+	client, err := NewClient(cfgs)
+	s.Require().NoError(err)
+
+	s.IsType(&aws.CredentialsCache{}, client.Options().Credentials)
+}
+
+func (s *DynamoDBTestSuite) TestNewClientError() {
+	loadDefaultConfig = func(_ context.Context, _ ...func(*config.LoadOptions) error) (aws.Config, error) {
+		return aws.Config{}, errors.New("invalid profile")
+	}
+	defer func() { loadDefaultConfig = config.LoadDefaultConfig }()
+
+	_, err := NewClient(s.stub.configs(false))
+	s.EqualError(err, "invalid profile")
+}
+
+func (s *DynamoDBTestSuite) TestTracing() {
+	provider := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(provider)
+
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	s.stub.respond("PutItem", `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`)
+
+	client, err := NewClient(s.stub.configs(true))
+	s.Require().NoError(err)
+
+	key := map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "order-1"}}
+
+	_, err = client.GetItem(context.Background(), &dynamodb.GetItemInput{TableName: aws.String("orders"), Key: key})
+	s.Require().NoError(err)
+
+	_, err = client.PutItem(context.Background(), &dynamodb.PutItemInput{TableName: aws.String("orders"), Item: key})
+	s.Require().Error(err)
+
+	spans := recorder.Ended()
+	s.Require().Len(spans, 2)
+
+	s.Equal("DynamoDB.GetItem", spans[0].Name())
+	s.Contains(spans[0].Attributes(), semconv.RPCMethod("GetItem"))
+	s.Contains(spans[0].Attributes(), semconv.DBSystemDynamoDB)
+	s.Contains(spans[0].Attributes(), semconv.AWSDynamoDBTableNames("orders"))
+	s.Contains(spans[0].Attributes(), semconv.AWSRequestID("request-id"))
+	s.Equal(codes.Unset, spans[0].Status().Code)
+
+	s.Equal("DynamoDB.PutItem", spans[1].Name())
+	s.Equal(codes.Error, spans[1].Status().Code)
+}
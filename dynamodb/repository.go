@@ -0,0 +1,195 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+type (
+	// Key identifies an item by the values of its partition key and, if the table has one, its sort key
+	Key map[string]any
+
+	// Repository stores and retrieves the items of type T in a DynamoDB table,
+	// marshaling them with the dynamodbav struct tags.
+	Repository[T any] struct {
+		client  API
+		table   string
+		version string
+	}
+)
+
+var (
+	// ErrItemNotFound is returned by Get when no item has the given key
+	ErrItemNotFound = errors.New("dynamodb: item not found")
+	// ErrVersionConflict is returned by Put when the stored item version differs from the version of the item written
+	ErrVersionConflict = errors.New("dynamodb: item version conflict")
+)
+
+// NewRepository creates a repository of the items of type T stored in the table.
+//
+// Parameters:
+//   - client: The DynamoDB client, usually created with NewClient
+//   - table: The table name, usually DynamoDBConfigs.Table
+//
+// Returns:
+//   - A new Repository instance
+func NewRepository[T any](client API, table string) *Repository[T] {
+	return &Repository[T]{client: client, table: table}
+}
+
+// WithVersion enables optimistic locking on the numeric attribute, e.g. a field tagged `dynamodbav:"version"`.
+// Put only writes an item when the stored version matches the version of the item, or when a new
+// item does not exist yet, and increments the version on every write.
+func (r *Repository[T]) WithVersion(attribute string) *Repository[T] {
+	r.version = attribute
+	return r
+}
+
+// Get retrieves the item with the given key.
+// Returns ErrItemNotFound when no item has the key.
+func (r *Repository[T]) Get(ctx context.Context, key Key) (*T, error) {
+	k, err := attributevalue.MarshalMap(map[string]any(key))
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := r.client.GetItem(ctx, &dynamodb.GetItemInput{TableName: aws.String(r.table), Key: k})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(out.Item) == 0 {
+		return nil, ErrItemNotFound
+	}
+
+	item := new(T)
+	if err := attributevalue.UnmarshalMap(out.Item, item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Put writes the item, replacing the stored item with the same key.
+// With optimistic locking enabled, the write is conditioned on the item version, ErrVersionConflict
+// being returned when another writer updated the item first. On success the version of the item is incremented.
+func (r *Repository[T]) Put(ctx context.Context, item *T) error {
+	av, err := attributevalue.MarshalMap(item)
+	if err != nil {
+		return err
+	}
+
+	input := &dynamodb.PutItemInput{TableName: aws.String(r.table), Item: av}
+
+	var next types.AttributeValue
+	if r.version != "" {
+		current, err := r.currentVersion(av)
+		if err != nil {
+			return err
+		}
+
+		input.ExpressionAttributeNames = map[string]string{"#version": r.version}
+
+		if current == 0 {
+			input.ConditionExpression = aws.String("attribute_not_exists(#version)")
+		} else {
+			input.ConditionExpression = aws.String("#version = :version")
+			input.ExpressionAttributeValues = map[string]types.AttributeValue{
+				":version": &types.AttributeValueMemberN{Value: strconv.FormatInt(current, 10)},
+			}
+		}
+
+		next = &types.AttributeValueMemberN{Value: strconv.FormatInt(current+1, 10)}
+		av[r.version] = next
+	}
+
+	if _, err := r.client.PutItem(ctx, input); err != nil {
+		var conditionErr *types.ConditionalCheckFailedException
+		if errors.As(err, &conditionErr) {
+			return ErrVersionConflict
+		}
+
+		return err
+	}
+
+	if next != nil {
+		return attributevalue.UnmarshalMap(map[string]types.AttributeValue{r.version: next}, item)
+	}
+
+	return nil
+}
+
+// Query retrieves the items matching the key condition expression, reading every page of results.
+// The values map the expression placeholders, e.g. ":id", to their values. The options customize
+// the query input, e.g. to set the index, the attribute names or the limit of items returned.
+func (r *Repository[T]) Query(ctx context.Context, keyCondition string, values map[string]any, opts ...func(*dynamodb.QueryInput)) ([]T, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.table),
+		KeyConditionExpression: aws.String(keyCondition),
+	}
+
+	if len(values) > 0 {
+		av, err := attributevalue.MarshalMap(values)
+		if err != nil {
+			return nil, err
+		}
+
+		input.ExpressionAttributeValues = av
+	}
+
+	for _, opt := range opts {
+		opt(input)
+	}
+
+	items := []T{}
+
+	for {
+		out, err := r.client.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		page := []T{}
+		if err := attributevalue.UnmarshalListOfMaps(out.Items, &page); err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+
+		if input.Limit != nil && len(items) >= int(*input.Limit) {
+			return items[:*input.Limit], nil
+		}
+
+		if len(out.LastEvaluatedKey) == 0 {
+			return items, nil
+		}
+
+		input.ExclusiveStartKey = out.LastEvaluatedKey
+	}
+}
+
+// currentVersion returns the version of the item, zero when the item has no version yet
+func (r *Repository[T]) currentVersion(item map[string]types.AttributeValue) (int64, error) {
+	var version int64
+
+	av, ok := item[r.version]
+	if !ok {
+		return 0, nil
+	}
+
+	if err := attributevalue.Unmarshal(av, &version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
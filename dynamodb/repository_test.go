@@ -0,0 +1,135 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package dynamodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	RepositoryTestSuite struct {
+		suite.Suite
+
+		stub       *dynamoStub
+		repository *Repository[order]
+	}
+
+	order struct {
+		ID      string  `dynamodbav:"id"`
+		Total   float64 `dynamodbav:"total,omitempty"`
+		Version int64   `dynamodbav:"version"`
+	}
+)
+
+func TestRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(RepositoryTestSuite))
+}
+
+func (s *RepositoryTestSuite) SetupTest() {
+	s.stub = newDynamoStub()
+
+	client, err := NewClient(s.stub.configs(false))
+	s.Require().NoError(err)
+
+	s.repository = NewRepository[order](client, "orders").WithVersion("version")
+}
+
+func (s *RepositoryTestSuite) TearDownTest() {
+	s.stub.Close()
+}
+
+func (s *RepositoryTestSuite) TestGet() {
+	s.stub.respond("GetItem", `{"Item":{"id":{"S":"order-1"},"total":{"N":"10.5"},"version":{"N":"2"}}}`, `{}`)
+
+	item, err := s.repository.Get(context.Background(), Key{"id": "order-1"})
+	s.Require().NoError(err)
+	s.Equal(&order{ID: "order-1", Total: 10.5, Version: 2}, item)
+
+	request := s.stub.received()[0]
+	s.Equal("orders", request.Body["TableName"])
+	s.Equal(map[string]any{"id": map[string]any{"S": "order-1"}}, request.Body["Key"])
+
+	_, err = s.repository.Get(context.Background(), Key{"id": "order-2"})
+	s.ErrorIs(err, ErrItemNotFound)
+}
+
+func (s *RepositoryTestSuite) TestPutNewItem() {
+	item := &order{ID: "order-1"}
+
+	s.Require().NoError(s.repository.Put(context.Background(), item))
+	s.Equal(int64(1), item.Version)
+
+	request := s.stub.received()[0]
+	s.Equal("PutItem", request.Operation)
+	s.Equal("attribute_not_exists(#version)", request.Body["ConditionExpression"])
+	s.Equal(map[string]any{"#version": "version"}, request.Body["ExpressionAttributeNames"])
+	s.Equal(map[string]any{"N": "1"}, request.Body["Item"].(map[string]any)["version"])
+}
+
+func (s *RepositoryTestSuite) TestPutExistingItem() {
+	item := &order{ID: "order-1", Version: 2}
+
+	s.Require().NoError(s.repository.Put(context.Background(), item))
+	s.Equal(int64(3), item.Version)
+
+	request := s.stub.received()[0]
+	s.Equal("#version = :version", request.Body["ConditionExpression"])
+	s.Equal(map[string]any{":version": map[string]any{"N": "2"}}, request.Body["ExpressionAttributeValues"])
+	s.Equal(map[string]any{"N": "3"}, request.Body["Item"].(map[string]any)["version"])
+}
+
+func (s *RepositoryTestSuite) TestPutVersionConflict() {
+	s.stub.respond("PutItem", `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`)
+
+	item := &order{ID: "order-1", Version: 2}
+
+	s.ErrorIs(s.repository.Put(context.Background(), item), ErrVersionConflict)
+	s.Equal(int64(2), item.Version)
+}
+
+func (s *RepositoryTestSuite) TestPutWithoutVersion() {
+	repository := NewRepository[order](s.repository.client, "orders")
+
+	item := &order{ID: "order-1", Version: 2}
+	s.Require().NoError(repository.Put(context.Background(), item))
+	s.Equal(int64(2), item.Version)
+
+	s.NotContains(s.stub.received()[0].Body, "ConditionExpression")
+}
+
+func (s *RepositoryTestSuite) TestQuery() {
+	s.stub.respond("Query",
+		`{"Items":[{"id":{"S":"order-1"}},{"id":{"S":"order-2"}}],"LastEvaluatedKey":{"id":{"S":"order-2"}}}`,
+		`{"Items":[{"id":{"S":"order-3"}}]}`,
+	)
+
+	items, err := s.repository.Query(context.Background(), "id = :id", map[string]any{":id": "order"}, func(input *dynamodb.QueryInput) {
+		input.IndexName = aws.String("by-customer")
+	})
+	s.Require().NoError(err)
+	s.Equal([]order{{ID: "order-1"}, {ID: "order-2"}, {ID: "order-3"}}, items)
+
+	requests := s.stub.received()
+	s.Require().Len(requests, 2)
+	s.Equal("by-customer", requests[0].Body["IndexName"])
+	s.Equal(map[string]any{":id": map[string]any{"S": "order"}}, requests[0].Body["ExpressionAttributeValues"])
+	s.Equal(map[string]any{"id": map[string]any{"S": "order-2"}}, requests[1].Body["ExclusiveStartKey"])
+}
+
+func (s *RepositoryTestSuite) TestQueryLimit() {
+	s.stub.respond("Query", `{"Items":[{"id":{"S":"order-1"}}],"LastEvaluatedKey":{"id":{"S":"order-1"}}}`)
+
+	items, err := s.repository.Query(context.Background(), "id = :id", map[string]any{":id": "order"}, func(input *dynamodb.QueryInput) {
+		input.Limit = aws.Int32(1)
+	})
+	s.Require().NoError(err)
+	s.Equal([]order{{ID: "order-1"}}, items)
+	s.Len(s.stub.received(), 1)
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package dynamodb
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracingMiddlewareID identifies the tracing middleware in the client middleware stack
+const tracingMiddlewareID = "GokitDynamoDBTracing"
+
+// tracingMiddleware adds a middleware to the initialize step of the client middleware stack,
+// starting a client span for every DynamoDB operation. The middleware runs after the operation
+// metadata is registered, so the span is named after the operation, e.g. DynamoDB.GetItem.
+func tracingMiddleware(tracer trace.Tracer) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc(tracingMiddlewareID, func(
+			ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler,
+		) (middleware.InitializeOutput, middleware.Metadata, error) {
+			operation := awsmiddleware.GetOperationName(ctx)
+
+			attrs := []attribute.KeyValue{
+				semconv.RPCSystemKey.String("aws-api"),
+				semconv.RPCService("DynamoDB"),
+				semconv.RPCMethod(operation),
+				semconv.DBSystemDynamoDB,
+			}
+
+			if table := tableName(in.Parameters); table != "" {
+				attrs = append(attrs, semconv.AWSDynamoDBTableNames(table))
+			}
+
+			ctx, span := tracer.Start(ctx, "DynamoDB."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+			defer span.End()
+
+			out, metadata, err := next.HandleInitialize(ctx, in)
+
+			if requestID, ok := awsmiddleware.GetRequestIDMetadata(metadata); ok {
+				span.SetAttributes(semconv.AWSRequestID(requestID))
+			}
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return out, metadata, err
+		}), middleware.After)
+	}
+}
+
+// tableName returns the table of the item and query operations, or an empty string for the other operations
+func tableName(params any) string {
+	switch p := params.(type) {
+	case *dynamodb.GetItemInput:
+		return aws.ToString(p.TableName)
+	case *dynamodb.PutItemInput:
+		return aws.ToString(p.TableName)
+	case *dynamodb.UpdateItemInput:
+		return aws.ToString(p.TableName)
+	case *dynamodb.DeleteItemInput:
+		return aws.ToString(p.TableName)
+	case *dynamodb.QueryInput:
+		return aws.ToString(p.TableName)
+	case *dynamodb.ScanInput:
+		return aws.ToString(p.TableName)
+	default:
+		return ""
+	}
+}
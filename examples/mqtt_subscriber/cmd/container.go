@@ -41,7 +41,7 @@ func NewContainer() (*Container, error) {
 	}
 
 	mqttClient := mqtt.NewMQTTClient(cfgs)
-	dispatcher := mqtt.NewDispatcher(cfgs.Logger, mqttClient.Client())
+	dispatcher := mqtt.NewDispatcher(cfgs.Logger, mqttClient)
 	basicConsumer := consumers.NewBasicMessage(cfgs.Logger, Topic)
 
 	return &Container{
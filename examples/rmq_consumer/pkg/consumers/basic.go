@@ -8,6 +8,7 @@ import (
 	"context"
 
 	"github.com/ralvescosta/gokit/logging"
+	"github.com/ralvescosta/gokit/messaging"
 	"github.com/ralvescosta/gokit/rabbitmq"
 	"go.uber.org/zap"
 )
@@ -34,10 +35,10 @@ func (b *basicConsumer) Install(dispatcher rabbitmq.Dispatcher) {
 	dispatcher.Register(b.queueName, BasicMessage{}, b.basicConsumer)
 }
 
-func (b *basicConsumer) basicConsumer(ctx context.Context, msg any, metadata any) error {
+func (b *basicConsumer) basicConsumer(ctx context.Context, msg any, metadata *messaging.Metadata) error {
 	basic := msg.(BasicMessage)
 
-	b.logger.Info("Basic Consumer", zap.String("msg", basic.String()))
+	b.logger.Info("Basic Consumer", zap.String("msg", basic.String()), zap.Int("attempt", metadata.Attempt))
 	return nil
 }
 
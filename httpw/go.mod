@@ -10,6 +10,7 @@ require (
 	github.com/ralvescosta/gokit/configs v1.21.0
 	github.com/ralvescosta/gokit/logging v1.21.0
 	github.com/ralvescosta/gokit/metrics v1.20.0
+	github.com/stretchr/testify v1.10.0
 	github.com/swaggo/http-swagger v1.3.4
 	go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp v0.52.0
 	go.uber.org/zap v1.27.0
@@ -38,7 +39,6 @@ require (
 	github.com/prometheus/common v0.54.0 // indirect
 	github.com/prometheus/procfs v0.15.1 // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
 	github.com/swaggo/files v1.0.1 // indirect
 	github.com/swaggo/swag v1.16.3 // indirect
 	go.opentelemetry.io/otel v1.27.0 // indirect
@@ -0,0 +1,169 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package health provides a registry of the health checks of the service dependencies,
+// such as databases and brokers, and an HTTP handler reporting the service readiness.
+//
+// Any type with a Check(ctx) error method is a Checker, so the dependencies packages
+// do not need to import this package, e.g. the sql.Monitor reports the database availability:
+//
+//	registry := health.NewRegistry()
+//	registry.Register("postgres", sql.NewMonitor(cfgs, db))
+//
+//	server := server.NewHTTPServerBuilder(cfgs).WithReadiness(registry).Build()
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// Checker reports the health of a dependency, returning nil when it is available.
+	Checker interface {
+		Check(ctx context.Context) error
+	}
+
+	// CheckerFunc adapts a function to the Checker interface.
+	CheckerFunc func(ctx context.Context) error
+
+	// Status is the status of a check or of the whole service.
+	Status string
+
+	// CheckResult is the result of a check.
+	CheckResult struct {
+		Status Status `json:"status"`
+		Error  string `json:"error,omitempty"`
+	}
+
+	// Report is the result of the checks of the registry.
+	Report struct {
+		Status Status                 `json:"status"`
+		Checks map[string]CheckResult `json:"checks"`
+	}
+
+	// Registry holds the checks reported by the readiness handler.
+	Registry struct {
+		mu       sync.RWMutex
+		checkers map[string]Checker
+		timeout  time.Duration
+	}
+)
+
+const (
+	// StatusUp reports an available dependency or a ready service.
+	StatusUp Status = "up"
+	// StatusDown reports an unavailable dependency or a service not ready.
+	StatusDown Status = "down"
+
+	// DefaultTimeout is the default time limit of each check.
+	DefaultTimeout = 5 * time.Second
+)
+
+// Check calls the function.
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// NewRegistry creates an empty Registry, limiting each check to the DefaultTimeout.
+func NewRegistry() *Registry {
+	return &Registry{checkers: map[string]Checker{}, timeout: DefaultTimeout}
+}
+
+// WithTimeout sets the time limit of each check.
+func (r *Registry) WithTimeout(timeout time.Duration) *Registry {
+	r.timeout = timeout
+	return r
+}
+
+// Register adds the check of a dependency, replacing the check registered with the same name.
+func (r *Registry) Register(name string, checker Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checkers[name] = checker
+}
+
+// Unregister removes the check of a dependency.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.checkers, name)
+}
+
+// Check runs the checks concurrently, each one limited to the registry timeout.
+//
+// Returns:
+//   - The report of the checks, up when every check succeeds.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.checkers))
+	for name := range r.checkers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	checkers := make([]Checker, len(names))
+	for i, name := range names {
+		checkers[i] = r.checkers[name]
+	}
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(names))
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, checker)
+		}()
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]CheckResult, len(names))}
+	for i, name := range names {
+		report.Checks[name] = results[i]
+
+		if results[i].Status == StatusDown {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+// Handler returns the readiness handler, responding the report as JSON
+// with the status 200 when every check succeeds and 503 otherwise.
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context())
+
+		status := http.StatusOK
+		if report.Status == StatusDown {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
+
+// run runs the check within the timeout.
+func (r *Registry) run(ctx context.Context, checker Checker) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	if err := checker.Check(ctx); err != nil {
+		return CheckResult{Status: StatusDown, Error: err.Error()}
+	}
+
+	return CheckResult{Status: StatusUp}
+}
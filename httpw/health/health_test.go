@@ -0,0 +1,99 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthTestSuite struct {
+	suite.Suite
+
+	registry *Registry
+}
+
+func TestHealthTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
+
+func (s *HealthTestSuite) SetupTest() {
+	s.registry = NewRegistry()
+}
+
+func (s *HealthTestSuite) TestHandlerAllUp() {
+	s.registry.Register("postgres", up())
+	s.registry.Register("rabbitmq", up())
+
+	status, report := s.ready()
+
+	s.Equal(http.StatusOK, status)
+	s.Equal(Report{
+		Status: StatusUp,
+		Checks: map[string]CheckResult{"postgres": {Status: StatusUp}, "rabbitmq": {Status: StatusUp}},
+	}, report)
+}
+
+func (s *HealthTestSuite) TestHandlerOneDown() {
+	s.registry.Register("postgres", up())
+	s.registry.Register("rabbitmq", CheckerFunc(func(context.Context) error { return errors.New("connection refused") }))
+
+	status, report := s.ready()
+
+	s.Equal(http.StatusServiceUnavailable, status)
+	s.Equal(StatusDown, report.Status)
+	s.Equal(CheckResult{Status: StatusUp}, report.Checks["postgres"])
+	s.Equal(CheckResult{Status: StatusDown, Error: "connection refused"}, report.Checks["rabbitmq"])
+}
+
+func (s *HealthTestSuite) TestCheckTimeout() {
+	s.registry.WithTimeout(10 * time.Millisecond)
+	s.registry.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+
+	report := s.registry.Check(context.Background())
+
+	s.Equal(StatusDown, report.Status)
+	s.Equal(context.DeadlineExceeded.Error(), report.Checks["slow"].Error)
+}
+
+func (s *HealthTestSuite) TestRegisterAndUnregister() {
+	s.Equal(Report{Status: StatusUp, Checks: map[string]CheckResult{}}, s.registry.Check(context.Background()))
+
+	s.registry.Register("postgres", CheckerFunc(func(context.Context) error { return errors.New("down") }))
+	s.Equal(StatusDown, s.registry.Check(context.Background()).Status)
+
+	s.registry.Register("postgres", up())
+	s.Equal(StatusUp, s.registry.Check(context.Background()).Status)
+
+	s.registry.Unregister("postgres")
+	s.Empty(s.registry.Check(context.Background()).Checks)
+}
+
+// ready requests the readiness handler, returning the response status and report.
+func (s *HealthTestSuite) ready() (int, Report) {
+	rec := httptest.NewRecorder()
+	s.registry.Handler()(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	var report Report
+	s.Require().NoError(json.Unmarshal(rec.Body.Bytes(), &report))
+	s.Equal("application/json", rec.Header().Get("Content-Type"))
+
+	return rec.Code, report
+}
+
+// up returns a check that always succeeds.
+func up() Checker {
+	return CheckerFunc(func(context.Context) error { return nil })
+}
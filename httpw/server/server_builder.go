@@ -21,6 +21,7 @@ import (
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	"github.com/ralvescosta/gokit/httpw"
+	"github.com/ralvescosta/gokit/httpw/health"
 )
 
 type (
@@ -53,6 +54,9 @@ type (
 		// ExportPrometheusScraping enables a Prometheus metrics endpoint.
 		ExportPrometheusScraping() HTTPServerBuilder
 
+		// WithReadiness enables a readiness endpoint reporting the checks of the registry.
+		WithReadiness(registry *health.Registry) HTTPServerBuilder
+
 		// Build constructs and returns an HTTPServer instance.
 		Build() HTTPServer
 	}
@@ -71,6 +75,7 @@ type (
 		withMetric               bool
 		exportPrometheusScraping bool
 		withOpenAPI              bool
+		readiness                *health.Registry
 		_metricKind              MetricKind
 	}
 )
@@ -125,6 +130,13 @@ func (s *httpServerBuilder) ExportPrometheusScraping() HTTPServerBuilder {
 	return s
 }
 
+// WithReadiness enables a readiness endpoint at /ready, responding 200 when every check
+// of the registry succeeds and 503 otherwise. The /health endpoint keeps reporting liveness.
+func (s *httpServerBuilder) WithReadiness(registry *health.Registry) HTTPServerBuilder {
+	s.readiness = registry
+	return s
+}
+
 // Signal sets a channel to receive OS signals for graceful shutdown.
 func (s *httpServerBuilder) Signal(sig chan os.Signal) HTTPServerBuilder {
 	s.sig = sig
@@ -172,6 +184,10 @@ func (s *httpServerBuilder) Build() HTTPServer {
 		s.openAPIEndpoint(&server)
 	}
 
+	if s.readiness != nil {
+		server.router.Method(http.MethodGet, "/ready", s.readiness.Handler())
+	}
+
 	s.logger.Debug(httpw.Message("server was created"))
 	return &server
 }
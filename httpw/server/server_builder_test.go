@@ -0,0 +1,57 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/httpw/health"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type ServerBuilderTestSuite struct {
+	suite.Suite
+
+	cfgs *configs.Configs
+}
+
+func TestServerBuilderTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerBuilderTestSuite))
+}
+
+func (s *ServerBuilderTestSuite) SetupTest() {
+	s.cfgs = &configs.Configs{Logger: zap.NewNop(), HTTPConfigs: &configs.HTTPConfigs{}}
+}
+
+func (s *ServerBuilderTestSuite) TestReadinessNotMounted() {
+	server := NewHTTPServerBuilder(s.cfgs).Build()
+
+	s.Equal(http.StatusNotFound, s.get(server, "/ready"))
+}
+
+func (s *ServerBuilderTestSuite) TestReadinessMounted() {
+	registry := health.NewRegistry()
+	server := NewHTTPServerBuilder(s.cfgs).WithReadiness(registry).Build()
+
+	s.Equal(http.StatusOK, s.get(server, "/ready"))
+
+	registry.Register("postgres", health.CheckerFunc(func(context.Context) error { return errors.New("down") }))
+	s.Equal(http.StatusServiceUnavailable, s.get(server, "/ready"))
+	s.Equal(http.StatusOK, s.get(server, "/health"))
+}
+
+// get requests the path to the server router, returning the response status.
+func (s *ServerBuilderTestSuite) get(server HTTPServer, path string) int {
+	rec := httptest.NewRecorder()
+	server.(*httpServer).router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+
+	return rec.Code
+}
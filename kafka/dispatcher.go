@@ -12,6 +12,7 @@ import (
 	"github.com/ralvescosta/gokit/configs"
 	"github.com/ralvescosta/gokit/logging"
 	"github.com/ralvescosta/gokit/messaging"
+	"github.com/ralvescosta/gokit/messaging/cloudevents"
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
@@ -27,36 +28,70 @@ type kafkaDispatcher struct {
 	handlers map[string]map[any]messaging.ConsumerHandler
 	mutex    sync.RWMutex
 
+	// middlewares are applied to every handler, wrapping the handler-specific middlewares.
+	middlewares []messaging.Middleware
+	// handlerMiddlewares stores the middlewares registered for each handler, keyed as the handlers map.
+	handlerMiddlewares map[string]map[any][]messaging.Middleware
+
 	// kafkaReaders is a slice of Kafka readers used to consume messages.
 	kafkaReaders []*kafka.Reader
+
+	// metrics records the consumption of the messages.
+	metrics *messaging.ConsumerMetrics
+}
+
+// acknowledger settles a message manually, implementing messaging.Acknowledger.
+// Kafka acknowledges messages by committing their offset, so they cannot be requeued or deferred.
+type acknowledger struct {
+	reader  *kafka.Reader
+	message kafka.Message
 }
 
 // NewDispatcher creates a new instance of kafkaDispatcher.
 // It initializes the handlers map and returns a pointer to the dispatcher instance.
 func NewDispatcher(configs *configs.Configs) *kafkaDispatcher {
+	metrics, err := messaging.NewConsumerMetrics()
+	if err != nil {
+		configs.Logger.Warn("Failure to create the consumer metrics", zap.Error(err))
+	}
+
 	return &kafkaDispatcher{
-		logger:       configs.Logger,
-		handlers:     make(map[string]map[any]messaging.ConsumerHandler),
-		kafkaReaders: []*kafka.Reader{},
+		logger:             configs.Logger,
+		handlers:           make(map[string]map[any]messaging.ConsumerHandler),
+		handlerMiddlewares: make(map[string]map[any][]messaging.Middleware),
+		kafkaReaders:       []*kafka.Reader{},
+		metrics:            metrics,
 	}
 }
 
 // Register associates a message type and source with a specific messaging.ConsumerHandler.
 // It ensures that the same handler is not registered multiple times for the same message type and source.
+// Messages are routed by their type header, resolving the aliases of the messaging.DefaultTypeRegistry,
+// or by their key, and CloudEvents by their type when registered with cloudevents.OfType.
 //
 // Parameters:
 // - from: The source of the message (e.g., Kafka topic).
-// - msgType: The type of the message.
+// - msgType: The message key, or a value of the message type, named by messaging.TypeName.
 // - handler: The handler function to process the message.
+// - middlewares: Middlewares applied only to this handler, after the global ones (optional).
 //
 // Returns:
 // - An error if a handler is already registered for the given message type and source.
-func (d *kafkaDispatcher) Register(from string, msgType any, handler messaging.ConsumerHandler) error {
+func (d *kafkaDispatcher) Register(from string, msgType any, handler messaging.ConsumerHandler, middlewares ...messaging.Middleware) error {
+	switch typed := msgType.(type) {
+	case string:
+	case *cloudevents.TypedMessage:
+		msgType = typed.Type
+	default:
+		msgType = messaging.TypeName(msgType)
+	}
+
 	d.mutex.Lock()
 	defer d.mutex.Unlock()
 
 	if _, exists := d.handlers[from]; !exists {
 		d.handlers[from] = make(map[any]messaging.ConsumerHandler)
+		d.handlerMiddlewares[from] = make(map[any][]messaging.Middleware)
 	}
 
 	if _, exists := d.handlers[from][msgType]; exists {
@@ -72,42 +107,193 @@ func (d *kafkaDispatcher) Register(from string, msgType any, handler messaging.C
 
 	d.kafkaReaders = append(d.kafkaReaders, reader)
 	d.handlers[from][msgType] = handler
+	d.handlerMiddlewares[from][msgType] = middlewares
 
 	return nil
 }
 
+// Use registers middlewares applied to every handler of the dispatcher.
+// Must be called before ConsumeBlocking.
+//
+// Parameters:
+// - middlewares: The middlewares to be applied, the first one being the outermost.
+func (d *kafkaDispatcher) Use(middlewares ...messaging.Middleware) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.middlewares = append(d.middlewares, middlewares...)
+}
+
 // ConsumeBlocking starts consuming messages from Kafka and dispatches them to the appropriate registered handlers.
 // It creates a separate goroutine for each Kafka reader to consume messages concurrently.
+// The offset of each message is committed once its handler returns, unless the handler acknowledged it.
+// The consumer lag of each partition is recorded from the high water mark of the fetched messages.
+// CloudEvents are parsed, passing the event data to the handlers and the event through the context.
 func (d *kafkaDispatcher) ConsumeBlocking() {
+	// Wrap the handlers with the global and handler-specific middlewares
+	d.mutex.Lock()
+	for from, handlers := range d.handlers {
+		for msgType, handler := range handlers {
+			middlewares := append(append([]messaging.Middleware{}, d.middlewares...), d.handlerMiddlewares[from][msgType]...)
+			handlers[msgType] = messaging.Chain(handler, middlewares...)
+		}
+	}
+	d.mutex.Unlock()
+
 	for _, reader := range d.kafkaReaders {
 		go func(r *kafka.Reader) {
 			for {
-				msg, err := r.ReadMessage(context.Background())
+				msg, err := r.FetchMessage(context.Background())
 				if err != nil {
 					d.logger.Error("Error reading message from Kafka", zap.Error(err))
 					continue
 				}
 
+				ctx := context.Background()
+				labels := messaging.ConsumerLabels{System: System, Topic: msg.Topic, Consumer: r.Config().GroupID}
+				d.metrics.Lag(ctx, labels, lag(msg))
+
+				// The type label is only set to the types of the registered handlers, to bound
+				// the cardinality of the metrics; the other messages are labeled UnknownMessageType
+				labels.Type = UnknownMessageType
+				discard := func() {
+					d.commit(r, msg)
+					d.metrics.Received(ctx, labels)
+					d.metrics.DeadLettered(ctx, labels)
+				}
+
 				d.mutex.RLock()
 				handlersForSource, exists := d.handlers[msg.Topic]
 				d.mutex.RUnlock()
 
 				if !exists {
 					d.logger.Warn("No handlers registered for topic", zap.String("topic", msg.Topic))
+					discard()
+					continue
+				}
+
+				event, value, err := cloudevents.Parse(cloudevents.Kafka, stringHeaders(msg.Headers), header(msg.Headers, ContentTypeHeader), msg.Value)
+				if err != nil {
+					d.logger.Error("Invalid cloudevent", zap.String("topic", msg.Topic), zap.Error(err))
+					discard()
 					continue
 				}
 
-				handler, exists := handlersForSource[string(msg.Key)]
+				msgType := header(msg.Headers, TypeHeader)
+				if event != nil {
+					msgType = event.Type
+					ctx = cloudevents.NewContext(ctx, event)
+				}
+
+				handler, exists := handlersForSource[messaging.ResolveTypeName(msgType)]
+				if !exists && event == nil {
+					msgType = string(msg.Key)
+					handler, exists = handlersForSource[msgType]
+				}
+
 				if !exists {
-					d.logger.Warn("No handler registered for message type", zap.String("messageType", string(msg.Key)))
+					d.logger.Warn("No handler registered for message type", zap.String("messageType", msgType))
+					discard()
 					continue
 				}
 
-				ctx := context.Background()
-				if err := handler(ctx, msg.Value, msg.Headers); err != nil {
+				labels.Type = msgType
+				d.metrics.Received(ctx, labels)
+
+				metadata := newMetadata(&msg, event, msgType).WithAcknowledger(&acknowledger{reader: r, message: msg})
+
+				done := d.metrics.Start(ctx, labels)
+				err = handler(ctx, value, metadata)
+				done(err)
+
+				if err != nil {
 					d.logger.Error("Error handling message", zap.Error(err))
 				}
+
+				// Messages settled by the handler are not committed again. The offset is committed
+				// through the metadata, so a handler still running in background, e.g. after a timeout,
+				// cannot commit it a second time
+				if err := metadata.Ack(); err != nil && !errors.Is(err, messaging.AlreadySettledError) {
+					d.logger.Error("Error committing message", zap.String("topic", msg.Topic), zap.Error(err))
+				}
 			}
 		}(reader)
 	}
 }
+
+// commit commits the offset of the message, logging the failures.
+func (d *kafkaDispatcher) commit(r *kafka.Reader, msg kafka.Message) {
+	if err := r.CommitMessages(context.Background(), msg); err != nil {
+		d.logger.Error("Error committing message", zap.String("topic", msg.Topic), zap.Error(err))
+	}
+}
+
+// newMetadata builds the metadata passed to the handlers from the message headers.
+// CloudEvents without the message ID header use the event ID.
+func newMetadata(msg *kafka.Message, event *cloudevents.Event, msgType string) *messaging.Metadata {
+	messageID := header(msg.Headers, MessageIDHeader)
+	if messageID == "" && event != nil {
+		messageID = event.ID
+	}
+
+	return &messaging.Metadata{
+		MessageID:     messageID,
+		CorrelationID: header(msg.Headers, CorrelationIDHeader),
+		Type:          msgType,
+		Source:        msg.Topic,
+		Timestamp:     msg.Time,
+		Attempt:       1,
+		Headers:       stringHeaders(msg.Headers),
+		Raw:           msg,
+	}
+}
+
+// Ack commits the offset of the message.
+func (a *acknowledger) Ack() error {
+	return a.reader.CommitMessages(context.Background(), a.message)
+}
+
+// Nack commits the offset of the message, discarding it. Kafka cannot requeue a single message,
+// so requeueing returns messaging.AcknowledgmentNotSupportedError.
+func (a *acknowledger) Nack(requeue bool) error {
+	if requeue {
+		return messaging.AcknowledgmentNotSupportedError
+	}
+
+	return a.reader.CommitMessages(context.Background(), a.message)
+}
+
+// Defer returns messaging.AcknowledgmentNotSupportedError, Kafka cannot redeliver a single message.
+func (a *acknowledger) Defer() error {
+	return messaging.AcknowledgmentNotSupportedError
+}
+
+// lag returns the number of messages after the given one in its partition.
+func lag(msg kafka.Message) int64 {
+	if msg.HighWaterMark <= msg.Offset {
+		return 0
+	}
+
+	return msg.HighWaterMark - msg.Offset - 1
+}
+
+// stringHeaders returns the Kafka headers as a map. Repeated headers keep the last value.
+func stringHeaders(headers []kafka.Header) map[string]string {
+	values := make(map[string]string, len(headers))
+	for _, h := range headers {
+		values[h.Key] = string(h.Value)
+	}
+
+	return values
+}
+
+// header returns the value of the Kafka header, or an empty string when it is not set.
+func header(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
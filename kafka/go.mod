@@ -3,6 +3,7 @@ module github.com/ralvescosta/gokit/kafka
 go 1.24.0
 
 require (
+	github.com/google/uuid v1.6.0
 	github.com/ralvescosta/gokit/configs v1.32.0
 	github.com/ralvescosta/gokit/messaging v0.0.0-20250423125402-05dd81b22867
 	go.uber.org/zap v1.27.0
@@ -10,11 +11,16 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/go-logr/logr v1.4.1 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/klauspost/compress v1.18.0 // indirect
 	github.com/pierrec/lz4/v4 v4.1.22 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
 	github.com/stretchr/testify v1.10.0 // indirect
+	go.opentelemetry.io/otel v1.27.0 // indirect
+	go.opentelemetry.io/otel/metric v1.27.0 // indirect
+	go.opentelemetry.io/otel/trace v1.27.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
@@ -23,3 +29,9 @@ require (
 	github.com/segmentio/kafka-go v0.4.47
 	go.uber.org/multierr v1.11.0 // indirect
 )
+
+replace github.com/ralvescosta/gokit/messaging => ../messaging
+
+replace github.com/ralvescosta/gokit/configs => ../configs
+
+replace github.com/ralvescosta/gokit/logging => ../logging
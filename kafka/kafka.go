@@ -13,8 +13,10 @@
 //
 // - Type-safe message publishing with the Publisher interface
 // - Consumer-side message dispatching with the Dispatcher interface
-// - Support for message headers and metadata
+// - Support for message headers and metadata (messaging.Metadata, holding the *kafka.Message as Raw)
+// - Offsets committed once the handlers return, or manually with messaging.Metadata.Ack
 // - Integration with GoKit's configuration, logging, and tracing systems
+// - Consumer metrics (received, succeeded, failed messages, in-flight messages, durations and partition lag)
 // - Error handling and recovery mechanisms
 //
 // # Components
@@ -31,6 +33,10 @@
 //	key := "new-order"
 //	err := publisher.Publish(ctx, &topic, nil, &key, orderData)
 //
+// Publishing with an envelope:
+//
+//	err := messaging.Publish(ctx, publisher, "orders", orderData, messaging.WithKey("new-order"))
+//
 // Dispatcher example:
 //
 //	dispatcher := kafka.NewDispatcher(configs)
@@ -46,3 +52,11 @@
 //
 // See the configs package documentation for all available configuration options.
 package kafka
+
+const (
+	// System identifies Kafka in the "system" attribute of the consumer metrics.
+	System = "kafka"
+	// UnknownMessageType is the "type" attribute of the consumer metrics of the messages
+	// whose type has no registered handler.
+	UnknownMessageType = "unknown"
+)
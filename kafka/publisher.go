@@ -6,9 +6,11 @@ package kafka
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/ralvescosta/gokit/configs"
 	"github.com/ralvescosta/gokit/logging"
 	"github.com/ralvescosta/gokit/messaging"
@@ -16,17 +18,40 @@ import (
 	"go.uber.org/zap"
 )
 
+// Headers and content types set by the publisher.
+const (
+	// MessageIDHeader is the Kafka header holding the message ID.
+	MessageIDHeader = "message-id"
+	// CorrelationIDHeader is the Kafka header holding the correlation ID.
+	CorrelationIDHeader = "correlation-id"
+	// ContentTypeHeader is the Kafka header holding the content type of the message value.
+	ContentTypeHeader = "content-type"
+	// TypeHeader is the Kafka header holding the message type, named by messaging.TypeName.
+	TypeHeader = "message-type"
+
+	// JSONContentType is the MIME type used for JSON encoded values.
+	JSONContentType = "application/json"
+	// OctetStreamContentType is the MIME type used for raw values ([]byte or string).
+	OctetStreamContentType = "application/octet-stream"
+)
+
 // kafkaPublisher is the concrete implementation of the messaging.Publisher interface.
 // It uses a Kafka writer to send messages to Kafka topics.
 //
 // Fields:
 // - logger: A structured logger for logging events and errors.
 // - writer: A Kafka writer instance for sending messages.
+// - interceptors: Interceptors executed for every published message.
 type kafkaPublisher struct {
-	logger logging.Logger
-	writer *kafka.Writer
+	logger       logging.Logger
+	writer       *kafka.Writer
+	interceptors []messaging.PublishInterceptor
 }
 
+// legacyKey is the envelope extension key marking the messages sent by Publish,
+// whose value and headers are kept as they were before PublishEnvelope.
+type legacyKey struct{}
+
 // NewPublisher creates a new instance of kafkaPublisher.
 //
 // Parameters:
@@ -53,30 +78,16 @@ func NewPublisher(configs *configs.Configs) messaging.Publisher {
 // - to: The destination topic where the message should be sent (optional).
 // - from: The source or origin of the message (optional).
 // - key: A routing key or identifier for the message (optional).
-// - msg: The message payload to be sent.
+// - msg: The message payload to be sent, formatted with the %v verb.
 // - options: Additional dynamic parameters for the message (optional).
 //
 // Returns:
 // - An error if the message could not be sent.
 func (p *kafkaPublisher) Publish(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
-	if to == nil || *to == "" {
-		return fmt.Errorf("destination topic cannot be empty")
-	}
-
-	topic := *to
-	messageKey := ""
-	if key != nil {
-		messageKey = *key
-	}
+	envelope := messaging.EnvelopeFromOptions(to, from, key, options...)
+	envelope.SetExtension(legacyKey{}, true)
 
-	message := kafka.Message{
-		Topic: topic,
-		Key:   []byte(messageKey),
-		Value: []byte(fmt.Sprintf("%v", msg)),
-	}
-
-	p.logger.Info("Publishing message", zap.String("topic", topic), zap.String("key", messageKey))
-	return p.writer.WriteMessages(ctx, message)
+	return p.PublishEnvelope(ctx, envelope, msg)
 }
 
 // PublishDeadline sends a message to the specified Kafka topic with a deadline.
@@ -88,7 +99,7 @@ func (p *kafkaPublisher) Publish(ctx context.Context, to, from, key *string, msg
 // - to: The destination topic where the message should be sent (optional).
 // - from: The source or origin of the message (optional).
 // - key: A routing key or identifier for the message (optional).
-// - msg: The message payload to be sent.
+// - msg: The message payload to be sent, formatted with the %v verb.
 // - options: Additional dynamic parameters for the message (optional).
 //
 // Returns:
@@ -99,3 +110,119 @@ func (p *kafkaPublisher) PublishDeadline(ctx context.Context, to, from, key *str
 
 	return p.Publish(ctx, to, from, key, msg, options...)
 }
+
+// PublishEnvelope sends a message to the Kafka topic defined in the envelope destination.
+//
+// The envelope key is used as the message key and the envelope headers, message ID,
+// correlation ID and content type are sent as Kafka headers. Raw payloads ([]byte and string)
+// are sent as they are, any other value is encoded as JSON.
+//
+// Parameters:
+// - ctx: The context for managing deadlines, cancellations, and other request-scoped values.
+// - envelope: The destination and delivery properties of the message.
+// - msg: The message payload to be sent.
+//
+// Returns:
+// - An error if the message could not be sent, or messaging.DelayNotSupportedError if the envelope has a delay.
+func (p *kafkaPublisher) PublishEnvelope(ctx context.Context, envelope *messaging.Envelope, msg any) error {
+	if envelope == nil || envelope.To == "" {
+		return fmt.Errorf("destination topic cannot be empty")
+	}
+
+	if envelope.Delay > 0 {
+		return messaging.DelayNotSupportedError
+	}
+
+	_, legacy := envelope.Extension(legacyKey{})
+
+	value, contentType, err := encode(msg, legacy)
+	if err != nil {
+		p.logger.Error("Failure to encode message", zap.String("topic", envelope.To), zap.Error(err))
+		return err
+	}
+
+	envelope = envelope.Clone()
+	if envelope.ContentType == "" {
+		envelope.ContentType = contentType
+	}
+
+	publish := messaging.ChainPublish(p.send, p.interceptors...)
+
+	return publish(ctx, &messaging.OutgoingMessage{Envelope: envelope, Message: msg, Body: value})
+}
+
+// Intercept registers interceptors executed for every published message.
+//
+// Parameters:
+// - interceptors: The interceptors to be applied, the first one being the outermost.
+func (p *kafkaPublisher) Intercept(interceptors ...messaging.PublishInterceptor) {
+	p.interceptors = append(p.interceptors, interceptors...)
+}
+
+// send writes the outgoing message to Kafka, mapping the envelope to the message key and headers.
+//
+// Parameters:
+// - ctx: The context for managing deadlines, cancellations, and other request-scoped values.
+// - out: The encoded message and its envelope.
+//
+// Returns:
+// - An error if the message could not be written.
+func (p *kafkaPublisher) send(ctx context.Context, out *messaging.OutgoingMessage) error {
+	envelope := out.Envelope
+
+	messageID := envelope.MessageID
+	if messageID == "" {
+		messageID = uuid.NewString()
+	}
+
+	headers := []kafka.Header{
+		{Key: MessageIDHeader, Value: []byte(messageID)},
+		{Key: ContentTypeHeader, Value: []byte(envelope.ContentType)},
+		{Key: TypeHeader, Value: []byte(messaging.TypeName(out.Message))},
+	}
+
+	// The messages sent by Publish only carry the headers added by the interceptors
+	if _, legacy := envelope.Extension(legacyKey{}); legacy {
+		headers = nil
+	}
+
+	if envelope.CorrelationID != "" {
+		headers = append(headers, kafka.Header{Key: CorrelationIDHeader, Value: []byte(envelope.CorrelationID)})
+	}
+
+	for k, v := range envelope.Headers {
+		headers = append(headers, kafka.Header{Key: k, Value: []byte(v)})
+	}
+
+	message := kafka.Message{
+		Topic:   envelope.To,
+		Key:     []byte(envelope.Key),
+		Value:   out.Body,
+		Headers: headers,
+	}
+
+	p.logger.Info("Publishing message", zap.String("topic", envelope.To), zap.String("key", envelope.Key))
+	return p.writer.WriteMessages(ctx, message)
+}
+
+// encode converts the message into the Kafka message value and returns its content type.
+// The messages sent by Publish are formatted with the %v verb, without content type.
+func encode(msg any, legacy bool) ([]byte, string, error) {
+	if legacy {
+		return []byte(fmt.Sprintf("%v", msg)), "", nil
+	}
+
+	switch v := msg.(type) {
+	case []byte:
+		return v, OctetStreamContentType, nil
+	case string:
+		return []byte(v), OctetStreamContentType, nil
+	}
+
+	value, err := json.Marshal(msg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return value, JSONContentType, nil
+}
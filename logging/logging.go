@@ -37,6 +37,13 @@ type (
 		// then calls os.Exit(1).
 		Fatal(msg string, fields ...zap.Field)
 	}
+
+	// levelCore is the core of the loggers created by this package, holding the level
+	// of the logger so it can be changed without recreating the logger.
+	levelCore struct {
+		zapcore.Core
+		level zap.AtomicLevel
+	}
 )
 
 var (
@@ -45,21 +52,44 @@ var (
 	openFile = os.OpenFile
 )
 
+// AtomicLevel returns the level of a logger created by this package, shared by the loggers derived from it
+// with With or Named. Changing it applies the new level to these loggers immediately. It also implements
+// http.Handler, so it can be exposed to read and change the level at runtime.
+//
+// Returns:
+//   - The level of the logger, and false when the logger was not created by this package.
+func AtomicLevel(logger Logger) (zap.AtomicLevel, bool) {
+	core, ok := logger.With().Core().(*levelCore)
+	if !ok {
+		return zap.AtomicLevel{}, false
+	}
+
+	return core.level, true
+}
+
+// SetLevel changes the level of a logger created by this package at runtime.
+// Unrecognized levels are mapped to the Info level, and loggers not created by this package are left untouched.
+func SetLevel(logger Logger, level configs.LogLevel) {
+	if atomicLevel, ok := AtomicLevel(logger); ok {
+		atomicLevel.SetLevel(mapZapLogLevel(&configs.AppConfigs{LogLevel: level}))
+	}
+}
+
 // NewDefaultLogger creates a new logger that outputs to stdout.
 // It configures the logger based on the environment:
 // - Production/Staging: Uses JSON encoder
 // - Development: Uses colored console output
 //
-// The log level is determined by the configuration provided.
+// The log level is determined by the configuration provided, and changed at runtime with SetLevel.
 func NewDefaultLogger(cfgs *configs.Configs) (Logger, error) {
-	zapLogLevel := mapZapLogLevel(cfgs.AppConfigs)
+	zapLogLevel := zap.NewAtomicLevelAt(mapZapLogLevel(cfgs.AppConfigs))
 
 	if cfgs.AppConfigs.GoEnv == configs.ProductionEnv || cfgs.AppConfigs.GoEnv == configs.StagingEnv {
 		logConfig := zap.NewProductionEncoderConfig()
 		logConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 		encoder := zapcore.NewJSONEncoder(logConfig)
 
-		cfgs.Logger = zap.New(zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapLogLevel)).Named(cfgs.AppConfigs.AppName)
+		cfgs.Logger = zap.New(withLevel(zapcore.NewCore(encoder, zapcore.AddSync(os.Stdout), zapLogLevel), zapLogLevel)).Named(cfgs.AppConfigs.AppName)
 
 		return cfgs.Logger, nil
 	}
@@ -69,7 +99,7 @@ func NewDefaultLogger(cfgs *configs.Configs) (Logger, error) {
 	logConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	consoleEncoder := zapcore.NewConsoleEncoder(logConfig)
 
-	cfgs.Logger = zap.New(zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), zapLogLevel)).Named(cfgs.AppConfigs.AppName)
+	cfgs.Logger = zap.New(withLevel(zapcore.NewCore(consoleEncoder, zapcore.AddSync(os.Stdout), zapLogLevel), zapLogLevel)).Named(cfgs.AppConfigs.AppName)
 
 	return cfgs.Logger, nil
 }
@@ -78,8 +108,9 @@ func NewDefaultLogger(cfgs *configs.Configs) (Logger, error) {
 // The file path is specified in the configuration.
 // In production/staging environments, it only outputs to the file in JSON format.
 // In development environments, it outputs to both stdout (colored) and the file (JSON).
+// As for NewDefaultLogger, the log level is changed at runtime with SetLevel.
 func NewFileLogger(cfgs *configs.Configs) (Logger, error) {
-	zapLogLevel := mapZapLogLevel(cfgs.AppConfigs)
+	zapLogLevel := zap.NewAtomicLevelAt(mapZapLogLevel(cfgs.AppConfigs))
 
 	file, err := openFile(
 		cfgs.AppConfigs.LogPath,
@@ -95,7 +126,7 @@ func NewFileLogger(cfgs *configs.Configs) (Logger, error) {
 		logConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 		encoder := zapcore.NewJSONEncoder(logConfig)
 
-		cfgs.Logger = zap.New(zapcore.NewCore(encoder, zapcore.AddSync(file), zapLogLevel)).Named(cfgs.AppConfigs.AppName)
+		cfgs.Logger = zap.New(withLevel(zapcore.NewCore(encoder, zapcore.AddSync(file), zapLogLevel), zapLogLevel)).Named(cfgs.AppConfigs.AppName)
 
 		return cfgs.Logger, nil
 	}
@@ -111,11 +142,21 @@ func NewFileLogger(cfgs *configs.Configs) (Logger, error) {
 		zapcore.NewCore(fileEncoder, zapcore.AddSync(file), zapLogLevel),
 	)
 
-	cfgs.Logger = zap.New(core).Named(cfgs.AppConfigs.AppName)
+	cfgs.Logger = zap.New(withLevel(core, zapLogLevel)).Named(cfgs.AppConfigs.AppName)
 
 	return cfgs.Logger, nil
 }
 
+// withLevel wraps the core to keep the level of the logger.
+func withLevel(core zapcore.Core, level zap.AtomicLevel) zapcore.Core {
+	return &levelCore{Core: core, level: level}
+}
+
+// With adds the fields to the core, keeping the level of the logger.
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), level: c.level}
+}
+
 // mapZapLogLevel converts the application config log level to the corresponding
 // Zap log level. It defaults to InfoLevel if the level is not recognized.
 func mapZapLogLevel(e *configs.AppConfigs) zapcore.Level {
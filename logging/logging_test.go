@@ -31,6 +31,37 @@ func (s *LoggerTestSuite) TestMapZapLogLevel() {
 	s.Equal(mapZapLogLevel(&configs.AppConfigs{LogLevel: configs.PANIC}), zap.PanicLevel)
 }
 
+func (s *LoggerTestSuite) TestAtomicLevel() {
+	logger, err := NewDefaultLogger(&configs.Configs{
+		AppConfigs: &configs.AppConfigs{GoEnv: configs.ProductionEnv, LogLevel: configs.ERROR},
+	})
+	s.Require().NoError(err)
+
+	zapLogger := logger.(*zap.Logger)
+	s.False(zapLogger.Core().Enabled(zap.InfoLevel))
+
+	// The level is kept by each logger, creating another logger does not change it
+	other, err := NewDefaultLogger(&configs.Configs{
+		AppConfigs: &configs.AppConfigs{GoEnv: configs.ProductionEnv, LogLevel: configs.INFO},
+	})
+	s.Require().NoError(err)
+	s.False(zapLogger.Core().Enabled(zap.InfoLevel))
+
+	derived := zapLogger.With(zap.String("key", "value"))
+
+	SetLevel(logger, configs.DEBUG)
+	s.True(zapLogger.Core().Enabled(zap.DebugLevel))
+	s.True(derived.Core().Enabled(zap.DebugLevel))
+	s.False(other.(*zap.Logger).Core().Enabled(zap.DebugLevel))
+
+	level, ok := AtomicLevel(derived)
+	s.True(ok)
+	s.Equal(zap.DebugLevel, level.Level())
+
+	_, ok = AtomicLevel(zap.NewNop())
+	s.False(ok)
+}
+
 func (s *LoggerTestSuite) TestNewDefaultLoggerProd() {
 	logConfigs := configs.Configs{
 		AppConfigs: &configs.AppConfigs{
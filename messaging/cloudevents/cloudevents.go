@@ -0,0 +1,331 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package cloudevents adds opt-in CloudEvents 1.0 support to the messaging publishers and dispatchers.
+//
+// Publishers emit CloudEvents through the Interceptor, either in binary content mode, where the
+// event attributes are sent as protocol headers (e.g. cloudEvents:type for AMQP, ce_type for Kafka)
+// and the body is the event data, or in structured content mode, where the body is the JSON event
+// (application/cloudevents+json) holding the attributes and the data.
+//
+// The rabbitmq, kafka and mqtt dispatchers parse the received events, passing the data to the
+// handlers and the attributes through the delivery metadata and the context (FromContext).
+// Handlers registered with OfType are routed by the event type.
+//
+// Example:
+//
+//	publisher := rabbitmq.NewPublisher(cfgs, channel)
+//	publisher.Intercept(cloudevents.Interceptor(cloudevents.AMQP, cloudevents.WithSource("/orders")))
+//
+//	err := messaging.Publish(ctx, publisher, "orders", OrderCreated{ID: "1"},
+//		cloudevents.WithType("com.acme.order.created"),
+//	)
+//
+//	err = dispatcher.Register("orders", cloudevents.OfType("com.acme.order.created", OrderCreated{}), handler)
+package cloudevents
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+type (
+	// Mode is the CloudEvents content mode used to publish the events.
+	Mode int
+
+	// Binding describes how a protocol carries the event attributes.
+	Binding struct {
+		// Name is the name of the protocol binding.
+		Name string
+		// Prefix is prepended to the attribute names in the protocol headers, in binary mode.
+		Prefix string
+		// Binary reports whether the protocol supports headers, and so the binary mode.
+		// Events published with bindings that do not support it are sent in structured mode.
+		Binary bool
+	}
+
+	// Event holds the context attributes of a CloudEvent.
+	Event struct {
+		// ID identifies the event. Publishers use the envelope message ID.
+		ID string
+		// Source identifies the context in which the event happened.
+		Source string
+		// SpecVersion is the CloudEvents specification version, always 1.0.
+		SpecVersion string
+		// Type is the type of the event, used by the dispatchers to route it.
+		Type string
+		// Subject is the subject of the event in the context of the source.
+		Subject string
+		// Time is the time the event happened.
+		Time time.Time
+		// DataContentType is the MIME type of the event data.
+		DataContentType string
+		// DataSchema is the URI of the schema the event data adheres to.
+		DataSchema string
+		// Extensions holds the extension attributes of the event.
+		Extensions map[string]string
+	}
+)
+
+const (
+	// Binary sends the event attributes as protocol headers and the data as the body.
+	Binary Mode = iota
+	// Structured sends the whole event, attributes and data, as a JSON body.
+	Structured
+)
+
+const (
+	// SpecVersion is the supported CloudEvents specification version.
+	SpecVersion = "1.0"
+	// StructuredContentType is the content type of the events in structured mode.
+	StructuredContentType = "application/cloudevents+json"
+)
+
+var (
+	// AMQP is the AMQP protocol binding, used with the rabbitmq package.
+	AMQP = Binding{Name: "amqp", Prefix: "cloudEvents:", Binary: true}
+	// Kafka is the Kafka protocol binding, used with the kafka package.
+	Kafka = Binding{Name: "kafka", Prefix: "ce_", Binary: true}
+	// MQTT is the MQTT 3.1.1 protocol binding, used with the mqtt package.
+	// MQTT 3.1.1 has no headers, so the events are always sent in structured mode.
+	MQTT = Binding{Name: "mqtt", Binary: false}
+
+	// InvalidEventError is returned when a received message carries an invalid CloudEvent.
+	InvalidEventError = messaging.NewMessagingError("invalid cloudevent")
+	// MissingSourceError is returned when a message is published without an event source.
+	MissingSourceError = messaging.NewMessagingError("cloudevent source is required")
+)
+
+// attribute names defined by the specification.
+const (
+	attrID              = "id"
+	attrSource          = "source"
+	attrSpecVersion     = "specversion"
+	attrType            = "type"
+	attrSubject         = "subject"
+	attrTime            = "time"
+	attrDataContentType = "datacontenttype"
+	attrDataSchema      = "dataschema"
+	attrData            = "data"
+	attrDataBase64      = "data_base64"
+)
+
+// Headers returns the event attributes as protocol headers, using the binding prefix.
+// The data content type is not included, since the protocols carry it in their own content type.
+func (e *Event) Headers(binding Binding) map[string]string {
+	headers := map[string]string{}
+
+	for name, value := range e.attributes() {
+		headers[binding.Prefix+name] = value
+	}
+
+	return headers
+}
+
+// Structured encodes the event and its data as a JSON object, as defined by the structured content mode.
+// JSON data is embedded as it is, any other data is encoded as data_base64.
+func (e *Event) Structured(data []byte) ([]byte, error) {
+	obj := map[string]any{}
+	for name, value := range e.attributes() {
+		obj[name] = value
+	}
+
+	if e.DataContentType != "" {
+		obj[attrDataContentType] = e.DataContentType
+	}
+
+	if len(data) > 0 {
+		if isJSON(e.DataContentType) && json.Valid(data) {
+			obj[attrData] = json.RawMessage(data)
+		} else {
+			obj[attrDataBase64] = base64.StdEncoding.EncodeToString(data)
+		}
+	}
+
+	return json.Marshal(obj)
+}
+
+// attributes returns the event attributes, except the data content type, as strings.
+func (e *Event) attributes() map[string]string {
+	attrs := map[string]string{
+		attrID:          e.ID,
+		attrSource:      e.Source,
+		attrSpecVersion: SpecVersion,
+		attrType:        e.Type,
+	}
+
+	if e.Subject != "" {
+		attrs[attrSubject] = e.Subject
+	}
+
+	if !e.Time.IsZero() {
+		attrs[attrTime] = e.Time.UTC().Format(time.RFC3339Nano)
+	}
+
+	if e.DataSchema != "" {
+		attrs[attrDataSchema] = e.DataSchema
+	}
+
+	for name, value := range e.Extensions {
+		attrs[name] = value
+	}
+
+	return attrs
+}
+
+// FromHeaders parses an event received in binary mode.
+//
+// Parameters:
+//   - binding: The protocol binding, defining the headers prefix.
+//   - headers: The protocol headers.
+//   - contentType: The protocol content type, holding the data content type.
+//
+// Returns:
+//   - The event, or nil when the headers do not carry the CloudEvents spec version.
+//   - InvalidEventError if a required attribute is missing or the time is malformed.
+func FromHeaders(binding Binding, headers map[string]string, contentType string) (*Event, error) {
+	if binding.Prefix == "" || headers[binding.Prefix+attrSpecVersion] == "" {
+		return nil, nil
+	}
+
+	attrs := map[string]string{}
+	for k, v := range headers {
+		if name, ok := strings.CutPrefix(k, binding.Prefix); ok {
+			attrs[name] = v
+		}
+	}
+
+	attrs[attrDataContentType] = contentType
+
+	return fromAttributes(attrs)
+}
+
+// FromStructured parses an event received in structured mode.
+//
+// Parameters:
+//   - body: The JSON event.
+//
+// Returns:
+//   - The event, or nil when the body is not a JSON object holding the CloudEvents spec version.
+//   - The event data.
+//   - InvalidEventError if a required attribute is missing or the data is malformed.
+func FromStructured(body []byte) (*Event, []byte, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, body, nil
+	}
+
+	obj := map[string]json.RawMessage{}
+	if err := json.Unmarshal(trimmed, &obj); err != nil {
+		return nil, body, nil
+	}
+
+	if _, ok := obj[attrSpecVersion]; !ok {
+		return nil, body, nil
+	}
+
+	attrs := map[string]string{}
+	for name, raw := range obj {
+		if name == attrData || name == attrDataBase64 {
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			// extensions may hold numbers or booleans
+			value = string(raw)
+		}
+		attrs[name] = value
+	}
+
+	event, err := fromAttributes(attrs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var data []byte
+	if raw, ok := obj[attrDataBase64]; ok {
+		var encoded string
+		if err := json.Unmarshal(raw, &encoded); err != nil {
+			return nil, nil, InvalidEventError
+		}
+
+		if data, err = base64.StdEncoding.DecodeString(encoded); err != nil {
+			return nil, nil, InvalidEventError
+		}
+	} else if raw, ok := obj[attrData]; ok {
+		data = raw
+
+		// string data of non JSON events holds the data itself
+		var s string
+		if !isJSON(event.DataContentType) && json.Unmarshal(raw, &s) == nil {
+			data = []byte(s)
+		}
+	}
+
+	return event, data, nil
+}
+
+// Parse parses an event received in binary or structured mode, as sent by the Interceptor.
+//
+// Returns:
+//   - The event, or nil when the message is not a CloudEvent.
+//   - The event data, or the body when the message is not a CloudEvent.
+//   - InvalidEventError if the event is malformed.
+func Parse(binding Binding, headers map[string]string, contentType string, body []byte) (*Event, []byte, error) {
+	if strings.HasPrefix(contentType, StructuredContentType) || !binding.Binary {
+		return FromStructured(body)
+	}
+
+	event, err := FromHeaders(binding, headers, contentType)
+	return event, body, err
+}
+
+// fromAttributes builds the event from its attributes, validating the required ones.
+func fromAttributes(attrs map[string]string) (*Event, error) {
+	event := &Event{Extensions: map[string]string{}}
+
+	for name, value := range attrs {
+		switch name {
+		case attrID:
+			event.ID = value
+		case attrSource:
+			event.Source = value
+		case attrSpecVersion:
+			event.SpecVersion = value
+		case attrType:
+			event.Type = value
+		case attrSubject:
+			event.Subject = value
+		case attrDataContentType:
+			event.DataContentType = value
+		case attrDataSchema:
+			event.DataSchema = value
+		case attrTime:
+			t, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil {
+				return nil, InvalidEventError
+			}
+			event.Time = t
+		default:
+			event.Extensions[name] = value
+		}
+	}
+
+	if event.ID == "" || event.Source == "" || event.Type == "" || event.SpecVersion == "" {
+		return nil, InvalidEventError
+	}
+
+	return event, nil
+}
+
+// isJSON reports whether the content type holds JSON data. Empty content types are considered JSON.
+func isJSON(contentType string) bool {
+	return contentType == "" || strings.Contains(contentType, "json")
+}
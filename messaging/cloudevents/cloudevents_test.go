@@ -0,0 +1,167 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package cloudevents
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/stretchr/testify/suite"
+)
+
+type OrderCreated struct {
+	ID string `json:"id"`
+}
+
+type CloudEventsTestSuite struct {
+	suite.Suite
+
+	now  time.Time
+	sent *messaging.OutgoingMessage
+}
+
+func TestCloudEventsTestSuite(t *testing.T) {
+	suite.Run(t, new(CloudEventsTestSuite))
+}
+
+func (s *CloudEventsTestSuite) SetupTest() {
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.sent = nil
+}
+
+func (s *CloudEventsTestSuite) publish(binding Binding, envelope *messaging.Envelope, opts ...Option) {
+	interceptor := Interceptor(binding, append([]Option{func(c *config) { c.now = func() time.Time { return s.now } }}, opts...)...)
+
+	publish := interceptor(func(_ context.Context, out *messaging.OutgoingMessage) error {
+		s.sent = out
+		return nil
+	})
+
+	err := publish(context.Background(), &messaging.OutgoingMessage{
+		Envelope: envelope,
+		Message:  OrderCreated{ID: "1"},
+		Body:     []byte(`{"id":"1"}`),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *CloudEventsTestSuite) TestBinaryMode() {
+	envelope := messaging.NewEnvelope("orders",
+		messaging.WithMessageID("msg-1"),
+		messaging.WithContentType("application/json"),
+		WithType("com.acme.order.created"),
+		WithSubject("order-1"),
+	)
+
+	s.publish(AMQP, envelope, WithSource("/orders"))
+
+	headers := s.sent.Envelope.Headers
+	s.Equal("msg-1", headers["cloudEvents:id"])
+	s.Equal("/orders", headers["cloudEvents:source"])
+	s.Equal("1.0", headers["cloudEvents:specversion"])
+	s.Equal("com.acme.order.created", headers["cloudEvents:type"])
+	s.Equal("order-1", headers["cloudEvents:subject"])
+	s.Equal("2024-01-01T12:00:00Z", headers["cloudEvents:time"])
+	s.Equal(`{"id":"1"}`, string(s.sent.Body))
+
+	event, data, err := Parse(AMQP, headers, s.sent.Envelope.ContentType, s.sent.Body)
+	s.Require().NoError(err)
+	s.Equal("com.acme.order.created", event.Type)
+	s.Equal("application/json", event.DataContentType)
+	s.Equal(s.now, event.Time)
+	s.Equal(`{"id":"1"}`, string(data))
+}
+
+func (s *CloudEventsTestSuite) TestStructuredMode() {
+	envelope := messaging.NewEnvelope("orders", messaging.WithFrom("/orders"), messaging.WithContentType("application/json"))
+
+	s.publish(Kafka, envelope, WithMode(Structured))
+
+	s.Equal(StructuredContentType, s.sent.Envelope.ContentType)
+	s.NotEmpty(s.sent.Envelope.MessageID)
+	s.Empty(s.sent.Envelope.Headers["ce_type"])
+
+	event, data, err := Parse(Kafka, s.sent.Envelope.Headers, s.sent.Envelope.ContentType, s.sent.Body)
+	s.Require().NoError(err)
+	s.Equal("cloudevents.OrderCreated", event.Type)
+	s.Equal("/orders", event.Source)
+	s.Equal(s.sent.Envelope.MessageID, event.ID)
+	s.JSONEq(`{"id":"1"}`, string(data))
+}
+
+func (s *CloudEventsTestSuite) TestMQTTBindingIsAlwaysStructured() {
+	s.publish(MQTT, messaging.NewEnvelope("orders", messaging.WithContentType("application/octet-stream")), WithSource("/devices"))
+
+	event, data, err := FromStructured(s.sent.Body)
+	s.Require().NoError(err)
+	s.Equal("/devices", event.Source)
+	s.Equal(`{"id":"1"}`, string(data))
+}
+
+func (s *CloudEventsTestSuite) TestDefaultSource() {
+	for _, binding := range []Binding{AMQP, Kafka, MQTT} {
+		s.publish(binding, messaging.NewEnvelope("orders", messaging.WithFrom("/orders"), messaging.WithContentType("application/json")))
+
+		event, data, err := Parse(binding, s.sent.Envelope.Headers, s.sent.Envelope.ContentType, s.sent.Body)
+		s.Require().NoError(err)
+		s.Equal("/orders", event.Source)
+		s.JSONEq(`{"id":"1"}`, string(data))
+	}
+}
+
+func (s *CloudEventsTestSuite) TestMissingSource() {
+	publish := Interceptor(AMQP)(func(context.Context, *messaging.OutgoingMessage) error {
+		s.Fail("published without source")
+		return nil
+	})
+
+	err := publish(context.Background(), &messaging.OutgoingMessage{
+		Envelope: messaging.NewEnvelope("orders"),
+		Message:  OrderCreated{ID: "1"},
+		Body:     []byte(`{"id":"1"}`),
+	})
+
+	s.ErrorIs(err, MissingSourceError)
+}
+
+func (s *CloudEventsTestSuite) TestParseMessagesThatAreNotEvents() {
+	event, data, err := Parse(AMQP, map[string]string{}, "application/json", []byte(`{"id":"1"}`))
+	s.NoError(err)
+	s.Nil(event)
+	s.Equal(`{"id":"1"}`, string(data))
+
+	event, data, err = FromStructured([]byte("raw"))
+	s.NoError(err)
+	s.Nil(event)
+	s.Equal("raw", string(data))
+}
+
+func (s *CloudEventsTestSuite) TestParseInvalidEvents() {
+	_, err := FromHeaders(Kafka, map[string]string{"ce_specversion": "1.0", "ce_id": "1"}, "")
+	s.ErrorIs(err, InvalidEventError)
+
+	_, _, err = FromStructured([]byte(`{"specversion":"1.0","id":"1","source":"/","type":"t","time":"yesterday"}`))
+	s.ErrorIs(err, InvalidEventError)
+}
+
+func (s *CloudEventsTestSuite) TestExtensions() {
+	event, _, err := FromStructured([]byte(`{"specversion":"1.0","id":"1","source":"/","type":"t","tenant":"acme","partition":3}`))
+
+	s.Require().NoError(err)
+	s.Equal("acme", event.Extensions["tenant"])
+	s.Equal("3", event.Extensions["partition"])
+	s.Equal("acme", event.Headers(Kafka)["ce_tenant"])
+}
+
+func (s *CloudEventsTestSuite) TestContext() {
+	_, ok := FromContext(context.Background())
+	s.False(ok)
+
+	event, ok := FromContext(NewContext(context.Background(), &Event{Type: "t"}))
+	s.True(ok)
+	s.Equal("t", event.Type)
+}
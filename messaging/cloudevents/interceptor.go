@@ -0,0 +1,148 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package cloudevents
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+type (
+	// Option configures the Interceptor.
+	Option func(c *config)
+
+	config struct {
+		mode     Mode
+		source   string
+		typeName func(msg any) string
+		now      func() time.Time
+	}
+
+	// typeKey and subjectKey are the envelope extension keys holding the event type and subject.
+	typeKey    struct{}
+	subjectKey struct{}
+)
+
+// WithMode sets the content mode of the published events. Defaults to Binary.
+func WithMode(mode Mode) Option {
+	return func(c *config) { c.mode = mode }
+}
+
+// WithSource sets the source attribute of the published events.
+// Defaults to the envelope From; messages published without any source fail with MissingSourceError.
+func WithSource(source string) Option {
+	return func(c *config) { c.source = source }
+}
+
+// WithTypeName sets the function naming the event type of the messages published without WithType.
+// Defaults to messaging.TypeName, the name registered for the message type or its Go type name.
+func WithTypeName(typeName func(msg any) string) Option {
+	return func(c *config) { c.typeName = typeName }
+}
+
+// WithType sets the event type of the published message.
+func WithType(eventType string) messaging.EnvelopeOption {
+	return func(e *messaging.Envelope) { e.SetExtension(typeKey{}, eventType) }
+}
+
+// WithSubject sets the event subject of the published message.
+func WithSubject(subject string) messaging.EnvelopeOption {
+	return func(e *messaging.Envelope) { e.SetExtension(subjectKey{}, subject) }
+}
+
+// Interceptor publishes the messages as CloudEvents, using the protocol binding of the publisher.
+//
+// The event ID is the envelope message ID, generated when empty, and the event data content type
+// is the content type set by the publisher codec. In binary mode the attributes are added to the
+// envelope headers; in structured mode the body is replaced by the JSON event. The source is
+// required by the specification, so the messages without WithSource nor envelope From are not
+// published and MissingSourceError is returned.
+//
+// Parameters:
+//   - binding: The protocol binding of the publisher: AMQP, Kafka or MQTT.
+//   - opts: The content mode, source and type naming options.
+//
+// Returns:
+//   - The interceptor to be registered with Publisher.Intercept.
+func Interceptor(binding Binding, opts ...Option) messaging.PublishInterceptor {
+	cfg := &config{
+		mode:     Binary,
+		typeName: messaging.TypeName,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next messaging.PublishFunc) messaging.PublishFunc {
+		return func(ctx context.Context, out *messaging.OutgoingMessage) error {
+			envelope := out.Envelope
+
+			if envelope.MessageID == "" {
+				envelope.MessageID = newID()
+			}
+
+			event := &Event{
+				ID:              envelope.MessageID,
+				Source:          cfg.source,
+				SpecVersion:     SpecVersion,
+				Type:            cfg.typeName(out.Message),
+				Time:            cfg.now(),
+				DataContentType: envelope.ContentType,
+			}
+
+			if event.Source == "" {
+				event.Source = envelope.From
+			}
+
+			if event.Source == "" {
+				return MissingSourceError
+			}
+
+			if t, ok := envelope.Extension(typeKey{}); ok {
+				event.Type = t.(string)
+			}
+
+			if s, ok := envelope.Extension(subjectKey{}); ok {
+				event.Subject = s.(string)
+			}
+
+			if cfg.mode == Structured || !binding.Binary {
+				body, err := event.Structured(out.Body)
+				if err != nil {
+					return err
+				}
+
+				out.Body = body
+				envelope.ContentType = StructuredContentType
+
+				return next(ctx, out)
+			}
+
+			if envelope.Headers == nil {
+				envelope.Headers = map[string]string{}
+			}
+
+			for k, v := range event.Headers(binding) {
+				envelope.Headers[k] = v
+			}
+
+			return next(ctx, out)
+		}
+	}
+}
+
+// newID generates a random event ID.
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package cloudevents
+
+import "context"
+
+type (
+	// TypedMessage binds a CloudEvents type to the Go type its data is decoded into.
+	// Dispatchers route the events to the handler registered with the TypedMessage of their type.
+	TypedMessage struct {
+		// Type is the CloudEvents type.
+		Type string
+		// Message is a value of the Go type the event data is decoded into.
+		Message any
+	}
+
+	contextKey struct{}
+)
+
+// OfType creates the message type used to register a handler for the events of the given type.
+//
+// Example:
+//
+//	err := dispatcher.Register("orders", cloudevents.OfType("com.acme.order.created", OrderCreated{}), handler)
+func OfType(eventType string, msg any) *TypedMessage {
+	return &TypedMessage{Type: eventType, Message: msg}
+}
+
+// NewContext returns a copy of the context holding the received event.
+func NewContext(ctx context.Context, event *Event) context.Context {
+	return context.WithValue(ctx, contextKey{}, event)
+}
+
+// FromContext returns the event held by the context, set by the dispatchers for the received CloudEvents.
+func FromContext(ctx context.Context) (*Event, bool) {
+	event, ok := ctx.Value(contextKey{}).(*Event)
+	return event, ok
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Results recorded in the "result" attribute of the consumer duration histogram.
+const (
+	// ConsumerResultSuccess identifies the messages processed successfully.
+	ConsumerResultSuccess = "success"
+	// ConsumerResultFailure identifies the messages whose handler returned an error.
+	ConsumerResultFailure = "failure"
+)
+
+type (
+	// ConsumerLabels identifies where a message was consumed from. Empty fields are not
+	// recorded as attributes, so each dispatcher fills only the ones that apply to its broker.
+	ConsumerLabels struct {
+		// System is the messaging system, e.g. rabbitmq, kafka or mqtt.
+		System string
+		// Queue is the queue the message was consumed from.
+		Queue string
+		// Topic is the topic the message was consumed from.
+		Topic string
+		// Consumer is the consumer tag, group or subscription that received the message.
+		Consumer string
+		// Type is the message type.
+		Type string
+	}
+
+	// ConsumerMetrics holds the OpenTelemetry instruments shared by the dispatchers to report
+	// the consumption of messages. All the methods are safe to be called on a nil *ConsumerMetrics,
+	// in which case nothing is recorded.
+	ConsumerMetrics struct {
+		// received counts the messages delivered to the dispatcher.
+		received metric.Int64Counter
+		// succeeded counts the messages processed successfully.
+		succeeded metric.Int64Counter
+		// failed counts the messages whose handler returned an error.
+		failed metric.Int64Counter
+		// retried counts the messages sent back to the broker to be processed again.
+		retried metric.Int64Counter
+		// deadLettered counts the messages sent to a dead letter queue or discarded.
+		deadLettered metric.Int64Counter
+		// duration measures the time spent by the handlers.
+		duration metric.Float64Histogram
+		// inFlight tracks the number of messages being processed.
+		inFlight metric.Int64UpDownCounter
+		// lag tracks the number of messages waiting to be consumed.
+		lag metric.Int64Gauge
+	}
+)
+
+// NewConsumerMetrics creates the consumer instruments using the global meter provider.
+//
+// Returns:
+//   - The ConsumerMetrics used by the dispatchers.
+//   - An error if the meter instruments cannot be created.
+func NewConsumerMetrics() (*ConsumerMetrics, error) {
+	meter := otel.Meter("github.com/ralvescosta/gokit/messaging")
+
+	m := &ConsumerMetrics{}
+	var err error
+
+	counters := []struct {
+		instrument  *metric.Int64Counter
+		name        string
+		description string
+	}{
+		{&m.received, "messaging.consumer.received", "Number of messages received by the consumer"},
+		{&m.succeeded, "messaging.consumer.succeeded", "Number of messages processed successfully"},
+		{&m.failed, "messaging.consumer.failed", "Number of messages whose processing failed"},
+		{&m.retried, "messaging.consumer.retried", "Number of messages sent back to be processed again"},
+		{&m.deadLettered, "messaging.consumer.dead_lettered", "Number of messages sent to the dead letter queue or discarded"},
+	}
+
+	for _, c := range counters {
+		if *c.instrument, err = meter.Int64Counter(c.name, metric.WithDescription(c.description)); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.duration, err = meter.Float64Histogram(
+		"messaging.consumer.duration",
+		metric.WithDescription("Time spent processing messages"),
+		metric.WithUnit("ms"),
+	); err != nil {
+		return nil, err
+	}
+
+	if m.inFlight, err = meter.Int64UpDownCounter(
+		"messaging.consumer.in_flight",
+		metric.WithDescription("Number of messages being processed"),
+	); err != nil {
+		return nil, err
+	}
+
+	if m.lag, err = meter.Int64Gauge(
+		"messaging.consumer.lag",
+		metric.WithDescription("Number of messages waiting to be consumed"),
+	); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// Received records a message delivered to the dispatcher.
+func (m *ConsumerMetrics) Received(ctx context.Context, labels ConsumerLabels) {
+	if m == nil {
+		return
+	}
+
+	m.received.Add(ctx, 1, labels.options())
+}
+
+// Start records a message entering its handler and returns the function that must be called
+// with the handler result once it finishes, recording the duration and the outcome.
+//
+// Example:
+//
+//	done := metrics.Start(ctx, labels)
+//	err := handler(ctx, msg, metadata)
+//	done(err)
+func (m *ConsumerMetrics) Start(ctx context.Context, labels ConsumerLabels) func(err error) {
+	if m == nil {
+		return func(error) {}
+	}
+
+	start := time.Now()
+	attrs := labels.options()
+	m.inFlight.Add(ctx, 1, attrs)
+
+	return func(err error) {
+		m.inFlight.Add(ctx, -1, attrs)
+
+		result := ConsumerResultSuccess
+		if err != nil {
+			result = ConsumerResultFailure
+			m.failed.Add(ctx, 1, attrs)
+		} else {
+			m.succeeded.Add(ctx, 1, attrs)
+		}
+
+		m.duration.Record(
+			ctx,
+			float64(time.Since(start).Milliseconds()),
+			metric.WithAttributes(append(labels.attributes(), attribute.String("result", result))...),
+		)
+	}
+}
+
+// Retried records a message sent back to the broker to be processed again.
+func (m *ConsumerMetrics) Retried(ctx context.Context, labels ConsumerLabels) {
+	if m == nil {
+		return
+	}
+
+	m.retried.Add(ctx, 1, labels.options())
+}
+
+// DeadLettered records a message sent to a dead letter queue or discarded.
+func (m *ConsumerMetrics) DeadLettered(ctx context.Context, labels ConsumerLabels) {
+	if m == nil {
+		return
+	}
+
+	m.deadLettered.Add(ctx, 1, labels.options())
+}
+
+// Lag records the number of messages waiting to be consumed, as reported by the broker.
+func (m *ConsumerMetrics) Lag(ctx context.Context, labels ConsumerLabels, lag int64) {
+	if m == nil {
+		return
+	}
+
+	m.lag.Record(ctx, lag, labels.options())
+}
+
+// Middleware returns a Middleware recording the consumption of every message, labeled with
+// the message type. It is meant for dispatchers that do not record the consumer metrics themselves.
+func (m *ConsumerMetrics) Middleware(labels ConsumerLabels) Middleware {
+	return func(next ConsumerHandler) ConsumerHandler {
+		return func(ctx context.Context, msg any, metadata *Metadata) error {
+			l := labels
+			if l.Type == "" {
+				l.Type = TypeName(msg)
+			}
+
+			m.Received(ctx, l)
+			done := m.Start(ctx, l)
+
+			err := next(ctx, msg, metadata)
+			done(err)
+
+			return err
+		}
+	}
+}
+
+// attributes converts the non-empty labels into metric attributes.
+func (l ConsumerLabels) attributes() []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, 5)
+
+	for _, kv := range []struct{ key, value string }{
+		{"system", l.System},
+		{"queue", l.Queue},
+		{"topic", l.Topic},
+		{"consumer", l.Consumer},
+		{"type", l.Type},
+	} {
+		if kv.value != "" {
+			attrs = append(attrs, attribute.String(kv.key, kv.value))
+		}
+	}
+
+	return attrs
+}
+
+func (l ConsumerLabels) options() metric.MeasurementOption {
+	return metric.WithAttributes(l.attributes()...)
+}
@@ -12,11 +12,11 @@ import "context"
 // Parameters:
 // - ctx: The context for managing deadlines, cancellations, and other request-scoped values.
 // - msg: The message payload to be processed.
-// - metadata: Additional metadata associated with the message.
+// - metadata: The delivery metadata of the message, also used to acknowledge it manually.
 //
 // Returns:
 // - An error if the message processing fails.
-type ConsumerHandler = func(ctx context.Context, msg any, metadata any) error
+type ConsumerHandler = func(ctx context.Context, msg any, metadata *Metadata) error
 
 // Dispatcher defines an interface for registering message handlers and consuming
 // messages in a blocking manner. It abstracts the logic for dispatching messages
@@ -28,10 +28,15 @@ type Dispatcher interface {
 	// - from: The source or topic from which the message originates.
 	// - msgType: The type of the message to be handled.
 	// - handler: The ConsumerHandler function to process the message.
+	// - middlewares: Middlewares applied only to this handler, after the global ones (optional).
 	//
 	// Returns:
 	// - An error if the registration fails.
-	Register(from string, msgType any, handler ConsumerHandler) error
+	Register(from string, msgType any, handler ConsumerHandler, middlewares ...Middleware) error
+
+	// Use registers middlewares applied to every handler of the dispatcher.
+	// Global middlewares wrap the handler-specific ones and must be registered before ConsumeBlocking.
+	Use(middlewares ...Middleware)
 
 	// ConsumeBlocking starts consuming messages and dispatches them to the
 	// appropriate registered handlers. This method blocks the execution and
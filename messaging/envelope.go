@@ -0,0 +1,213 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// Envelope carries the destination and the delivery properties of a published message.
+	// Each broker maps the fields it supports to its own protocol; the broker-specific
+	// properties (e.g. MQTT QoS) are set through the typed options exposed by each package.
+	Envelope struct {
+		// To is the destination of the message: the exchange, topic or queue.
+		To string
+		// From is the source or origin of the message.
+		From string
+		// Key is the routing or partition key of the message.
+		Key string
+		// Headers holds custom headers sent with the message.
+		Headers map[string]string
+		// MessageID is the unique identifier of the message. Publishers generate one when empty.
+		MessageID string
+		// CorrelationID relates the message to a request or to a chain of messages.
+		CorrelationID string
+		// ContentType is the MIME type of the message body. Publishers use their default codec when empty.
+		ContentType string
+		// Priority is the message priority, for brokers that support priority queues.
+		Priority uint8
+		// Expiration is the time after which the broker discards the message, if not consumed.
+		Expiration time.Duration
+		// Delay is the time the broker waits before delivering the message.
+		// Publishers that cannot delay messages return DelayNotSupportedError, in which case
+		// the sql/scheduler package can be used to schedule the message.
+		Delay time.Duration
+
+		extensions map[any]any
+	}
+
+	// EnvelopeOption configures an Envelope.
+	EnvelopeOption func(e *Envelope)
+
+	// legacyOptionsKey is the extension key holding the options of the Publisher.Publish method.
+	legacyOptionsKey struct{}
+)
+
+// NewEnvelope creates an Envelope to the given destination and applies the options.
+func NewEnvelope(to string, opts ...EnvelopeOption) *Envelope {
+	e := &Envelope{To: to, Headers: map[string]string{}}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	return e
+}
+
+// EnvelopeFromOptions adapts the arguments of the Publisher.Publish method into an Envelope.
+// The options are not sent as headers: they are kept as an extension, read with LegacyOption,
+// so each publisher keeps giving them the meaning they had before the envelopes.
+func EnvelopeFromOptions(to, from, key *string, options ...*Option) *Envelope {
+	e := NewEnvelope(valueOf(to))
+	e.From = valueOf(from)
+	e.Key = valueOf(key)
+
+	if len(options) > 0 {
+		e.SetExtension(legacyOptionsKey{}, options)
+	}
+
+	return e
+}
+
+// LegacyOption returns the value of the Publisher.Publish option with the given key,
+// when the envelope was built by EnvelopeFromOptions.
+func (e *Envelope) LegacyOption(key string) (string, bool) {
+	options, _ := e.extensions[legacyOptionsKey{}].([]*Option)
+
+	for _, opt := range options {
+		if opt != nil && opt.Key == key {
+			return opt.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Clone returns a copy of the envelope, with its own headers and extensions.
+func (e *Envelope) Clone() *Envelope {
+	clone := *e
+
+	clone.Headers = make(map[string]string, len(e.Headers))
+	for k, v := range e.Headers {
+		clone.Headers[k] = v
+	}
+
+	clone.extensions = make(map[any]any, len(e.extensions))
+	for k, v := range e.extensions {
+		clone.extensions[k] = v
+	}
+
+	return &clone
+}
+
+// SetExtension stores a broker-specific property in the envelope.
+// Packages should use unexported key types to avoid collisions, as with context.WithValue.
+func (e *Envelope) SetExtension(key, value any) {
+	if e.extensions == nil {
+		e.extensions = map[any]any{}
+	}
+
+	e.extensions[key] = value
+}
+
+// Extension returns the broker-specific property stored with the given key.
+func (e *Envelope) Extension(key any) (any, bool) {
+	v, ok := e.extensions[key]
+	return v, ok
+}
+
+// WithFrom sets the source of the message.
+func WithFrom(from string) EnvelopeOption {
+	return func(e *Envelope) { e.From = from }
+}
+
+// WithKey sets the routing or partition key of the message.
+func WithKey(key string) EnvelopeOption {
+	return func(e *Envelope) { e.Key = key }
+}
+
+// WithHeader adds a custom header to the message.
+func WithHeader(key, value string) EnvelopeOption {
+	return func(e *Envelope) {
+		if e.Headers == nil {
+			e.Headers = map[string]string{}
+		}
+		e.Headers[key] = value
+	}
+}
+
+// WithMessageID sets the unique identifier of the message.
+func WithMessageID(id string) EnvelopeOption {
+	return func(e *Envelope) { e.MessageID = id }
+}
+
+// WithCorrelationID sets the correlation identifier of the message.
+func WithCorrelationID(id string) EnvelopeOption {
+	return func(e *Envelope) { e.CorrelationID = id }
+}
+
+// WithContentType sets the MIME type of the message body.
+func WithContentType(contentType string) EnvelopeOption {
+	return func(e *Envelope) { e.ContentType = contentType }
+}
+
+// WithPriority sets the message priority.
+func WithPriority(priority uint8) EnvelopeOption {
+	return func(e *Envelope) { e.Priority = priority }
+}
+
+// WithExpiration sets the time after which the broker discards the message.
+func WithExpiration(expiration time.Duration) EnvelopeOption {
+	return func(e *Envelope) { e.Expiration = expiration }
+}
+
+// WithDelay sets the time the broker waits before delivering the message.
+func WithDelay(delay time.Duration) EnvelopeOption {
+	return func(e *Envelope) { e.Delay = delay }
+}
+
+// WithDeliverAt sets the delay so the message is delivered at the given time.
+// Times in the past deliver the message immediately.
+func WithDeliverAt(at time.Time) EnvelopeOption {
+	return func(e *Envelope) {
+		e.Delay = time.Until(at)
+		if e.Delay < 0 {
+			e.Delay = 0
+		}
+	}
+}
+
+// Publish sends a typed message to the destination using the publisher.
+func Publish[T any](ctx context.Context, publisher Publisher, to string, msg T, opts ...EnvelopeOption) error {
+	return publisher.PublishEnvelope(ctx, NewEnvelope(to, opts...), msg)
+}
+
+// TypedPublisher publishes messages of a single type to a fixed destination.
+type TypedPublisher[T any] struct {
+	publisher Publisher
+	to        string
+	opts      []EnvelopeOption
+}
+
+// NewTypedPublisher creates a TypedPublisher that sends messages to the destination using the publisher.
+// The options are applied to every message, before the options provided to Publish.
+func NewTypedPublisher[T any](publisher Publisher, to string, opts ...EnvelopeOption) *TypedPublisher[T] {
+	return &TypedPublisher[T]{publisher: publisher, to: to, opts: opts}
+}
+
+// Publish sends the message to the destination of the TypedPublisher.
+func (p *TypedPublisher[T]) Publish(ctx context.Context, msg T, opts ...EnvelopeOption) error {
+	return Publish(ctx, p.publisher, p.to, msg, append(append([]EnvelopeOption{}, p.opts...), opts...)...)
+}
+
+func valueOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvelopeTestSuite struct {
+	suite.Suite
+}
+
+func TestEnvelopeTestSuite(t *testing.T) {
+	suite.Run(t, new(EnvelopeTestSuite))
+}
+
+func (s *EnvelopeTestSuite) TestEnvelopeFromOptions() {
+	to, from, key := "orders", "billing", "order-1"
+
+	e := EnvelopeFromOptions(&to, &from, &key, &Option{Key: "qos", Value: "1"}, nil)
+
+	s.Equal("orders", e.To)
+	s.Equal("billing", e.From)
+	s.Equal("order-1", e.Key)
+	s.Empty(e.Headers)
+
+	value, ok := e.LegacyOption("qos")
+	s.True(ok)
+	s.Equal("1", value)
+
+	_, ok = e.LegacyOption("retain")
+	s.False(ok)
+}
+
+func (s *EnvelopeTestSuite) TestLegacyOptionWithoutOptions() {
+	_, ok := NewEnvelope("orders").LegacyOption("qos")
+	s.False(ok)
+}
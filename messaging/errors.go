@@ -3,3 +3,28 @@
 // All rights reserved.
 
 package messaging
+
+// MessagingError represents a custom error type for the messaging abstractions.
+// It encapsulates an error message describing the specific error condition.
+type MessagingError struct {
+	msg string
+}
+
+// Error implements the error interface and returns the error message.
+func (e *MessagingError) Error() string {
+	return e.msg
+}
+
+// NewMessagingError creates a new MessagingError instance with the provided message.
+func NewMessagingError(msg string) error {
+	return &MessagingError{msg}
+}
+
+var (
+	// RetryableError indicates that a message processing failed but can be retried later.
+	// Handlers return it to ask the dispatcher to redeliver the message instead of dead-lettering it.
+	RetryableError = NewMessagingError("error to process this message, retry latter")
+
+	// DelayNotSupportedError is returned by publishers that cannot delay the delivery of a message.
+	DelayNotSupportedError = NewMessagingError("delayed delivery is not supported by this publisher")
+)
@@ -0,0 +1,525 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package inmemory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+type (
+	// Broker is an in-memory message broker implementing messaging.Publisher and messaging.Dispatcher.
+	//
+	// Messages published to a topic are delivered to every queue bound to the topic. When the topic
+	// has no bindings, the message is delivered to the queue with the same name as the topic, if declared,
+	// similar to the RabbitMQ default exchange.
+	Broker struct {
+		mu           sync.Mutex
+		queues       map[string]*queue
+		bindings     map[string][]*binding
+		consumers    map[string]map[string]*consumerDefinition
+		middlewares  []messaging.Middleware
+		interceptors []messaging.PublishInterceptor
+		published    map[string][]*Message
+		processed    map[string][]*Message
+		deadLettered map[string][]*Message
+		changed      chan struct{}
+		awaitTimeout time.Duration
+		sequence     uint64
+		consuming    bool
+		closed       bool
+		done         chan struct{}
+		wg           sync.WaitGroup
+	}
+
+	// queue holds the deliveries waiting to be consumed.
+	queue struct {
+		def     *QueueDefinition
+		pending []*delivery
+		signal  chan struct{}
+	}
+
+	// binding routes the messages of a topic to a queue.
+	binding struct {
+		queue string
+		key   string
+	}
+
+	// consumerDefinition holds the message type and handler registered to a queue.
+	consumerDefinition struct {
+		msgType     reflect.Type
+		handler     messaging.ConsumerHandler
+		middlewares []messaging.Middleware
+	}
+)
+
+var (
+	_ messaging.Publisher  = (*Broker)(nil)
+	_ messaging.Dispatcher = (*Broker)(nil)
+)
+
+// NewBroker creates a new empty in-memory broker.
+func NewBroker() *Broker {
+	return &Broker{
+		queues:       map[string]*queue{},
+		bindings:     map[string][]*binding{},
+		consumers:    map[string]map[string]*consumerDefinition{},
+		published:    map[string][]*Message{},
+		processed:    map[string][]*Message{},
+		deadLettered: map[string][]*Message{},
+		changed:      make(chan struct{}),
+		awaitTimeout: DefaultAwaitTimeout,
+		done:         make(chan struct{}),
+	}
+}
+
+// AwaitTimeout sets the time the Await helpers wait before returning AwaitTimeoutError.
+func (b *Broker) AwaitTimeout(timeout time.Duration) *Broker {
+	b.awaitTimeout = timeout
+	return b
+}
+
+// DeclareQueue declares the queues in the broker. If a queue has a DLQ, its DLQ is declared as well,
+// so dead-lettered messages can also be consumed.
+func (b *Broker) DeclareQueue(defs ...*QueueDefinition) *Broker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, def := range defs {
+		b.queues[def.name] = &queue{def: def, signal: make(chan struct{}, 1)}
+
+		if def.withDLQ {
+			if _, ok := b.queues[def.DLQName()]; !ok {
+				b.queues[def.DLQName()] = &queue{def: NewQueue(def.DLQName()), signal: make(chan struct{}, 1)}
+			}
+		}
+	}
+
+	return b
+}
+
+// Bind routes the messages published to the topic to the queue.
+// An empty routing key matches any key, otherwise the message key must be equal to the routing key.
+func (b *Broker) Bind(topic, routingKey, queue string) *Broker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bindings[topic] = append(b.bindings[topic], &binding{queue: queue, key: routingKey})
+
+	return b
+}
+
+// Publish encodes the message as JSON and routes it to the queues bound to the topic.
+// The options are not delivered as headers; they are read from the published envelope with LegacyOption.
+func (b *Broker) Publish(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
+	return b.PublishEnvelope(ctx, messaging.EnvelopeFromOptions(to, from, key, options...), msg)
+}
+
+// PublishDeadline publishes the message if the context deadline was not exceeded.
+func (b *Broker) PublishDeadline(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return b.Publish(ctx, to, from, key, msg, options...)
+}
+
+// PublishEnvelope encodes the message as JSON and routes it to the queues bound to the envelope destination.
+// Messages without a MessageID receive a sequential identifier.
+func (b *Broker) PublishEnvelope(ctx context.Context, envelope *messaging.Envelope, msg any) error {
+	if envelope == nil || envelope.To == "" {
+		return EmptyTopicError
+	}
+
+	if envelope.Delay > 0 {
+		return messaging.DelayNotSupportedError
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	envelope = envelope.Clone()
+	if envelope.ContentType == "" {
+		envelope.ContentType = JSONContentType
+	}
+
+	b.mu.Lock()
+	publish := messaging.ChainPublish(b.send, b.interceptors...)
+	b.mu.Unlock()
+
+	return publish(ctx, &messaging.OutgoingMessage{Envelope: envelope, Message: msg, Body: body})
+}
+
+// Intercept registers interceptors executed for every published message.
+func (b *Broker) Intercept(interceptors ...messaging.PublishInterceptor) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.interceptors = append(b.interceptors, interceptors...)
+}
+
+// send records the outgoing message and routes it to the queues bound to its destination.
+func (b *Broker) send(_ context.Context, out *messaging.OutgoingMessage) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sequence++
+	m := &Message{
+		ID:          out.Envelope.MessageID,
+		Topic:       out.Envelope.To,
+		From:        out.Envelope.From,
+		Key:         out.Envelope.Key,
+		Type:        messaging.TypeName(out.Message),
+		Body:        out.Body,
+		Headers:     out.Envelope.Headers,
+		Envelope:    out.Envelope,
+		PublishedAt: time.Now(),
+	}
+	if m.ID == "" {
+		m.ID = strconv.FormatUint(b.sequence, 10)
+	}
+
+	b.published[m.Topic] = append(b.published[m.Topic], m)
+	b.route(m)
+	b.notify()
+
+	return nil
+}
+
+// Register associates a declared queue with a message type and a handler function.
+// The handler receives a pointer to a new value of the message type and a *messaging.Metadata,
+// whose Raw field holds the *Message.
+// Messages are dispatched to the handler registered for their type; when the queue has a single
+// handler, it receives every message of the queue, as the rabbitmq dispatcher does.
+// The middlewares are applied only to this handler, after the ones registered with Use.
+func (b *Broker) Register(from string, msgType any, handler messaging.ConsumerHandler, middlewares ...messaging.Middleware) error {
+	if msgType == nil || from == "" || handler == nil {
+		return InvalidDispatchParamsError
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.queues[from]; !ok {
+		return QueueDefinitionNotFoundError
+	}
+
+	if _, ok := b.consumers[from]; !ok {
+		b.consumers[from] = map[string]*consumerDefinition{}
+	}
+
+	b.consumers[from][messaging.TypeName(msgType)] = &consumerDefinition{
+		msgType:     reflect.TypeOf(msgType),
+		handler:     handler,
+		middlewares: middlewares,
+	}
+
+	return nil
+}
+
+// Use registers middlewares applied to every handler of the broker.
+func (b *Broker) Use(middlewares ...messaging.Middleware) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.middlewares = append(b.middlewares, middlewares...)
+}
+
+// ConsumeBlocking starts consuming the queues with registered handlers and blocks until Close is called.
+func (b *Broker) ConsumeBlocking() {
+	b.mu.Lock()
+	if b.closed || b.consuming {
+		b.mu.Unlock()
+		return
+	}
+
+	b.consuming = true
+	for name := range b.consumers {
+		q := b.queues[name]
+		b.wg.Add(1)
+		go b.consume(q)
+		q.wakeUp()
+	}
+	b.mu.Unlock()
+
+	<-b.done
+}
+
+// Close stops the consumers and waits until the messages being processed are finished.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+
+	b.closed = true
+	close(b.done)
+	b.mu.Unlock()
+
+	b.wg.Wait()
+}
+
+// Published returns the messages published to the topic.
+func (b *Broker) Published(topic string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]*Message{}, b.published[topic]...)
+}
+
+// Processed returns the messages from the queue successfully processed by the handlers.
+func (b *Broker) Processed(queue string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]*Message{}, b.processed[queue]...)
+}
+
+// DeadLettered returns the messages from the queue that were sent to its DLQ.
+func (b *Broker) DeadLettered(queue string) []*Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]*Message{}, b.deadLettered[queue]...)
+}
+
+// AwaitPublished waits until at least n messages were published to the topic and returns them.
+// Returns AwaitTimeoutError if the messages are not published within the await timeout.
+func (b *Broker) AwaitPublished(topic string, n int) ([]*Message, error) {
+	return b.await(b.published, topic, n)
+}
+
+// AwaitProcessed waits until at least n messages from the queue were successfully processed and returns them.
+// Returns AwaitTimeoutError if the messages are not processed within the await timeout.
+func (b *Broker) AwaitProcessed(queue string, n int) ([]*Message, error) {
+	return b.await(b.processed, queue, n)
+}
+
+// AwaitDeadLettered waits until at least n messages from the queue were sent to its DLQ and returns them.
+// Returns AwaitTimeoutError if the messages are not dead-lettered within the await timeout.
+func (b *Broker) AwaitDeadLettered(queue string, n int) ([]*Message, error) {
+	return b.await(b.deadLettered, queue, n)
+}
+
+// await blocks until the entry of the given registry has at least n messages.
+func (b *Broker) await(registry map[string][]*Message, name string, n int) ([]*Message, error) {
+	timer := time.NewTimer(b.awaitTimeout)
+	defer timer.Stop()
+
+	for {
+		b.mu.Lock()
+		if msgs := registry[name]; len(msgs) >= n {
+			b.mu.Unlock()
+			return append([]*Message{}, msgs...), nil
+		}
+		changed := b.changed
+		b.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			return nil, fmt.Errorf("%w: expected %d messages on %s", AwaitTimeoutError, n, name)
+		}
+	}
+}
+
+// consume processes the queue deliveries until the broker is closed.
+func (b *Broker) consume(q *queue) {
+	defer b.wg.Done()
+
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-q.signal:
+		}
+
+		for d := b.next(q); d != nil; d = b.next(q) {
+			b.process(q, d)
+
+			select {
+			case <-b.done:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// process dispatches a delivery to its handler, applying the retry and dead letter rules
+// of the queue definition.
+func (b *Broker) process(q *queue, d *delivery) {
+	b.mu.Lock()
+	def := b.consumerFor(q.def.name, d.msg.Type)
+	var handler messaging.ConsumerHandler
+	if def != nil {
+		middlewares := append(append([]messaging.Middleware{}, b.middlewares...), def.middlewares...)
+		handler = messaging.Chain(def.handler, middlewares...)
+	}
+	b.mu.Unlock()
+
+	// Messages without a handler are acknowledged and discarded
+	if def == nil {
+		return
+	}
+
+	ptr := reflect.New(def.msgType).Interface()
+	if err := d.msg.Decode(ptr); err != nil {
+		b.deadLetter(q, d)
+		return
+	}
+
+	metadata := (&messaging.Metadata{
+		MessageID:     d.msg.ID,
+		CorrelationID: d.msg.Envelope.CorrelationID,
+		Type:          d.msg.Type,
+		Source:        q.def.name,
+		Timestamp:     d.msg.PublishedAt,
+		Attempt:       int(d.xCount) + 1,
+		Headers:       d.msg.Headers,
+		Raw:           d.msg,
+	}).WithAcknowledger(&acknowledger{broker: b, queue: q, delivery: d})
+
+	err := handler(context.Background(), ptr, metadata)
+
+	// Messages settled by the handler are not acknowledged again. The others are settled through
+	// the metadata as well, so a handler still running in background, e.g. after a timeout,
+	// cannot settle them a second time
+	if metadata.Settled() {
+		return
+	}
+
+	// As in the rabbitmq dispatcher, failed messages of queues with a DLQ are always dead lettered,
+	// and only the RetryableError of queues without a DLQ are retried.
+	if err != nil {
+		if !q.def.withDLQ && errors.Is(err, messaging.RetryableError) && q.def.withRetry && d.xCount < q.def.retries {
+			_ = metadata.Nack(true)
+			return
+		}
+
+		_ = metadata.Nack(false)
+		return
+	}
+
+	_ = metadata.Ack()
+}
+
+// processedMessage records the message as processed by the queue.
+func (b *Broker) processedMessage(q *queue, d *delivery) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.processed[q.def.name] = append(b.processed[q.def.name], d.msg)
+	b.notify()
+}
+
+// redeliver enqueues the message again, counting a new delivery attempt.
+func (b *Broker) redeliver(q *queue, d *delivery) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q.push(&delivery{msg: d.msg, xCount: d.xCount + 1})
+}
+
+// deadLetter moves the message to the queue DLQ. Without a DLQ the message is discarded.
+func (b *Broker) deadLetter(q *queue, d *delivery) {
+	if !q.def.withDLQ {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.deadLettered[q.def.name] = append(b.deadLettered[q.def.name], d.msg)
+	if dlq, ok := b.queues[q.def.DLQName()]; ok {
+		dlq.push(&delivery{msg: d.msg})
+	}
+	b.notify()
+}
+
+// consumerFor returns the handler registered to the queue for the message type,
+// resolving the aliases registered in the messaging.DefaultTypeRegistry.
+// Must be called with the lock held.
+func (b *Broker) consumerFor(queue, msgType string) *consumerDefinition {
+	consumers := b.consumers[queue]
+	if def, ok := consumers[messaging.ResolveTypeName(msgType)]; ok {
+		return def
+	}
+
+	if len(consumers) == 1 {
+		for _, def := range consumers {
+			return def
+		}
+	}
+
+	return nil
+}
+
+// next pops the next delivery of the queue.
+func (b *Broker) next(q *queue) *delivery {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		return nil
+	}
+
+	d := q.pending[0]
+	q.pending = q.pending[1:]
+
+	return d
+}
+
+// route enqueues the message in the queues bound to its topic. Must be called with the lock held.
+func (b *Broker) route(m *Message) {
+	bindings, ok := b.bindings[m.Topic]
+	if !ok {
+		if q, ok := b.queues[m.Topic]; ok {
+			q.push(&delivery{msg: m})
+		}
+		return
+	}
+
+	for _, bind := range bindings {
+		if bind.key != "" && bind.key != m.Key {
+			continue
+		}
+
+		if q, ok := b.queues[bind.queue]; ok {
+			q.push(&delivery{msg: m})
+		}
+	}
+}
+
+// notify wakes up the goroutines waiting on the Await helpers. Must be called with the lock held.
+func (b *Broker) notify() {
+	close(b.changed)
+	b.changed = make(chan struct{})
+}
+
+// push appends a delivery to the queue and wakes up its consumer.
+func (q *queue) push(d *delivery) {
+	q.pending = append(q.pending, d)
+	q.wakeUp()
+}
+
+// wakeUp signals the queue consumer without blocking.
+func (q *queue) wakeUp() {
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,262 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package inmemory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	BrokerTestSuite struct {
+		suite.Suite
+
+		broker *Broker
+	}
+
+	orderCreated struct {
+		ID string `json:"id"`
+	}
+
+	orderShipped struct {
+		ID string `json:"id"`
+	}
+
+	orderCancelled struct {
+		ID string `json:"id"`
+	}
+
+	legacyOrderCancelled struct {
+		ID string `json:"id"`
+	}
+)
+
+func TestBrokerTestSuite(t *testing.T) {
+	suite.Run(t, new(BrokerTestSuite))
+}
+
+func (s *BrokerTestSuite) SetupTest() {
+	s.broker = NewBroker().AwaitTimeout(500 * time.Millisecond)
+}
+
+func (s *BrokerTestSuite) TearDownTest() {
+	s.broker.Close()
+}
+
+func (s *BrokerTestSuite) TestPublishShouldRouteToBoundQueues() {
+	s.broker.
+		DeclareQueue(NewQueue("billing"), NewQueue("shipping"), NewQueue("audit")).
+		Bind("orders", "created", "billing").
+		Bind("orders", "created", "shipping").
+		Bind("orders", "", "audit")
+
+	received := make(chan *orderCreated, 2)
+	handler := func(_ context.Context, msg any, _ *messaging.Metadata) error {
+		received <- msg.(*orderCreated)
+		return nil
+	}
+
+	s.NoError(s.broker.Register("billing", orderCreated{}, handler))
+	s.NoError(s.broker.Register("shipping", orderCreated{}, handler))
+	go s.broker.ConsumeBlocking()
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, strPtr("created"), &orderCreated{ID: "1"}))
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, strPtr("updated"), &orderCreated{ID: "2"}))
+
+	published, err := s.broker.AwaitPublished("orders", 2)
+	s.NoError(err)
+	s.Len(published, 2)
+	s.Equal("*inmemory.orderCreated", published[0].Type)
+
+	_, err = s.broker.AwaitProcessed("billing", 1)
+	s.NoError(err)
+	_, err = s.broker.AwaitProcessed("shipping", 1)
+	s.NoError(err)
+
+	s.Equal("1", (<-received).ID)
+	s.Equal("1", (<-received).ID)
+	s.Empty(s.broker.Processed("audit"))
+}
+
+func (s *BrokerTestSuite) TestPublishWithoutBindingsShouldUseTheQueueWithTheTopicName() {
+	s.broker.DeclareQueue(NewQueue("orders"))
+
+	var metadata *messaging.Metadata
+	s.NoError(s.broker.Register("orders", orderCreated{}, func(_ context.Context, _ any, m *messaging.Metadata) error {
+		metadata = m
+		return nil
+	}))
+	go s.broker.ConsumeBlocking()
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, &orderCreated{ID: "1"}, &messaging.Option{Key: "tenant", Value: "acme"}))
+
+	_, err := s.broker.AwaitProcessed("orders", 1)
+	s.NoError(err)
+	s.Equal("orders", metadata.Source)
+	s.Equal(1, metadata.Attempt)
+	s.NotEmpty(metadata.MessageID)
+	s.False(metadata.Timestamp.IsZero())
+	s.NotContains(metadata.Headers, "tenant")
+
+	tenant, ok := s.broker.Published("orders")[0].Envelope.LegacyOption("tenant")
+	s.True(ok)
+	s.Equal("acme", tenant)
+}
+
+func (s *BrokerTestSuite) TestRegisteredTypesShouldBeRoutedByNameAndAlias() {
+	s.NoError(messaging.RegisterType(orderShipped{}, "orders.shipped.v1"))
+	s.NoError(messaging.RegisterType(orderCancelled{}, "orders.cancelled.v2", "inmemory.legacyOrderCancelled"))
+
+	s.broker.DeclareQueue(NewQueue("orders"))
+
+	shipped := make(chan any, 1)
+	cancelled := make(chan any, 1)
+	s.NoError(s.broker.Register("orders", orderShipped{}, func(_ context.Context, msg any, _ *messaging.Metadata) error {
+		shipped <- msg
+		return nil
+	}))
+	s.NoError(s.broker.Register("orders", orderCancelled{}, func(_ context.Context, msg any, _ *messaging.Metadata) error {
+		cancelled <- msg
+		return nil
+	}))
+	go s.broker.ConsumeBlocking()
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, &orderShipped{ID: "1"}))
+	// messages published with a previous name are routed through the alias
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, legacyOrderCancelled{ID: "2"}))
+
+	published, err := s.broker.AwaitPublished("orders", 2)
+	s.NoError(err)
+	s.Equal("orders.shipped.v1", published[0].Type)
+	s.Equal("inmemory.legacyOrderCancelled", published[1].Type)
+
+	_, err = s.broker.AwaitProcessed("orders", 2)
+	s.NoError(err)
+	s.Equal("1", (<-shipped).(*orderShipped).ID)
+	s.Equal("2", (<-cancelled).(*orderCancelled).ID)
+}
+
+func (s *BrokerTestSuite) TestRetryableErrorShouldBeRetriedWithoutDLQ() {
+	s.broker.DeclareQueue(NewQueue("orders").WithRetry(2))
+
+	attempts := make(chan int, 3)
+	s.NoError(s.broker.Register("orders", orderCreated{}, func(_ context.Context, _ any, m *messaging.Metadata) error {
+		attempts <- m.Attempt
+		return messaging.RetryableError
+	}))
+	go s.broker.ConsumeBlocking()
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, &orderCreated{ID: "1"}))
+
+	s.Equal(1, <-attempts)
+	s.Equal(2, <-attempts)
+	s.Equal(3, <-attempts)
+	s.Empty(s.broker.Processed("orders"))
+}
+
+func (s *BrokerTestSuite) TestRetryableErrorShouldBeDeadLetteredWithDLQ() {
+	s.broker.DeclareQueue(NewQueue("orders").WithRetry(2).WithDLQ())
+
+	attempts := 0
+	s.NoError(s.broker.Register("orders", orderCreated{}, func(_ context.Context, _ any, m *messaging.Metadata) error {
+		attempts++
+		return messaging.RetryableError
+	}))
+	go s.broker.ConsumeBlocking()
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, &orderCreated{ID: "1"}))
+
+	dead, err := s.broker.AwaitDeadLettered("orders", 1)
+	s.NoError(err)
+	s.Len(dead, 1)
+	s.Equal(1, attempts)
+	s.Empty(s.broker.Processed("orders"))
+}
+
+func (s *BrokerTestSuite) TestNonRetryableErrorShouldBeDeadLetteredImmediately() {
+	s.broker.DeclareQueue(NewQueue("orders").WithRetry(2).WithDLQ())
+
+	attempts := 0
+	s.NoError(s.broker.Register("orders", orderCreated{}, func(context.Context, any, *messaging.Metadata) error {
+		attempts++
+		return errors.New("some error")
+	}))
+	s.NoError(s.broker.Register("orders-dlq", orderCreated{}, func(context.Context, any, *messaging.Metadata) error {
+		return nil
+	}))
+	go s.broker.ConsumeBlocking()
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, &orderCreated{ID: "1"}))
+
+	_, err := s.broker.AwaitDeadLettered("orders", 1)
+	s.NoError(err)
+	_, err = s.broker.AwaitProcessed("orders-dlq", 1)
+	s.NoError(err)
+	s.Equal(1, attempts)
+}
+
+func (s *BrokerTestSuite) TestManualAcknowledgment() {
+	s.broker.DeclareQueue(NewQueue("orders").WithRetry(1).WithDLQ())
+
+	attempts := 0
+	s.NoError(s.broker.Register("orders", orderCreated{}, func(_ context.Context, msg any, m *messaging.Metadata) error {
+		attempts++
+
+		switch msg.(*orderCreated).ID {
+		case "deferred":
+			s.NoError(m.Defer())
+		case "rejected":
+			s.NoError(m.Nack(false))
+		default:
+			s.NoError(m.Ack())
+			s.ErrorIs(m.Ack(), messaging.AlreadySettledError)
+		}
+
+		// the result is ignored for messages settled by the handler
+		return errors.New("some error")
+	}))
+	go s.broker.ConsumeBlocking()
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, &orderCreated{ID: "acked"}))
+	_, err := s.broker.AwaitProcessed("orders", 1)
+	s.NoError(err)
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, &orderCreated{ID: "rejected"}))
+	_, err = s.broker.AwaitDeadLettered("orders", 1)
+	s.NoError(err)
+
+	s.NoError(s.broker.Publish(context.Background(), strPtr("orders"), nil, nil, &orderCreated{ID: "deferred"}))
+	_, err = s.broker.AwaitDeadLettered("orders", 2)
+	s.NoError(err)
+
+	s.Equal(4, attempts)
+}
+
+func (s *BrokerTestSuite) TestAwaitPublishedShouldTimeout() {
+	_, err := s.broker.AwaitPublished("orders", 1)
+	s.ErrorIs(err, AwaitTimeoutError)
+}
+
+func (s *BrokerTestSuite) TestRegisterShouldValidateParams() {
+	s.ErrorIs(s.broker.Register("orders", nil, nil), InvalidDispatchParamsError)
+	s.ErrorIs(s.broker.Register("orders", orderCreated{}, func(context.Context, any, *messaging.Metadata) error { return nil }), QueueDefinitionNotFoundError)
+}
+
+func (s *BrokerTestSuite) TestPublishShouldValidateTopic() {
+	s.ErrorIs(s.broker.Publish(context.Background(), nil, nil, nil, orderCreated{}), EmptyTopicError)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.ErrorIs(s.broker.PublishDeadline(ctx, strPtr("orders"), nil, nil, orderCreated{}), context.Canceled)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
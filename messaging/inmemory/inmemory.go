@@ -0,0 +1,37 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package inmemory provides an in-memory broker implementing messaging.Publisher and
+// messaging.Dispatcher. It is intended for unit tests: services can exercise their publish
+// and consume flows, with the same handler signatures used in production, without running
+// RabbitMQ, Kafka or an MQTT broker.
+package inmemory
+
+import (
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+const (
+	// DefaultAwaitTimeout is the time the Await helpers wait before failing.
+	DefaultAwaitTimeout = time.Second
+
+	// JSONContentType is the MIME type of the messages encoded by the broker.
+	JSONContentType = "application/json"
+)
+
+var (
+	// EmptyTopicError is returned when a message is published without a destination topic.
+	EmptyTopicError = messaging.NewMessagingError("topic cannot be empty")
+
+	// InvalidDispatchParamsError is returned when invalid parameters are provided to Register.
+	InvalidDispatchParamsError = messaging.NewMessagingError("register dispatch with invalid parameters")
+
+	// QueueDefinitionNotFoundError is returned when no queue was declared with the given name.
+	QueueDefinitionNotFoundError = messaging.NewMessagingError("any queue definition was founded to the given queue")
+
+	// AwaitTimeoutError is returned by the Await helpers when the expected messages are not observed in time.
+	AwaitTimeoutError = messaging.NewMessagingError("timeout waiting for messages")
+)
@@ -0,0 +1,87 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package inmemory
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+type (
+	// Message is a message published to the in-memory broker.
+	Message struct {
+		// ID is the unique identifier assigned to the message when it was published.
+		ID string
+		// Topic is the destination the message was published to.
+		Topic string
+		// From is the source of the message, if provided.
+		From string
+		// Key is the routing key of the message, if provided.
+		Key string
+		// Type is the type name of the published message, named by messaging.TypeName.
+		Type string
+		// Body is the JSON encoded message.
+		Body []byte
+		// Headers holds the envelope headers.
+		Headers map[string]string
+		// Envelope is the envelope used to publish the message.
+		Envelope *messaging.Envelope
+		// PublishedAt is the time the message was published.
+		PublishedAt time.Time
+	}
+
+	// acknowledger settles a delivery manually, implementing messaging.Acknowledger.
+	acknowledger struct {
+		broker   *Broker
+		queue    *queue
+		delivery *delivery
+	}
+
+	// delivery is a message waiting to be consumed from a queue.
+	delivery struct {
+		msg    *Message
+		xCount int64
+	}
+)
+
+// Decode unmarshals the message body into the provided pointer.
+func (m *Message) Decode(v any) error {
+	return json.Unmarshal(m.Body, v)
+}
+
+// Ack records the message as processed.
+func (a *acknowledger) Ack() error {
+	a.broker.processedMessage(a.queue, a.delivery)
+	return nil
+}
+
+// Nack delivers the message again when requeued, counting a new attempt, or moves it to the DLQ.
+func (a *acknowledger) Nack(requeue bool) error {
+	if requeue {
+		a.broker.redeliver(a.queue, a.delivery)
+		return nil
+	}
+
+	a.broker.deadLetter(a.queue, a.delivery)
+	return nil
+}
+
+// Defer delivers the message again while the queue retries are not exhausted, then moves it to the DLQ.
+// Returns messaging.AcknowledgmentNotSupportedError if the queue was declared without retries.
+func (a *acknowledger) Defer() error {
+	if !a.queue.def.withRetry {
+		return messaging.AcknowledgmentNotSupportedError
+	}
+
+	if a.delivery.xCount >= a.queue.def.retries {
+		a.broker.deadLetter(a.queue, a.delivery)
+		return nil
+	}
+
+	a.broker.redeliver(a.queue, a.delivery)
+	return nil
+}
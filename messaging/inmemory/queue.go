@@ -0,0 +1,48 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package inmemory
+
+import "fmt"
+
+// QueueDefinition represents the configuration of an in-memory queue.
+// It mirrors the retry and dead letter options of rabbitmq.QueueDefinition.
+type QueueDefinition struct {
+	name      string
+	withDLQ   bool
+	withRetry bool
+	retries   int64
+}
+
+// NewQueue creates a new queue definition with the given name.
+// By default, failed messages are discarded.
+func NewQueue(name string) *QueueDefinition {
+	return &QueueDefinition{name: name}
+}
+
+// WithDLQ enables a Dead Letter Queue (DLQ) for this queue.
+// Messages whose handler fails, with any error, are moved to the DLQ, named "<queue-name>-dlq".
+func (q *QueueDefinition) WithDLQ() *QueueDefinition {
+	q.withDLQ = true
+	return q
+}
+
+// WithRetry enables the retry mechanism for this queue.
+// Messages whose handler returns messaging.RetryableError are redelivered up to the specified number of retries,
+// unless the queue has a DLQ.
+func (q *QueueDefinition) WithRetry(retries int64) *QueueDefinition {
+	q.withRetry = true
+	q.retries = retries
+	return q
+}
+
+// Name returns the name of the queue.
+func (q *QueueDefinition) Name() string {
+	return q.name
+}
+
+// DLQName returns the name of the Dead Letter Queue associated with this queue.
+func (q *QueueDefinition) DLQName() string {
+	return fmt.Sprintf("%s-dlq", q.name)
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+import "context"
+
+type (
+	// OutgoingMessage is a message being published, after it was encoded by the publisher.
+	// Interceptors may change the envelope (e.g. add headers) and the encoded body before
+	// the message is sent to the broker.
+	OutgoingMessage struct {
+		// Envelope holds the destination and the delivery properties of the message.
+		// It is a copy of the envelope provided by the caller, with the content type
+		// set by the publisher codec when it was empty.
+		Envelope *Envelope
+		// Message is the original message provided by the caller.
+		Message any
+		// Body is the encoded message sent to the broker.
+		Body []byte
+	}
+
+	// PublishFunc sends an outgoing message to the broker.
+	PublishFunc func(ctx context.Context, msg *OutgoingMessage) error
+
+	// PublishInterceptor wraps a PublishFunc with additional behavior. Code executed before
+	// calling next runs before the message is sent, and code executed after it observes the
+	// broker result. Returning an error without calling next aborts the publishing.
+	PublishInterceptor func(next PublishFunc) PublishFunc
+)
+
+// ChainPublish wraps the publish function with the interceptors. The first interceptor is the
+// outermost one, so it is the first to receive the message and the last to observe the result.
+func ChainPublish(publish PublishFunc, interceptors ...PublishInterceptor) PublishFunc {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		if interceptors[i] != nil {
+			publish = interceptors[i](publish)
+		}
+	}
+
+	return publish
+}
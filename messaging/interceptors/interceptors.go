@@ -0,0 +1,28 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package interceptors provides built-in messaging.PublishInterceptor implementations for
+// trace context injection, metrics and payload validation. They work with every
+// messaging.Publisher, since they only rely on the envelope and the encoded body.
+//
+// Example:
+//
+//	publisher.Intercept(
+//		interceptors.Tracing(),
+//		interceptors.Metrics(),
+//		interceptors.Validation(),
+//	)
+package interceptors
+
+import "github.com/ralvescosta/gokit/messaging"
+
+var (
+	// ValidationError is returned by the Validation interceptor when the message is invalid.
+	ValidationError = messaging.NewMessagingError("invalid message")
+)
+
+// messageType returns the logical name of the message type, used to identify the message in spans and metrics.
+func messageType(msg any) string {
+	return messaging.TypeName(msg)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/ralvescosta/gokit/messaging/inmemory"
+	"github.com/stretchr/testify/assert"
+)
+
+type order struct {
+	ID string `json:"id"`
+}
+
+func (o order) Validate() error {
+	if o.ID == "" {
+		return errors.New("id is required")
+	}
+
+	return nil
+}
+
+func TestInterceptorsShouldChangeTheOutgoingMessage(t *testing.T) {
+	broker := inmemory.NewBroker()
+	defer broker.Close()
+
+	broker.Intercept(
+		func(next messaging.PublishFunc) messaging.PublishFunc {
+			return func(ctx context.Context, out *messaging.OutgoingMessage) error {
+				out.Envelope.Headers["tenant"] = "acme"
+				out.Envelope.CorrelationID = "correlation"
+				return next(ctx, out)
+			}
+		},
+		Tracing(),
+		Metrics(),
+		Validation(),
+	)
+
+	envelope := messaging.NewEnvelope("orders")
+	assert.NoError(t, broker.PublishEnvelope(context.Background(), envelope, order{ID: "1"}))
+
+	published := broker.Published("orders")
+	assert.Len(t, published, 1)
+	assert.Equal(t, "acme", published[0].Headers["tenant"])
+	assert.Equal(t, "correlation", published[0].Envelope.CorrelationID)
+	assert.Equal(t, inmemory.JSONContentType, published[0].Envelope.ContentType)
+	assert.Empty(t, envelope.Headers, "the caller envelope must not be changed")
+}
+
+func TestValidationShouldRejectInvalidMessages(t *testing.T) {
+	broker := inmemory.NewBroker()
+	defer broker.Close()
+
+	broker.Intercept(Validation(MaxBodySize(16)))
+
+	assert.ErrorIs(t, messaging.Publish(context.Background(), broker, "orders", order{}), ValidationError)
+	assert.ErrorIs(t, messaging.Publish(context.Background(), broker, "orders", order{ID: "a-very-long-order-id"}), ValidationError)
+	assert.NoError(t, messaging.Publish(context.Background(), broker, "orders", order{ID: "1"}))
+	assert.Len(t, broker.Published("orders"), 1)
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package interceptors
+
+import (
+	"context"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Metrics records, using the global meter provider, the number of published messages
+// and the publish latency, labeled with the destination, the message type and the result.
+func Metrics() messaging.PublishInterceptor {
+	meter := otel.Meter("github.com/ralvescosta/gokit/messaging")
+
+	published, _ := meter.Int64Counter(
+		"messaging.publisher.published",
+		metric.WithDescription("Number of messages published"),
+	)
+	duration, _ := meter.Float64Histogram(
+		"messaging.publisher.duration",
+		metric.WithDescription("Time spent publishing messages"),
+		metric.WithUnit("ms"),
+	)
+
+	return func(next messaging.PublishFunc) messaging.PublishFunc {
+		return func(ctx context.Context, out *messaging.OutgoingMessage) error {
+			start := time.Now()
+
+			err := next(ctx, out)
+
+			result := "success"
+			if err != nil {
+				result = "failure"
+			}
+
+			attrs := metric.WithAttributes(
+				attribute.String("destination", out.Envelope.To),
+				attribute.String("type", messageType(out.Message)),
+				attribute.String("result", result),
+			)
+			published.Add(ctx, 1, attrs)
+			duration.Record(ctx, float64(time.Since(start).Milliseconds()), attrs)
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package interceptors
+
+import (
+	"context"
+
+	"github.com/ralvescosta/gokit/messaging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// propagator injects the W3C trace context and baggage, the same formats used by the tracing package.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// Tracing creates a producer span for each published message and injects its trace context
+// into the envelope headers, so consumers can continue the trace.
+// Brokers without message headers (MQTT 3.1.1) only record the producer span.
+func Tracing() messaging.PublishInterceptor {
+	tracer := otel.Tracer("github.com/ralvescosta/gokit/messaging")
+
+	return func(next messaging.PublishFunc) messaging.PublishFunc {
+		return func(ctx context.Context, out *messaging.OutgoingMessage) error {
+			ctx, span := tracer.Start(ctx, "publish "+out.Envelope.To,
+				trace.WithSpanKind(trace.SpanKindProducer),
+				trace.WithAttributes(
+					attribute.String("messaging.destination.name", out.Envelope.To),
+					attribute.String("messaging.message.type", messageType(out.Message)),
+					attribute.Int("messaging.message.body.size", len(out.Body)),
+				),
+			)
+			defer span.End()
+
+			if out.Envelope.Headers == nil {
+				out.Envelope.Headers = map[string]string{}
+			}
+			propagator.Inject(ctx, propagation.MapCarrier(out.Envelope.Headers))
+
+			if err := next(ctx, out); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return err
+			}
+
+			span.SetStatus(codes.Ok, "success")
+			return nil
+		}
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package interceptors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+type (
+	// Validator checks an outgoing message before it is sent to the broker.
+	Validator func(ctx context.Context, out *messaging.OutgoingMessage) error
+
+	// validatable is implemented by messages able to validate themselves.
+	validatable interface {
+		Validate() error
+	}
+)
+
+// Validation rejects invalid messages before they are sent to the broker, returning ValidationError.
+// Messages implementing `Validate() error` are validated first, followed by the provided validators.
+func Validation(validators ...Validator) messaging.PublishInterceptor {
+	return func(next messaging.PublishFunc) messaging.PublishFunc {
+		return func(ctx context.Context, out *messaging.OutgoingMessage) error {
+			if v, ok := out.Message.(validatable); ok {
+				if err := v.Validate(); err != nil {
+					return fmt.Errorf("%w: %s", ValidationError, err)
+				}
+			}
+
+			for _, validator := range validators {
+				if err := validator(ctx, out); err != nil {
+					return fmt.Errorf("%w: %s", ValidationError, err)
+				}
+			}
+
+			return next(ctx, out)
+		}
+	}
+}
+
+// MaxBodySize returns a Validator rejecting messages whose encoded body exceeds the size in bytes.
+func MaxBodySize(size int) Validator {
+	return func(_ context.Context, out *messaging.OutgoingMessage) error {
+		if len(out.Body) > size {
+			return fmt.Errorf("body size %d exceeds the limit of %d bytes", len(out.Body), size)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Metadata describes a consumed message. The dispatchers pass it to the handlers and
+	// middlewares, populated with the properties supported by each broker.
+	Metadata struct {
+		// MessageID is the unique identifier of the message.
+		MessageID string
+		// CorrelationID relates the message to a request or to a chain of messages.
+		CorrelationID string
+		// Type is the message type, as named by TypeName when published by the gokit publishers.
+		Type string
+		// Source is the queue or topic the message was consumed from.
+		Source string
+		// Timestamp is the time the message was published, or received when the broker does not carry it.
+		Timestamp time.Time
+		// Attempt is the delivery attempt of the message, starting at 1.
+		Attempt int
+		// Headers holds the string headers of the message.
+		Headers map[string]string
+		// Raw is the message as received from the broker client, for broker-specific access:
+		// *amqp091.Delivery for RabbitMQ, *kafka.Message for Kafka, paho mqtt.Message for MQTT
+		// and *inmemory.Message for the in-memory broker.
+		Raw any
+
+		acknowledger Acknowledger
+		mu           sync.Mutex
+		settled      bool
+	}
+
+	// Acknowledger settles a consumed message with the broker. It is implemented by each dispatcher.
+	Acknowledger interface {
+		// Ack acknowledges the message, removing it from the broker.
+		Ack() error
+		// Nack rejects the message, either requeueing it or sending it to the dead letter queue.
+		Nack(requeue bool) error
+		// Defer asks the broker to redeliver the message later, through its retry mechanism.
+		Defer() error
+	}
+)
+
+var (
+	// AlreadySettledError is returned when a message is acknowledged more than once.
+	AlreadySettledError = NewMessagingError("message already acknowledged")
+
+	// AcknowledgmentNotSupportedError is returned when the dispatcher does not support an acknowledgment mode.
+	AcknowledgmentNotSupportedError = NewMessagingError("acknowledgment not supported by this dispatcher")
+)
+
+// WithAcknowledger sets the Acknowledger used to settle the message manually.
+// It is called by the dispatchers when building the metadata.
+func (m *Metadata) WithAcknowledger(acknowledger Acknowledger) *Metadata {
+	m.acknowledger = acknowledger
+	return m
+}
+
+// Ack acknowledges the message. The dispatcher does not acknowledge the messages settled
+// by the handler, whatever the handler result.
+//
+// Returns:
+//   - AlreadySettledError if the message was already acknowledged, rejected or deferred.
+//   - AcknowledgmentNotSupportedError if the dispatcher does not support manual acknowledgment.
+func (m *Metadata) Ack() error {
+	return m.settle(func(a Acknowledger) error { return a.Ack() })
+}
+
+// Nack rejects the message. Requeued messages are delivered again, the others are sent
+// to the dead letter queue, when the broker has one, or discarded.
+// See Ack for the returned errors.
+func (m *Metadata) Nack(requeue bool) error {
+	return m.settle(func(a Acknowledger) error { return a.Nack(requeue) })
+}
+
+// Defer asks the broker to redeliver the message later, through its retry mechanism.
+// See Ack for the returned errors.
+func (m *Metadata) Defer() error {
+	return m.settle(func(a Acknowledger) error { return a.Defer() })
+}
+
+// Settled reports whether the message was acknowledged, rejected or deferred by the handler.
+func (m *Metadata) Settled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.settled
+}
+
+// settle executes the acknowledgment once.
+func (m *Metadata) settle(fn func(a Acknowledger) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.settled {
+		return AlreadySettledError
+	}
+
+	if m.acknowledger == nil {
+		return AcknowledgmentNotSupportedError
+	}
+
+	if err := fn(m.acknowledger); err != nil {
+		return err
+	}
+
+	m.settled = true
+
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	MetadataTestSuite struct {
+		suite.Suite
+	}
+
+	acknowledgerMock struct {
+		acks, nacks, defers int
+		requeued            bool
+		err                 error
+	}
+)
+
+func TestMetadataTestSuite(t *testing.T) {
+	suite.Run(t, new(MetadataTestSuite))
+}
+
+func (s *MetadataTestSuite) TestAckOnce() {
+	ack := &acknowledgerMock{}
+	metadata := (&Metadata{}).WithAcknowledger(ack)
+
+	s.False(metadata.Settled())
+	s.NoError(metadata.Ack())
+	s.True(metadata.Settled())
+
+	s.ErrorIs(metadata.Ack(), AlreadySettledError)
+	s.ErrorIs(metadata.Nack(true), AlreadySettledError)
+	s.ErrorIs(metadata.Defer(), AlreadySettledError)
+	s.Equal(1, ack.acks)
+	s.Zero(ack.nacks + ack.defers)
+}
+
+func (s *MetadataTestSuite) TestNackAndDefer() {
+	ack := &acknowledgerMock{}
+
+	s.NoError((&Metadata{}).WithAcknowledger(ack).Nack(true))
+	s.NoError((&Metadata{}).WithAcknowledger(ack).Defer())
+
+	s.True(ack.requeued)
+	s.Equal(1, ack.nacks)
+	s.Equal(1, ack.defers)
+}
+
+func (s *MetadataTestSuite) TestFailedAcknowledgmentIsNotSettled() {
+	ack := &acknowledgerMock{err: errors.New("closed channel")}
+	metadata := (&Metadata{}).WithAcknowledger(ack)
+
+	s.Error(metadata.Ack())
+	s.False(metadata.Settled())
+}
+
+func (s *MetadataTestSuite) TestWithoutAcknowledger() {
+	metadata := &Metadata{}
+
+	s.ErrorIs(metadata.Ack(), AcknowledgmentNotSupportedError)
+	s.False(metadata.Settled())
+}
+
+func (a *acknowledgerMock) Ack() error {
+	a.acks++
+	return a.err
+}
+
+func (a *acknowledgerMock) Nack(requeue bool) error {
+	a.nacks++
+	a.requeued = requeue
+	return a.err
+}
+
+func (a *acknowledgerMock) Defer() error {
+	a.defers++
+	return a.err
+}
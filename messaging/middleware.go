@@ -0,0 +1,27 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+// Middleware wraps a ConsumerHandler with additional behavior, such as logging, tracing,
+// metrics, panic recovery or timeouts. A middleware may run code before and after calling
+// the next handler, change the context passed to it, or short-circuit the chain by returning
+// an error without calling it.
+type Middleware func(next ConsumerHandler) ConsumerHandler
+
+// Chain wraps the handler with the middlewares. The first middleware is the outermost one,
+// so it is the first to receive the message and the last to observe the handler result.
+//
+// Example:
+//
+//	handler = messaging.Chain(handler, middlewares.Recovery(logger), middlewares.Logging(logger))
+func Chain(handler ConsumerHandler, middlewares ...Middleware) ConsumerHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			handler = middlewares[i](handler)
+		}
+	}
+
+	return handler
+}
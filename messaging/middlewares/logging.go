@@ -0,0 +1,46 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/ralvescosta/gokit/logging"
+	"github.com/ralvescosta/gokit/messaging"
+	"go.uber.org/zap"
+)
+
+// Logging logs the processing of each message with its type, duration and result.
+// Successful messages are logged at debug level and failures at error level.
+func Logging(logger logging.Logger) messaging.Middleware {
+	return func(next messaging.ConsumerHandler) messaging.ConsumerHandler {
+		return func(ctx context.Context, msg any, metadata *messaging.Metadata) error {
+			start := time.Now()
+			typ := messageType(msg)
+
+			logger.Debug(LogMessage("processing message"), zap.String("type", typ))
+
+			err := next(ctx, msg, metadata)
+			if err != nil {
+				logger.Error(
+					LogMessage("failure to process message"),
+					zap.String("type", typ),
+					zap.Duration("duration", time.Since(start)),
+					zap.Error(err),
+				)
+				return err
+			}
+
+			logger.Debug(
+				LogMessage("message processed"),
+				zap.String("type", typ),
+				zap.Duration("duration", time.Since(start)),
+			)
+
+			return nil
+		}
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package middlewares
+
+import (
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+// Metrics records, using the global meter provider, the consumer metrics of every message
+// (received, succeeded and failed messages, in-flight messages and the processing duration),
+// labeled with the message type.
+//
+// It is only meant for custom dispatchers. The rabbitmq, kafka and mqtt dispatchers already
+// record these metrics, so registering it on them counts every message twice.
+func Metrics() messaging.Middleware {
+	// The instruments can only fail to be created with an invalid name, in which case
+	// the nil metrics record nothing
+	metrics, _ := messaging.NewConsumerMetrics()
+
+	return metrics.Middleware(messaging.ConsumerLabels{})
+}
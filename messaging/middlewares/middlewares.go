@@ -0,0 +1,41 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package middlewares provides built-in messaging.Middleware implementations for panic
+// recovery, per-message timeouts, structured logging, OpenTelemetry tracing and metrics.
+// They can be registered globally with Dispatcher.Use or per handler with Dispatcher.Register.
+//
+// Example:
+//
+//	dispatcher.Use(
+//		middlewares.Recovery(logger),
+//		middlewares.Tracing(),
+//		middlewares.Logging(logger),
+//		middlewares.Timeout(30*time.Second),
+//	)
+package middlewares
+
+import "github.com/ralvescosta/gokit/messaging"
+
+var (
+	// PanicError is returned by the Recovery middleware when the handler panics.
+	PanicError = messaging.NewMessagingError("handler panicked")
+
+	// TimeoutError is returned by the Timeout middleware when the handler does not finish in time.
+	TimeoutError = messaging.NewMessagingError("handler timed out")
+)
+
+// LogMessage formats and returns a log message with a consistent prefix for the messaging middlewares.
+func LogMessage(msg ...string) string {
+	prefix := "[gokit::messaging] "
+	for _, s := range msg {
+		prefix += s
+	}
+	return prefix
+}
+
+// messageType returns the logical name of the message type, used to identify the message in logs, spans and metrics.
+func messageType(msg any) string {
+	return messaging.TypeName(msg)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestChainShouldApplyMiddlewaresInOrder(t *testing.T) {
+	calls := []string{}
+	track := func(name string) messaging.Middleware {
+		return func(next messaging.ConsumerHandler) messaging.ConsumerHandler {
+			return func(ctx context.Context, msg any, metadata *messaging.Metadata) error {
+				calls = append(calls, name)
+				return next(ctx, msg, metadata)
+			}
+		}
+	}
+
+	handler := messaging.Chain(func(context.Context, any, *messaging.Metadata) error {
+		calls = append(calls, "handler")
+		return nil
+	}, track("first"), nil, track("second"))
+
+	assert.NoError(t, handler(context.Background(), "msg", nil))
+	assert.Equal(t, []string{"first", "second", "handler"}, calls)
+}
+
+func TestRecoveryShouldReturnPanicError(t *testing.T) {
+	handler := Recovery(zap.NewNop())(func(context.Context, any, *messaging.Metadata) error {
+		panic("boom")
+	})
+
+	err := handler(context.Background(), "msg", nil)
+	assert.ErrorIs(t, err, PanicError)
+	assert.Contains(t, err.Error(), "boom")
+}
+
+func TestTimeoutShouldReturnTimeoutError(t *testing.T) {
+	handler := Timeout(10 * time.Millisecond)(func(ctx context.Context, _ any, _ *messaging.Metadata) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+
+	assert.ErrorIs(t, handler(context.Background(), "msg", nil), TimeoutError)
+}
+
+func TestTimeoutShouldReturnHandlerError(t *testing.T) {
+	expected := errors.New("some error")
+	handler := Timeout(time.Second)(func(context.Context, any, *messaging.Metadata) error {
+		return expected
+	})
+
+	assert.ErrorIs(t, handler(context.Background(), "msg", nil), expected)
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+
+	"github.com/ralvescosta/gokit/logging"
+	"github.com/ralvescosta/gokit/messaging"
+	"go.uber.org/zap"
+)
+
+// Recovery recovers from panics raised by the handler, logs the stack trace and
+// returns PanicError, so the dispatcher treats the message as failed instead of crashing.
+func Recovery(logger logging.Logger) messaging.Middleware {
+	return func(next messaging.ConsumerHandler) messaging.ConsumerHandler {
+		return func(ctx context.Context, msg any, metadata *messaging.Metadata) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.Error(
+						LogMessage("recovered from handler panic"),
+						zap.String("type", messageType(msg)),
+						zap.Any("panic", r),
+						zap.ByteString("stack", debug.Stack()),
+					)
+					err = fmt.Errorf("%w: %v", PanicError, r)
+				}
+			}()
+
+			return next(ctx, msg, metadata)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package middlewares
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+// Timeout limits the time each message can be processed. The handler receives a context
+// with the deadline; if it does not return in time, the middleware returns TimeoutError
+// and the handler keeps running in the background until it observes the context cancellation.
+func Timeout(timeout time.Duration) messaging.Middleware {
+	return func(next messaging.ConsumerHandler) messaging.ConsumerHandler {
+		return func(ctx context.Context, msg any, metadata *messaging.Metadata) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			done := make(chan error, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						done <- fmt.Errorf("%w: %v", PanicError, r)
+					}
+				}()
+
+				done <- next(ctx, msg, metadata)
+			}()
+
+			select {
+			case err := <-done:
+				return err
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %s", TimeoutError, ctx.Err())
+			}
+		}
+	}
+}
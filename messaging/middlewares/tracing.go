@@ -0,0 +1,43 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package middlewares
+
+import (
+	"context"
+
+	"github.com/ralvescosta/gokit/messaging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing creates an OpenTelemetry span for each processed message, using the global tracer provider.
+// The span is a child of the span in the handler context, such as the consumer span created by the
+// dispatchers from the propagated headers. Handler errors are recorded in the span.
+func Tracing() messaging.Middleware {
+	tracer := otel.Tracer("github.com/ralvescosta/gokit/messaging")
+
+	return func(next messaging.ConsumerHandler) messaging.ConsumerHandler {
+		return func(ctx context.Context, msg any, metadata *messaging.Metadata) error {
+			typ := messageType(msg)
+
+			ctx, span := tracer.Start(ctx, "process "+typ,
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(attribute.String("messaging.message.type", typ)),
+			)
+			defer span.End()
+
+			if err := next(ctx, msg, metadata); err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				return err
+			}
+
+			span.SetStatus(codes.Ok, "success")
+			return nil
+		}
+	}
+}
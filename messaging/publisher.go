@@ -7,6 +7,7 @@ package messaging
 import "context"
 
 // Option represents a key-value pair for additional dynamic parameters in publishing messages.
+// Prefer PublishEnvelope and the typed EnvelopeOption functions for new code.
 type Option struct {
 	Key   string
 	Value string
@@ -44,4 +45,23 @@ type Publisher interface {
 	// Returns:
 	// - An error if the message could not be sent within the deadline.
 	PublishDeadline(ctx context.Context, to, from, key *string, msg any, options ...*Option) error
+
+	// PublishEnvelope sends a message using the destination and delivery properties of the envelope.
+	// Publish and PublishDeadline are adapters building an envelope with EnvelopeFromOptions.
+	//
+	// Parameters:
+	// - ctx: The context for managing deadlines, cancellations, and other request-scoped values.
+	// - envelope: The destination and delivery properties of the message.
+	// - msg: The message payload to be sent.
+	//
+	// Returns:
+	// - An error if the message could not be sent.
+	PublishEnvelope(ctx context.Context, envelope *Envelope, msg any) error
+
+	// Intercept registers interceptors executed for every published message, after the message is
+	// encoded and before it is sent to the broker. Must be called before publishing.
+	//
+	// Parameters:
+	// - interceptors: The interceptors to be applied, the first one being the outermost.
+	Intercept(interceptors ...PublishInterceptor)
 }
@@ -0,0 +1,127 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package saga
+
+import (
+	"context"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging"
+)
+
+type (
+	// Action is the function executed to invoke or to compensate a step.
+	Action func(ctx context.Context, execution *Execution) error
+
+	// StepDefinition defines a step of the saga: the command issued when the step starts,
+	// the compensation executed when a later step fails, and the time to wait for the step result.
+	StepDefinition struct {
+		name       string
+		invoke     Action
+		compensate Action
+		timeout    time.Duration
+	}
+
+	// Definition is the ordered list of steps of a saga.
+	Definition struct {
+		name  string
+		steps []*StepDefinition
+	}
+
+	// Execution is passed to the step actions, giving access to the saga state and to the publisher.
+	Execution struct {
+		// State is the state of the saga execution. Changes to the data are persisted after the action.
+		State *State
+
+		publisher messaging.Publisher
+		deferred  bool
+		pending   []func(ctx context.Context) error
+	}
+)
+
+// NewDefinition creates an empty saga definition with the given name.
+func NewDefinition(name string) *Definition {
+	return &Definition{name: name}
+}
+
+// Step appends a step to the saga.
+func (d *Definition) Step(step *StepDefinition) *Definition {
+	d.steps = append(d.steps, step)
+	return d
+}
+
+// Name returns the name of the saga.
+func (d *Definition) Name() string {
+	return d.name
+}
+
+// index returns the position of the step with the given name, or -1 if it is not defined.
+func (d *Definition) index(name string) int {
+	for i, s := range d.steps {
+		if s.name == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// NewStep creates a step with the given name, without command, compensation or timeout.
+func NewStep(name string) *StepDefinition {
+	return &StepDefinition{name: name}
+}
+
+// Invoke sets the action issuing the command of the step.
+func (s *StepDefinition) Invoke(action Action) *StepDefinition {
+	s.invoke = action
+	return s
+}
+
+// Compensate sets the action undoing the step, executed when a later step fails.
+func (s *StepDefinition) Compensate(action Action) *StepDefinition {
+	s.compensate = action
+	return s
+}
+
+// WithTimeout sets the time to wait for the step result. The step fails when it expires.
+func (s *StepDefinition) WithTimeout(timeout time.Duration) *StepDefinition {
+	s.timeout = timeout
+	return s
+}
+
+// Name returns the name of the step.
+func (s *StepDefinition) Name() string {
+	return s.name
+}
+
+// Publish sends a command or event, using the saga ID as the correlation ID.
+// The options are applied after the correlation ID, so they can override it.
+//
+// The messages published by the invoke actions are sent after the action returns, once the state
+// with the data changes is stored, so the step results cannot be handled before the step is claimed.
+// Their publishing errors compensate the step, as the errors returned by the action.
+func (e *Execution) Publish(ctx context.Context, to string, msg any, opts ...messaging.EnvelopeOption) error {
+	opts = append([]messaging.EnvelopeOption{messaging.WithCorrelationID(e.State.ID)}, opts...)
+
+	if e.deferred {
+		e.pending = append(e.pending, func(ctx context.Context) error {
+			return messaging.Publish(ctx, e.publisher, to, msg, opts...)
+		})
+		return nil
+	}
+
+	return messaging.Publish(ctx, e.publisher, to, msg, opts...)
+}
+
+// flush sends the messages deferred by the invoke action.
+func (e *Execution) flush(ctx context.Context) error {
+	for _, publish := range e.pending {
+		if err := publish(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
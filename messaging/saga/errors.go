@@ -0,0 +1,39 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package saga
+
+// SagaError represents an error of the saga orchestration.
+type SagaError struct {
+	msg string
+}
+
+// Error implements the error interface and returns the error message.
+func (e *SagaError) Error() string {
+	return e.msg
+}
+
+// NewSagaError creates a new SagaError instance with the provided message.
+func NewSagaError(msg string) error {
+	return &SagaError{msg}
+}
+
+var (
+	// EmptyDefinitionError is returned when a saga without steps is started.
+	EmptyDefinitionError = NewSagaError("saga definition has no steps")
+	// EmptyIDError is returned when a saga is started without an ID.
+	EmptyIDError = NewSagaError("saga id cannot be empty")
+	// UnknownStepError is returned when an event is bound to a step that is not defined.
+	UnknownStepError = NewSagaError("saga step is not defined")
+	// StateNotFoundError is returned by the stores when the saga execution does not exist.
+	StateNotFoundError = NewSagaError("saga state not found")
+	// StateAlreadyExistsError is returned by the stores when a saga execution with the same ID already exists.
+	StateAlreadyExistsError = NewSagaError("saga state already exists")
+	// ConcurrentUpdateError is returned by the stores when the state was updated by another process.
+	ConcurrentUpdateError = NewSagaError("saga state was updated concurrently")
+	// StepTimeoutError is the failure reason of the steps that timed out.
+	StepTimeoutError = NewSagaError("saga step timed out")
+	// StepFailedError is the failure reason of the steps that received a failure event.
+	StepFailedError = NewSagaError("saga step failed")
+)
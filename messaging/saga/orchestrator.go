@@ -0,0 +1,350 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package saga
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/logging"
+	"github.com/ralvescosta/gokit/messaging"
+	"go.uber.org/zap"
+)
+
+type (
+	// Outcome is the result of a step carried by an event.
+	Outcome int
+
+	// Correlator extracts the saga ID from an event received by the dispatcher.
+	Correlator func(msg any, metadata *messaging.Metadata) string
+
+	// Orchestrator executes the steps of a saga definition, advancing them on the events
+	// received through the dispatchers and compensating them on failures and timeouts.
+	//
+	// Each transition is claimed by storing the new state, with the version check of the store,
+	// before the step command or the compensations are published, so concurrent or redelivered
+	// events cannot issue them twice. A command whose publication is interrupted after the claim
+	// is recovered by the step timeout.
+	//
+	// The step that failed or timed out is compensated as well, since its command may have been
+	// partially processed, so the compensations must handle the steps that did not complete.
+	Orchestrator struct {
+		logger     logging.Logger
+		definition *Definition
+		store      Store
+		publisher  messaging.Publisher
+		now        func() time.Time
+	}
+)
+
+const (
+	// StepSucceeded advances the saga to the next step.
+	StepSucceeded Outcome = iota
+	// StepFailed compensates the steps already completed.
+	StepFailed
+)
+
+// NewOrchestrator creates an Orchestrator for the saga definition.
+//
+// Parameters:
+//   - cfgs: Application configurations including the logger.
+//   - definition: The steps of the saga.
+//   - store: The store persisting the state of the executions.
+//   - publisher: The publisher used by the step actions to issue commands.
+//
+// Returns:
+//   - A new Orchestrator instance.
+func NewOrchestrator(cfgs *configs.Configs, definition *Definition, store Store, publisher messaging.Publisher) *Orchestrator {
+	return &Orchestrator{
+		logger:     cfgs.Logger,
+		definition: definition,
+		store:      store,
+		publisher:  publisher,
+		now:        time.Now,
+	}
+}
+
+// Start creates a new execution of the saga and invokes its first step.
+// When the command of the first step fails, the returned state is Compensated.
+//
+// Parameters:
+//   - ctx: The context for managing deadlines, cancellations, and other request-scoped values.
+//   - id: The ID of the execution, usually the ID of the business entity.
+//   - data: The values shared by the steps.
+//
+// Returns:
+//   - The state of the execution.
+//   - StateAlreadyExistsError if the execution was already started, or the error of the store.
+func (o *Orchestrator) Start(ctx context.Context, id string, data map[string]any) (*State, error) {
+	if len(o.definition.steps) == 0 {
+		return nil, EmptyDefinitionError
+	}
+
+	if id == "" {
+		return nil, EmptyIDError
+	}
+
+	if data == nil {
+		data = map[string]any{}
+	}
+
+	now := o.now()
+	state := &State{
+		ID:        id,
+		Saga:      o.definition.name,
+		Status:    Running,
+		Step:      -1,
+		Data:      data,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := o.store.Create(ctx, state); err != nil {
+		return nil, err
+	}
+
+	o.logger.Debug(LogMessage("saga started"), zap.String("saga", state.Saga), zap.String("id", id))
+
+	return state, o.advance(ctx, state)
+}
+
+// Succeed marks the step of the execution as succeeded and invokes the next one,
+// or completes the saga when it was the last step.
+// Events for steps that already finished are ignored, and events for steps not started
+// yet return messaging.RetryableError so the dispatcher redelivers them.
+func (o *Orchestrator) Succeed(ctx context.Context, id, step string) error {
+	state, err := o.current(ctx, id, step)
+	if state == nil || err != nil {
+		return err
+	}
+
+	return o.advance(ctx, state)
+}
+
+// Fail marks the step of the execution as failed and compensates the steps already completed.
+// The events are ignored or redelivered as in Succeed.
+func (o *Orchestrator) Fail(ctx context.Context, id, step string, reason error) error {
+	state, err := o.current(ctx, id, step)
+	if state == nil || err != nil {
+		return err
+	}
+
+	if reason == nil {
+		reason = StepFailedError
+	}
+
+	return o.compensate(ctx, state, reason)
+}
+
+// ByCorrelationID is the Correlator of the events published with the correlation ID of the
+// step commands, which Execution.Publish sets to the saga ID.
+func ByCorrelationID(_ any, metadata *messaging.Metadata) string {
+	return metadata.CorrelationID
+}
+
+// On registers in the dispatcher the handler of an event carrying the outcome of a step.
+//
+// Parameters:
+//   - dispatcher: The dispatcher receiving the event.
+//   - from: The source of the event, as in messaging.Dispatcher.Register.
+//   - msgType: The type of the event.
+//   - step: The name of the step whose outcome the event carries.
+//   - outcome: StepSucceeded or StepFailed.
+//   - correlate: Extracts the saga ID from the event.
+//
+// Returns:
+//   - UnknownStepError if the step is not defined, or the dispatcher error.
+func (o *Orchestrator) On(dispatcher messaging.Dispatcher, from string, msgType any, step string, outcome Outcome, correlate Correlator) error {
+	if o.definition.index(step) < 0 {
+		return UnknownStepError
+	}
+
+	return dispatcher.Register(from, msgType, func(ctx context.Context, msg any, metadata *messaging.Metadata) error {
+		id := correlate(msg, metadata)
+
+		var err error
+		if outcome == StepSucceeded {
+			err = o.Succeed(ctx, id, step)
+		} else {
+			err = o.Fail(ctx, id, step, fmt.Errorf("%w: %s", StepFailedError, step))
+		}
+
+		if errors.Is(err, ConcurrentUpdateError) {
+			return fmt.Errorf("%w: %w", messaging.RetryableError, err)
+		}
+
+		return err
+	})
+}
+
+// CheckTimeouts compensates the running executions whose current step timed out.
+//
+// Returns:
+//   - The number of executions compensated.
+//   - The first error returned by the store or by the compensations.
+func (o *Orchestrator) CheckTimeouts(ctx context.Context) (int, error) {
+	expired, err := o.store.Expired(ctx, o.definition.name, o.now())
+	if err != nil {
+		return 0, err
+	}
+
+	var firstErr error
+	compensated := 0
+	for _, state := range expired {
+		o.logger.Warn(
+			LogMessage("saga step timed out"),
+			zap.String("saga", state.Saga),
+			zap.String("id", state.ID),
+			zap.String("step", o.definition.steps[state.Step].name),
+		)
+
+		if err := o.compensate(ctx, state, StepTimeoutError); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		compensated++
+	}
+
+	return compensated, firstErr
+}
+
+// RunTimeouts checks the timeouts on every interval until the context is canceled.
+//
+// Returns:
+//   - The context error once it is canceled.
+func (o *Orchestrator) RunTimeouts(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := o.CheckTimeouts(ctx); err != nil {
+				o.logger.Error(LogMessage("failure to check the saga timeouts"), zap.Error(err))
+			}
+		}
+	}
+}
+
+// current loads the execution if the step is the one being executed.
+// Returns a nil state when the event must be ignored.
+func (o *Orchestrator) current(ctx context.Context, id, step string) (*State, error) {
+	index := o.definition.index(step)
+	if index < 0 {
+		return nil, UnknownStepError
+	}
+
+	state, err := o.store.Load(ctx, o.definition.name, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Status == Running && index > state.Step {
+		return nil, fmt.Errorf("%w: step %s not started", messaging.RetryableError, step)
+	}
+
+	if state.Status != Running || index < state.Step {
+		o.logger.Debug(
+			LogMessage("ignoring event of finished step"),
+			zap.String("saga", state.Saga),
+			zap.String("id", id),
+			zap.String("step", step),
+		)
+		return nil, nil
+	}
+
+	return state, nil
+}
+
+// advance invokes the step after the current one and claims it, before publishing the step commands,
+// or completes the saga. When the step command fails, the step and the ones already completed are compensated.
+func (o *Orchestrator) advance(ctx context.Context, state *State) error {
+	state.Step++
+	state.Deadline = time.Time{}
+	state.UpdatedAt = o.now()
+
+	if state.Step == len(o.definition.steps) {
+		state.Status = Completed
+		state.Step = len(o.definition.steps) - 1
+
+		o.logger.Debug(LogMessage("saga completed"), zap.String("saga", state.Saga), zap.String("id", state.ID))
+		return o.store.Update(ctx, state)
+	}
+
+	step := o.definition.steps[state.Step]
+	if step.timeout > 0 {
+		state.Deadline = state.UpdatedAt.Add(step.timeout)
+	}
+
+	execution := &Execution{State: state, publisher: o.publisher, deferred: true}
+	if step.invoke != nil {
+		if err := step.invoke(ctx, execution); err != nil {
+			o.logger.Error(LogMessage("failure to invoke saga step"), zap.String("step", step.name), zap.Error(err))
+			return o.compensate(ctx, state, err)
+		}
+	}
+
+	// Claims the step, storing the data changed by the action, before its commands are published
+	if err := o.store.Update(ctx, state); err != nil {
+		return err
+	}
+
+	if err := execution.flush(ctx); err != nil {
+		o.logger.Error(LogMessage("failure to publish saga step command"), zap.String("step", step.name), zap.Error(err))
+		return o.compensate(ctx, state, err)
+	}
+
+	return nil
+}
+
+// compensate claims the compensation of the execution and executes, in reverse order,
+// the compensations of the current step and of the steps before it.
+func (o *Orchestrator) compensate(ctx context.Context, state *State, reason error) error {
+	state.Status = Compensating
+	state.Error = reason.Error()
+	state.Deadline = time.Time{}
+	state.UpdatedAt = o.now()
+
+	if err := o.store.Update(ctx, state); err != nil {
+		return err
+	}
+
+	for ; state.Step >= 0; state.Step-- {
+		step := o.definition.steps[state.Step]
+		if step.compensate == nil {
+			continue
+		}
+
+		if err := step.compensate(ctx, &Execution{State: state, publisher: o.publisher}); err != nil {
+			o.logger.Error(LogMessage("failure to compensate saga step"), zap.String("step", step.name), zap.Error(err))
+
+			state.Status = Failed
+			state.Error = fmt.Sprintf("%s: compensation of %s failed: %s", reason.Error(), step.name, err.Error())
+			state.UpdatedAt = o.now()
+
+			if updateErr := o.store.Update(ctx, state); updateErr != nil {
+				return updateErr
+			}
+
+			return err
+		}
+	}
+
+	state.Step = 0
+	state.Status = Compensated
+	state.UpdatedAt = o.now()
+
+	o.logger.Warn(LogMessage("saga compensated"), zap.String("saga", state.Saga), zap.String("id", state.ID), zap.Error(reason))
+
+	return o.store.Update(ctx, state)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package saga provides a broker-agnostic orchestrator for workflows spanning several services.
+//
+// A saga is defined as an ordered list of steps. Each step issues a command through a
+// messaging.Publisher and waits for an event, received through a messaging.Dispatcher, telling
+// whether the step succeeded or failed. When a step fails or times out, the compensations of the
+// step and of the steps already completed are executed in reverse order.
+//
+// The state of each saga execution is persisted in a Store: MemoryStore for tests, or the SQL
+// store of the sql/sagastore package.
+//
+// Example:
+//
+//	definition := saga.NewDefinition("order").
+//		Step(saga.NewStep("reserve-stock").Invoke(reserveStock).Compensate(releaseStock).WithTimeout(time.Minute)).
+//		Step(saga.NewStep("charge-payment").Invoke(chargePayment).Compensate(refundPayment)).
+//		Step(saga.NewStep("ship-order").Invoke(shipOrder))
+//
+//	orchestrator := saga.NewOrchestrator(cfgs, definition, store, publisher)
+//	_ = orchestrator.On(dispatcher, "stock", StockReserved{}, "reserve-stock", saga.StepSucceeded, byOrderID)
+//	_ = orchestrator.On(dispatcher, "stock", StockRejected{}, "reserve-stock", saga.StepFailed, byOrderID)
+//
+//	_, err := orchestrator.Start(ctx, order.ID, map[string]any{"amount": order.Amount})
+package saga
+
+import (
+	"time"
+)
+
+type (
+	// Status is the status of a saga execution.
+	Status string
+
+	// State is the persisted state of a saga execution.
+	State struct {
+		// ID identifies the execution, usually the ID of the business entity (e.g. the order ID).
+		ID string
+		// Saga is the name of the saga definition.
+		Saga string
+		// Status is the status of the execution.
+		Status Status
+		// Step is the index of the step being executed or compensated.
+		Step int
+		// Data holds the values shared by the steps. It is persisted as JSON.
+		Data map[string]any
+		// Deadline is the time the current step times out. Zero when the step has no timeout.
+		Deadline time.Time
+		// Error is the reason of the failure, when the saga is compensated or failed.
+		Error string
+		// Version is incremented on every update, to detect concurrent updates.
+		Version int
+		// CreatedAt is the time the execution started.
+		CreatedAt time.Time
+		// UpdatedAt is the time of the last update.
+		UpdatedAt time.Time
+	}
+)
+
+const (
+	// Running is the status of the executions waiting for a step to finish.
+	Running Status = "running"
+	// Completed is the status of the executions whose steps all succeeded.
+	Completed Status = "completed"
+	// Compensating is the status of the executions running the compensations.
+	Compensating Status = "compensating"
+	// Compensated is the status of the executions whose compensations all succeeded.
+	Compensated Status = "compensated"
+	// Failed is the status of the executions whose compensation failed and need manual intervention.
+	Failed Status = "failed"
+)
+
+// Clone returns a copy of the state, with its own data.
+func (s *State) Clone() *State {
+	clone := *s
+
+	clone.Data = make(map[string]any, len(s.Data))
+	for k, v := range s.Data {
+		clone.Data[k] = v
+	}
+
+	return &clone
+}
+
+// LogMessage formats a log message with the saga package prefix.
+func LogMessage(msg ...string) string {
+	f := "[gokit::saga] "
+
+	for _, s := range msg {
+		f += s
+	}
+
+	return f
+}
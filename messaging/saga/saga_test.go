@@ -0,0 +1,281 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package saga
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/ralvescosta/gokit/messaging/inmemory"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type (
+	ReserveStock  struct{ OrderID string }
+	ReleaseStock  struct{ OrderID string }
+	StockReserved struct{ OrderID string }
+	ChargePayment struct{ OrderID string }
+	PaymentDenied struct{ OrderID string }
+	PaymentDone   struct{ OrderID string }
+)
+
+// staleStore rejects the updates, as when another event updated the execution.
+type staleStore struct {
+	Store
+}
+
+func (staleStore) Update(context.Context, *State) error {
+	return ConcurrentUpdateError
+}
+
+type SagaTestSuite struct {
+	suite.Suite
+
+	broker       *inmemory.Broker
+	store        *MemoryStore
+	orchestrator *Orchestrator
+	now          time.Time
+}
+
+func TestSagaTestSuite(t *testing.T) {
+	suite.Run(t, new(SagaTestSuite))
+}
+
+func (s *SagaTestSuite) SetupTest() {
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.store = NewMemoryStore()
+	s.broker = inmemory.NewBroker().
+		AwaitTimeout(time.Second).
+		DeclareQueue(inmemory.NewQueue("commands"), inmemory.NewQueue("events").WithRetry(3))
+
+	definition := NewDefinition("order").
+		Step(NewStep("reserve-stock").
+			Invoke(func(ctx context.Context, e *Execution) error {
+				return e.Publish(ctx, "commands", ReserveStock{OrderID: e.State.ID})
+			}).
+			Compensate(func(ctx context.Context, e *Execution) error {
+				return e.Publish(ctx, "commands", ReleaseStock{OrderID: e.State.ID})
+			}).
+			WithTimeout(time.Minute)).
+		Step(NewStep("charge-payment").
+			Invoke(func(ctx context.Context, e *Execution) error {
+				e.State.Data["charged"] = true
+				return e.Publish(ctx, "commands", ChargePayment{OrderID: e.State.ID})
+			}))
+
+	s.orchestrator = NewOrchestrator(&configs.Configs{Logger: zap.NewNop()}, definition, s.store, s.broker)
+	s.orchestrator.now = func() time.Time { return s.now }
+
+	byOrderID := func(msg any, _ *messaging.Metadata) string {
+		switch m := msg.(type) {
+		case *StockReserved:
+			return m.OrderID
+		case *PaymentDenied:
+			return m.OrderID
+		}
+		return ""
+	}
+
+	s.Require().NoError(s.orchestrator.On(s.broker, "events", StockReserved{}, "reserve-stock", StepSucceeded, byOrderID))
+	s.Require().NoError(s.orchestrator.On(s.broker, "events", PaymentDone{}, "charge-payment", StepSucceeded, ByCorrelationID))
+	s.Require().NoError(s.orchestrator.On(s.broker, "events", PaymentDenied{}, "charge-payment", StepFailed, byOrderID))
+
+	go s.broker.ConsumeBlocking()
+}
+
+func (s *SagaTestSuite) TearDownTest() {
+	s.broker.Close()
+}
+
+func (s *SagaTestSuite) TestCompleted() {
+	ctx := context.Background()
+
+	state, err := s.orchestrator.Start(ctx, "order-1", nil)
+	s.Require().NoError(err)
+	s.Equal(Running, state.Status)
+	s.Equal(s.now.Add(time.Minute), state.Deadline)
+
+	s.Require().NoError(messaging.Publish(ctx, s.broker, "events", StockReserved{OrderID: "order-1"}))
+	_, err = s.broker.AwaitProcessed("events", 1)
+	s.Require().NoError(err)
+
+	s.Require().NoError(messaging.Publish(ctx, s.broker, "events", PaymentDone{}, messaging.WithCorrelationID("order-1")))
+	_, err = s.broker.AwaitProcessed("events", 2)
+	s.Require().NoError(err)
+
+	state, err = s.store.Load(ctx, "order", "order-1")
+	s.Require().NoError(err)
+	s.Equal(Completed, state.Status)
+	s.Equal(true, state.Data["charged"])
+
+	commands := s.broker.Published("commands")
+	s.Require().Len(commands, 2)
+	s.Equal("order-1", commands[0].Envelope.CorrelationID)
+	s.Equal("saga.ChargePayment", commands[1].Type)
+}
+
+func (s *SagaTestSuite) TestCompensated() {
+	ctx := context.Background()
+
+	_, err := s.orchestrator.Start(ctx, "order-1", nil)
+	s.Require().NoError(err)
+
+	s.Require().NoError(messaging.Publish(ctx, s.broker, "events", StockReserved{OrderID: "order-1"}))
+	s.Require().NoError(messaging.Publish(ctx, s.broker, "events", PaymentDenied{OrderID: "order-1"}))
+	_, err = s.broker.AwaitProcessed("events", 2)
+	s.Require().NoError(err)
+
+	state, err := s.store.Load(ctx, "order", "order-1")
+	s.Require().NoError(err)
+	s.Equal(Compensated, state.Status)
+	s.Contains(state.Error, StepFailedError.Error())
+
+	commands := s.broker.Published("commands")
+	s.Require().Len(commands, 3)
+	s.Equal("saga.ReleaseStock", commands[2].Type)
+}
+
+func (s *SagaTestSuite) TestEventOfStepNotStarted() {
+	ctx := context.Background()
+
+	_, err := s.orchestrator.Start(ctx, "order-1", nil)
+	s.Require().NoError(err)
+
+	err = s.orchestrator.Succeed(ctx, "order-1", "charge-payment")
+	s.ErrorIs(err, messaging.RetryableError)
+
+	s.Require().NoError(s.orchestrator.Succeed(ctx, "order-1", "reserve-stock"))
+
+	// duplicated events of finished steps are ignored
+	s.NoError(s.orchestrator.Succeed(ctx, "order-1", "reserve-stock"))
+
+	state, err := s.store.Load(ctx, "order", "order-1")
+	s.Require().NoError(err)
+	s.Equal(1, state.Step)
+}
+
+func (s *SagaTestSuite) TestTimeout() {
+	ctx := context.Background()
+
+	_, err := s.orchestrator.Start(ctx, "order-1", nil)
+	s.Require().NoError(err)
+
+	compensated, err := s.orchestrator.CheckTimeouts(ctx)
+	s.NoError(err)
+	s.Equal(0, compensated)
+
+	s.now = s.now.Add(time.Minute)
+
+	compensated, err = s.orchestrator.CheckTimeouts(ctx)
+	s.NoError(err)
+	s.Equal(1, compensated)
+
+	state, err := s.store.Load(ctx, "order", "order-1")
+	s.Require().NoError(err)
+	s.Equal(Compensated, state.Status)
+	s.Equal(StepTimeoutError.Error(), state.Error)
+
+	// the step that timed out is compensated as well
+	commands := s.broker.Published("commands")
+	s.Require().Len(commands, 2)
+	s.Equal("saga.ReleaseStock", commands[1].Type)
+}
+
+func (s *SagaTestSuite) TestTransitionClaimedBeforeCommand() {
+	ctx := context.Background()
+
+	_, err := s.orchestrator.Start(ctx, "order-1", nil)
+	s.Require().NoError(err)
+
+	// an event processed concurrently advances the execution first
+	s.orchestrator.store = &staleStore{Store: s.store}
+
+	err = s.orchestrator.Succeed(ctx, "order-1", "reserve-stock")
+	s.ErrorIs(err, ConcurrentUpdateError)
+
+	commands := s.broker.Published("commands")
+	s.Require().Len(commands, 1)
+	s.Equal("saga.ReserveStock", commands[0].Type)
+}
+
+func (s *SagaTestSuite) TestResultHandledDuringCommand() {
+	ctx := context.Background()
+
+	// the payment result is handled before the publishing of its command returns
+	var resultErr error
+	s.broker.Intercept(func(next messaging.PublishFunc) messaging.PublishFunc {
+		return func(ctx context.Context, out *messaging.OutgoingMessage) error {
+			if err := next(ctx, out); err != nil {
+				return err
+			}
+
+			if _, ok := out.Message.(ChargePayment); ok {
+				resultErr = s.orchestrator.Succeed(ctx, "order-1", "charge-payment")
+			}
+			return nil
+		}
+	})
+
+	_, err := s.orchestrator.Start(ctx, "order-1", nil)
+	s.Require().NoError(err)
+	s.Require().NoError(s.orchestrator.Succeed(ctx, "order-1", "reserve-stock"))
+	s.NoError(resultErr)
+
+	state, err := s.store.Load(ctx, "order", "order-1")
+	s.Require().NoError(err)
+	s.Equal(Completed, state.Status)
+	s.Equal(true, state.Data["charged"])
+}
+
+func (s *SagaTestSuite) TestStartErrors() {
+	ctx := context.Background()
+
+	_, err := s.orchestrator.Start(ctx, "", nil)
+	s.ErrorIs(err, EmptyIDError)
+
+	_, err = s.orchestrator.Start(ctx, "order-1", nil)
+	s.Require().NoError(err)
+
+	_, err = s.orchestrator.Start(ctx, "order-1", nil)
+	s.ErrorIs(err, StateAlreadyExistsError)
+
+	err = s.orchestrator.On(s.broker, "events", StockReserved{}, "unknown", StepSucceeded, nil)
+	s.ErrorIs(err, UnknownStepError)
+}
+
+func (s *SagaTestSuite) TestCompensationFailure() {
+	ctx := context.Background()
+	definition := NewDefinition("failing").
+		Step(NewStep("first").Compensate(func(context.Context, *Execution) error { return errors.New("unavailable") })).
+		Step(NewStep("second"))
+	orchestrator := NewOrchestrator(&configs.Configs{Logger: zap.NewNop()}, definition, s.store, s.broker)
+
+	_, err := orchestrator.Start(ctx, "order-1", nil)
+	s.Require().NoError(err)
+	s.Require().NoError(orchestrator.Succeed(ctx, "order-1", "first"))
+
+	err = orchestrator.Fail(ctx, "order-1", "second", nil)
+	s.Error(err)
+
+	state, err := s.store.Load(ctx, "failing", "order-1")
+	s.Require().NoError(err)
+	s.Equal(Failed, state.Status)
+}
+
+func (s *SagaTestSuite) TestMemoryStoreConcurrentUpdate() {
+	ctx := context.Background()
+	s.Require().NoError(s.store.Create(ctx, &State{ID: "1", Saga: "order", Status: Running}))
+
+	first, _ := s.store.Load(ctx, "order", "1")
+	second, _ := s.store.Load(ctx, "order", "1")
+
+	s.NoError(s.store.Update(ctx, first))
+	s.ErrorIs(s.store.Update(ctx, second), ConcurrentUpdateError)
+}
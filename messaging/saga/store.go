@@ -0,0 +1,106 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package saga
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// Store persists the state of the saga executions.
+	Store interface {
+		// Create stores the state of a new execution.
+		// Returns StateAlreadyExistsError if an execution with the same saga and ID exists.
+		Create(ctx context.Context, state *State) error
+
+		// Load returns the state of the execution.
+		// Returns StateNotFoundError if the execution does not exist.
+		Load(ctx context.Context, saga, id string) (*State, error)
+
+		// Update stores the state and increments its version.
+		// Returns ConcurrentUpdateError if the stored version differs from the state version.
+		Update(ctx context.Context, state *State) error
+
+		// Expired returns the running executions of the saga whose deadline is before now.
+		Expired(ctx context.Context, saga string, now time.Time) ([]*State, error)
+	}
+
+	// MemoryStore is a Store keeping the states in memory, meant for tests and for single-instance applications.
+	MemoryStore struct {
+		mu     sync.RWMutex
+		states map[string]*State
+	}
+)
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{states: map[string]*State{}}
+}
+
+func (m *MemoryStore) Create(_ context.Context, state *State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := storeKey(state.Saga, state.ID)
+	if _, ok := m.states[key]; ok {
+		return StateAlreadyExistsError
+	}
+
+	m.states[key] = state.Clone()
+
+	return nil
+}
+
+func (m *MemoryStore) Load(_ context.Context, saga, id string) (*State, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	state, ok := m.states[storeKey(saga, id)]
+	if !ok {
+		return nil, StateNotFoundError
+	}
+
+	return state.Clone(), nil
+}
+
+func (m *MemoryStore) Update(_ context.Context, state *State) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := storeKey(state.Saga, state.ID)
+	stored, ok := m.states[key]
+	if !ok {
+		return StateNotFoundError
+	}
+
+	if stored.Version != state.Version {
+		return ConcurrentUpdateError
+	}
+
+	state.Version++
+	m.states[key] = state.Clone()
+
+	return nil
+}
+
+func (m *MemoryStore) Expired(_ context.Context, saga string, now time.Time) ([]*State, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	expired := []*State{}
+	for _, state := range m.states {
+		if state.Saga == saga && state.Status == Running && !state.Deadline.IsZero() && !state.Deadline.After(now) {
+			expired = append(expired, state.Clone())
+		}
+	}
+
+	return expired, nil
+}
+
+func storeKey(saga, id string) string {
+	return saga + "/" + id
+}
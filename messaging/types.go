@@ -0,0 +1,198 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+type (
+	// TypeRegistry maps the Go message types to stable logical names (e.g. orders.created.v1),
+	// sent by the publishers as the message type and used by the dispatchers to route the messages.
+	// Values and pointers of a registered type share the same name, and aliases let the messages
+	// published with previous names (e.g. *main.OrderCreated) still be routed to the handlers.
+	TypeRegistry struct {
+		mu      sync.RWMutex
+		names   map[reflect.Type]string
+		types   map[string]reflect.Type
+		aliases map[string]string
+	}
+
+	// EncodedMessage is a message already encoded as JSON, published with the type name of the message
+	// it was encoded from, e.g. by the relay of the sql/scheduler package. The body is marshaled as it is.
+	EncodedMessage struct {
+		// Type is the logical name of the message type, as returned by TypeName.
+		Type string
+		// Body is the JSON encoded message.
+		Body json.RawMessage
+	}
+)
+
+var (
+	// DefaultTypeRegistry is the registry used by the publishers and dispatchers of the gokit packages.
+	DefaultTypeRegistry = NewTypeRegistry()
+
+	// EmptyTypeNameError is returned when a type is registered without a name or with a nil message.
+	EmptyTypeNameError = NewMessagingError("message type and name cannot be empty")
+
+	// TypeNameConflictError is returned when a name or alias is already registered for another type.
+	TypeNameConflictError = NewMessagingError("message type name already registered for another type")
+)
+
+// NewTypeRegistry creates an empty TypeRegistry.
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{
+		names:   map[reflect.Type]string{},
+		types:   map[string]reflect.Type{},
+		aliases: map[string]string{},
+	}
+}
+
+// Register maps the type of the message to a logical name.
+//
+// Parameters:
+//   - msg: A value or a pointer of the message type.
+//   - name: The logical name of the type, e.g. orders.created.v1.
+//   - aliases: Names the type was previously published with, resolved to the logical name.
+//
+// Returns:
+//   - EmptyTypeNameError if the message is nil or the name is empty.
+//   - TypeNameConflictError if the type, the name or an alias is already registered differently.
+//
+// Example:
+//
+//	err := registry.Register(OrderCreated{}, "orders.created.v1", "*main.OrderCreated", "main.OrderCreated")
+func (r *TypeRegistry) Register(msg any, name string, aliases ...string) error {
+	if msg == nil || name == "" {
+		return EmptyTypeNameError
+	}
+
+	typ := baseType(reflect.TypeOf(msg))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if registered, ok := r.names[typ]; ok && registered != name {
+		return fmt.Errorf("%w: %s is registered as %s", TypeNameConflictError, typ, registered)
+	}
+
+	if registered, ok := r.types[name]; ok && registered != typ {
+		return fmt.Errorf("%w: %s", TypeNameConflictError, name)
+	}
+
+	for _, alias := range aliases {
+		if target, ok := r.aliases[alias]; ok && target != name {
+			return fmt.Errorf("%w: %s", TypeNameConflictError, alias)
+		}
+
+		if registered, ok := r.types[alias]; ok && registered != typ {
+			return fmt.Errorf("%w: %s", TypeNameConflictError, alias)
+		}
+	}
+
+	r.names[typ] = name
+	r.types[name] = typ
+
+	for _, alias := range aliases {
+		if alias != name {
+			r.aliases[alias] = name
+		}
+	}
+
+	return nil
+}
+
+// MustRegister is like Register but panics if the type cannot be registered.
+// It is meant to be used in package initialization.
+func (r *TypeRegistry) MustRegister(msg any, name string, aliases ...string) {
+	if err := r.Register(msg, name, aliases...); err != nil {
+		panic(err)
+	}
+}
+
+// Name returns the logical name of the message type.
+// Types that were not registered are named after their Go type (fmt.Sprintf("%T", msg)),
+// as the publishers and dispatchers always did, and encoded messages keep their Type.
+func (r *TypeRegistry) Name(msg any) string {
+	switch m := msg.(type) {
+	case nil:
+		return ""
+	case EncodedMessage:
+		return m.Type
+	case *EncodedMessage:
+		if m != nil {
+			return m.Type
+		}
+	}
+
+	r.mu.RLock()
+	name, ok := r.names[baseType(reflect.TypeOf(msg))]
+	r.mu.RUnlock()
+
+	if ok {
+		return name
+	}
+
+	return fmt.Sprintf("%T", msg)
+}
+
+// Resolve returns the logical name registered for the alias, or the name itself
+// when it is not an alias. Dispatchers resolve the received types before routing them.
+func (r *TypeRegistry) Resolve(name string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if target, ok := r.aliases[name]; ok {
+		return target
+	}
+
+	return name
+}
+
+// Type returns the Go type registered with the name or one of its aliases.
+func (r *TypeRegistry) Type(name string) (reflect.Type, bool) {
+	name = r.Resolve(name)
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	typ, ok := r.types[name]
+	return typ, ok
+}
+
+// RegisterType maps the message type to a logical name in the DefaultTypeRegistry.
+// See TypeRegistry.Register.
+func RegisterType(msg any, name string, aliases ...string) error {
+	return DefaultTypeRegistry.Register(msg, name, aliases...)
+}
+
+// TypeName returns the logical name of the message type from the DefaultTypeRegistry.
+// See TypeRegistry.Name.
+func TypeName(msg any) string {
+	return DefaultTypeRegistry.Name(msg)
+}
+
+// ResolveTypeName returns the logical name of the alias from the DefaultTypeRegistry.
+// See TypeRegistry.Resolve.
+func ResolveTypeName(name string) string {
+	return DefaultTypeRegistry.Resolve(name)
+}
+
+// MarshalJSON returns the encoded body.
+func (m EncodedMessage) MarshalJSON() ([]byte, error) {
+	return m.Body.MarshalJSON()
+}
+
+// baseType returns the element type of pointers, so that values and pointers share the same name.
+func baseType(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	return typ
+}
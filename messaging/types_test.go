@@ -0,0 +1,80 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package messaging
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type (
+	OrderCreated struct{ ID string }
+	OrderPaid    struct{ ID string }
+)
+
+type TypeRegistryTestSuite struct {
+	suite.Suite
+
+	registry *TypeRegistry
+}
+
+func TestTypeRegistryTestSuite(t *testing.T) {
+	suite.Run(t, new(TypeRegistryTestSuite))
+}
+
+func (s *TypeRegistryTestSuite) SetupTest() {
+	s.registry = NewTypeRegistry()
+}
+
+func (s *TypeRegistryTestSuite) TestName() {
+	s.Require().NoError(s.registry.Register(&OrderCreated{}, "orders.created.v1"))
+
+	s.Equal("orders.created.v1", s.registry.Name(OrderCreated{}))
+	s.Equal("orders.created.v1", s.registry.Name(&OrderCreated{}))
+	s.Equal("messaging.OrderPaid", s.registry.Name(OrderPaid{}))
+	s.Equal("*messaging.OrderPaid", s.registry.Name(&OrderPaid{}))
+	s.Empty(s.registry.Name(nil))
+}
+
+func (s *TypeRegistryTestSuite) TestEncodedMessageName() {
+	encoded := EncodedMessage{Type: "orders.created.v1", Body: []byte(`{"ID":"1"}`)}
+
+	s.Equal("orders.created.v1", s.registry.Name(encoded))
+	s.Equal("orders.created.v1", s.registry.Name(&encoded))
+
+	body, err := json.Marshal(encoded)
+	s.NoError(err)
+	s.JSONEq(`{"ID":"1"}`, string(body))
+}
+
+func (s *TypeRegistryTestSuite) TestAliases() {
+	s.Require().NoError(s.registry.Register(OrderCreated{}, "orders.created.v2", "orders.created.v1", "*main.OrderCreated"))
+
+	s.Equal("orders.created.v2", s.registry.Resolve("orders.created.v1"))
+	s.Equal("orders.created.v2", s.registry.Resolve("*main.OrderCreated"))
+	s.Equal("orders.paid.v1", s.registry.Resolve("orders.paid.v1"))
+
+	typ, ok := s.registry.Type("orders.created.v1")
+	s.True(ok)
+	s.Equal("OrderCreated", typ.Name())
+
+	_, ok = s.registry.Type("orders.paid.v1")
+	s.False(ok)
+}
+
+func (s *TypeRegistryTestSuite) TestRegisterConflicts() {
+	s.ErrorIs(s.registry.Register(nil, "orders.created.v1"), EmptyTypeNameError)
+	s.ErrorIs(s.registry.Register(OrderCreated{}, ""), EmptyTypeNameError)
+
+	s.Require().NoError(s.registry.Register(OrderCreated{}, "orders.created.v1", "created"))
+	s.NoError(s.registry.Register(&OrderCreated{}, "orders.created.v1"))
+
+	s.ErrorIs(s.registry.Register(OrderCreated{}, "orders.created.v2"), TypeNameConflictError)
+	s.ErrorIs(s.registry.Register(OrderPaid{}, "orders.created.v1"), TypeNameConflictError)
+	s.ErrorIs(s.registry.Register(OrderPaid{}, "orders.paid.v1", "created"), TypeNameConflictError)
+	s.ErrorIs(s.registry.Register(OrderPaid{}, "orders.paid.v1", "orders.created.v1"), TypeNameConflictError)
+}
@@ -6,6 +6,7 @@ package mqtt
 
 import (
 	"fmt"
+	"sync"
 
 	myQTT "github.com/eclipse/paho.mqtt.golang"
 	"github.com/ralvescosta/gokit/configs"
@@ -19,6 +20,23 @@ type MQTTClient interface {
 	Connect() error
 	// Client returns the underlying MQTT client instance.
 	Client() myQTT.Client
+	// AddOnConnectHandler registers a handler executed every time the connection with the broker
+	// is established, including after an automatic reconnection.
+	AddOnConnectHandler(handler myQTT.OnConnectHandler)
+	// WithWill configures the Last Will and Testament message published by the broker when the
+	// client disconnects ungracefully. Must be called before Connect.
+	WithWill(topic string, qos QoS, retain bool, payload []byte) MQTTClient
+	// WithBirth configures the message published by the client after every successful connection.
+	// Must be called before Connect.
+	WithBirth(topic string, qos QoS, retain bool, payload []byte) MQTTClient
+}
+
+// presenceMessage is a message used to announce the client status (will and birth messages).
+type presenceMessage struct {
+	topic   string
+	qos     QoS
+	retain  bool
+	payload []byte
 }
 
 // mqttClient is the concrete implementation of the MQTTClient interface.
@@ -26,14 +44,44 @@ type mqttClient struct {
 	logger logging.Logger
 	cfgs   *configs.Configs
 	client myQTT.Client
+	will   *presenceMessage
+	birth  *presenceMessage
+
+	mu                sync.RWMutex
+	onConnectHandlers []myQTT.OnConnectHandler
 }
 
 // NewMQTTClient creates a new instance of mqttClient.
+// The will and birth messages are loaded from the MQTT configs when their topics are set.
 func NewMQTTClient(cfgs *configs.Configs) MQTTClient {
-	return &mqttClient{
+	c := &mqttClient{
 		cfgs:   cfgs,
 		logger: cfgs.Logger,
 	}
+
+	if mqttCfgs := cfgs.MQTTConfigs; mqttCfgs != nil {
+		if mqttCfgs.WillTopic != "" {
+			c.WithWill(mqttCfgs.WillTopic, QoSFromBytes(mqttCfgs.WillQoS), mqttCfgs.WillRetain, []byte(mqttCfgs.WillPayload))
+		}
+
+		if mqttCfgs.BirthTopic != "" {
+			c.WithBirth(mqttCfgs.BirthTopic, QoSFromBytes(mqttCfgs.BirthQoS), mqttCfgs.BirthRetain, []byte(mqttCfgs.BirthPayload))
+		}
+	}
+
+	return c
+}
+
+// WithWill configures the Last Will and Testament message.
+func (c *mqttClient) WithWill(topic string, qos QoS, retain bool, payload []byte) MQTTClient {
+	c.will = &presenceMessage{topic, qos, retain, payload}
+	return c
+}
+
+// WithBirth configures the message published after every successful connection.
+func (c *mqttClient) WithBirth(topic string, qos QoS, retain bool, payload []byte) MQTTClient {
+	c.birth = &presenceMessage{topic, qos, retain, payload}
+	return c
 }
 
 // Connect establishes a connection to the MQTT broker.
@@ -44,8 +92,20 @@ func (c *mqttClient) Connect() error {
 	clientOpts.AddBroker(fmt.Sprintf("%s://%s:%v", "tcp", c.cfgs.MQTTConfigs.Host, c.cfgs.MQTTConfigs.Port))
 	clientOpts.SetUsername(c.cfgs.MQTTConfigs.User)
 	clientOpts.SetPassword(c.cfgs.MQTTConfigs.Password)
-	clientOpts.SetClientID(c.cfgs.AppConfigs.AppName)
+	clientOpts.SetClientID(c.clientID())
+	clientOpts.SetCleanSession(c.cfgs.MQTTConfigs.CleanSession)
+	// With a persistent session the broker keeps the subscriptions, so paho resumes the
+	// in-flight subscribe requests instead of discarding them
+	clientOpts.SetResumeSubs(!c.cfgs.MQTTConfigs.CleanSession)
+	if c.cfgs.MQTTConfigs.StorePath != "" {
+		clientOpts.SetStore(myQTT.NewFileStore(c.cfgs.MQTTConfigs.StorePath))
+	}
+	if c.will != nil {
+		clientOpts.SetBinaryWill(c.will.topic, c.will.payload, byte(c.will.qos), c.will.retain)
+	}
 	clientOpts.Order = false
+	// Messages are acknowledged by the dispatcher only after the handler succeeds
+	clientOpts.SetAutoAckDisabled(true)
 	clientOpts.OnConnect = c.onConnectionEvent
 	clientOpts.OnConnectionLost = c.onDisconnectEvent
 	clientOpts.OnReconnecting = c.onReconnectionEvent
@@ -67,9 +127,47 @@ func (c *mqttClient) Client() myQTT.Client {
 	return c.client
 }
 
-// onConnectionEvent handles the MQTT broker connection event.
-func (c *mqttClient) onConnectionEvent(_ myQTT.Client) {
+// AddOnConnectHandler registers a handler executed on every connection with the broker.
+func (c *mqttClient) AddOnConnectHandler(handler myQTT.OnConnectHandler) {
+	if handler == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.onConnectHandlers = append(c.onConnectHandlers, handler)
+}
+
+// clientID builds the MQTT client ID from the application name and the configured suffix.
+func (c *mqttClient) clientID() string {
+	if c.cfgs.MQTTConfigs.ClientIDSuffix == "" {
+		return c.cfgs.AppConfigs.AppName
+	}
+
+	return fmt.Sprintf("%s-%s", c.cfgs.AppConfigs.AppName, c.cfgs.MQTTConfigs.ClientIDSuffix)
+}
+
+// onConnectionEvent handles the MQTT broker connection event, publishes the birth message
+// and executes the registered handlers.
+func (c *mqttClient) onConnectionEvent(client myQTT.Client) {
 	c.logger.Debug(LogMessage("connected to the MQTT broker"))
+
+	if c.birth != nil {
+		token := client.Publish(c.birth.topic, byte(c.birth.qos), c.birth.retain, c.birth.payload)
+		if token.Wait() && token.Error() != nil {
+			c.logger.Error(LogMessage("failure to publish the birth message"), zap.String("topic", c.birth.topic), zap.Error(token.Error()))
+		}
+	}
+
+	c.mu.RLock()
+	handlers := make([]myQTT.OnConnectHandler, len(c.onConnectHandlers))
+	copy(handlers, c.onConnectHandlers)
+	c.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(client)
+	}
 }
 
 // onDisconnectEvent handles the MQTT broker disconnection event.
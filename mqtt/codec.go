@@ -0,0 +1,139 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package mqtt
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Content types supported by the MQTT codecs.
+const (
+	// JSONContentType is the MIME type used for JSON encoded payloads.
+	JSONContentType = "application/json"
+	// ProtobufContentType is the MIME type used for protobuf encoded payloads.
+	ProtobufContentType = "application/x-protobuf"
+	// OctetStreamContentType is the MIME type used for raw payloads ([]byte or string).
+	OctetStreamContentType = "application/octet-stream"
+)
+
+type (
+	// Codec defines how message payloads are encoded before publishing
+	// and decoded before being delivered to a typed handler.
+	Codec interface {
+		// ContentType returns the MIME type of the payloads produced by the codec.
+		ContentType() string
+		// Marshal encodes the message into a payload.
+		Marshal(msg any) ([]byte, error)
+		// Unmarshal decodes the payload into the message pointer.
+		Unmarshal(payload []byte, msg any) error
+	}
+
+	// jsonCodec encodes payloads using encoding/json, the same format used by the rabbitmq package.
+	jsonCodec struct{}
+
+	// protobufCodec encodes payloads using the protobuf wire format.
+	protobufCodec struct{}
+)
+
+var (
+	// JSONCodec is the default codec, used for any message that is not a protobuf message.
+	JSONCodec Codec = jsonCodec{}
+	// ProtobufCodec is the codec used for messages implementing proto.Message.
+	ProtobufCodec Codec = protobufCodec{}
+
+	protoMessageType = reflect.TypeOf((*proto.Message)(nil)).Elem()
+)
+
+func (jsonCodec) ContentType() string { return JSONContentType }
+
+func (jsonCodec) Marshal(msg any) ([]byte, error) { return json.Marshal(msg) }
+
+func (jsonCodec) Unmarshal(payload []byte, msg any) error { return json.Unmarshal(payload, msg) }
+
+func (protobufCodec) ContentType() string { return ProtobufContentType }
+
+func (protobufCodec) Marshal(msg any) ([]byte, error) {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil, InvalidProtobufMessageError
+	}
+
+	return proto.Marshal(m)
+}
+
+func (protobufCodec) Unmarshal(payload []byte, msg any) error {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return InvalidProtobufMessageError
+	}
+
+	return proto.Unmarshal(payload, m)
+}
+
+// CodecFor returns the codec used to encode the given message:
+// ProtobufCodec for protobuf messages and JSONCodec for everything else.
+func CodecFor(msg any) Codec {
+	if _, ok := msg.(proto.Message); ok {
+		return ProtobufCodec
+	}
+
+	return JSONCodec
+}
+
+// EncodePayload converts a message into an MQTT payload and returns its content type.
+// Raw payloads ([]byte and string) are sent as they are, any other value is encoded
+// with the codec returned by CodecFor.
+func EncodePayload(msg any) ([]byte, string, error) {
+	switch v := msg.(type) {
+	case []byte:
+		return v, OctetStreamContentType, nil
+	case string:
+		return []byte(v), OctetStreamContentType, nil
+	}
+
+	codec := CodecFor(msg)
+
+	payload, err := codec.Marshal(msg)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return payload, codec.ContentType(), nil
+}
+
+// codecForType returns the codec able to decode payloads into values of type T.
+func codecForType[T any]() Codec {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Implements(protoMessageType) || reflect.PointerTo(typ).Implements(protoMessageType) {
+		return ProtobufCodec
+	}
+
+	return JSONCodec
+}
+
+// decodePayload decodes the payload into a new value of type T.
+// When T is a pointer type, a new instance of the pointed type is allocated.
+func decodePayload[T any](codec Codec, payload []byte) (T, error) {
+	var msg T
+
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() == reflect.Pointer {
+		ptr := reflect.New(typ.Elem())
+		if err := codec.Unmarshal(payload, ptr.Interface()); err != nil {
+			return msg, err
+		}
+
+		return ptr.Interface().(T), nil
+	}
+
+	if err := codec.Unmarshal(payload, &msg); err != nil {
+		return msg, err
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package mqtt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type telemetry struct {
+	Device string  `json:"device"`
+	Value  float64 `json:"value"`
+}
+
+// TestEncodePayload verifies that the codec is selected according to the message type.
+func TestEncodePayload(t *testing.T) {
+	payload, contentType, err := EncodePayload(&telemetry{Device: "42", Value: 1.5})
+	assert.NoError(t, err)
+	assert.Equal(t, JSONContentType, contentType)
+	assert.JSONEq(t, `{"device":"42","value":1.5}`, string(payload))
+
+	payload, contentType, err = EncodePayload([]byte("raw"))
+	assert.NoError(t, err)
+	assert.Equal(t, OctetStreamContentType, contentType)
+	assert.Equal(t, []byte("raw"), payload)
+
+	_, contentType, err = EncodePayload(wrapperspb.String("proto"))
+	assert.NoError(t, err)
+	assert.Equal(t, ProtobufContentType, contentType)
+}
+
+// TestDecodePayload verifies that payloads are decoded into values and pointers of the registered type.
+func TestDecodePayload(t *testing.T) {
+	value, err := decodePayload[telemetry](codecForType[telemetry](), []byte(`{"device":"42","value":1.5}`))
+	assert.NoError(t, err)
+	assert.Equal(t, telemetry{Device: "42", Value: 1.5}, value)
+
+	ptr, err := decodePayload[*telemetry](codecForType[*telemetry](), []byte(`{"device":"42"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "42", ptr.Device)
+
+	payload, _, _ := EncodePayload(wrapperspb.String("proto"))
+	codec := codecForType[*wrapperspb.StringValue]()
+	assert.Equal(t, ProtobufCodec, codec)
+
+	msg, err := decodePayload[*wrapperspb.StringValue](codec, payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "proto", msg.GetValue())
+
+	_, err = decodePayload[telemetry](JSONCodec, []byte("invalid"))
+	assert.Error(t, err)
+}
+
+// TestRegisterTypedCodecMismatch verifies that typed handlers of overlapping topics must use the same codec.
+func TestRegisterTypedCodecMismatch(t *testing.T) {
+	d := &mqttDispatcher{codecs: map[string]Codec{}}
+
+	jsonHandler := func(context.Context, telemetry, *MessageMetadata) error { return nil }
+	protoHandler := func(context.Context, *wrapperspb.StringValue, *MessageMetadata) error { return nil }
+
+	assert.NoError(t, RegisterTyped(d, "devices/+/telemetry", AtLeastOnce, jsonHandler))
+	assert.NoError(t, RegisterTyped(d, "devices/42/telemetry", AtLeastOnce, jsonHandler))
+	assert.NoError(t, RegisterTyped(d, "devices/+/status", AtLeastOnce, protoHandler))
+	assert.ErrorIs(t, RegisterTyped(d, "devices/#", AtLeastOnce, protoHandler), CodecMismatchError)
+	assert.Len(t, d.subscribers, 3)
+}
@@ -6,13 +6,21 @@ package mqtt
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
+	"sync"
 	"syscall"
+	"time"
 
 	myQTT "github.com/eclipse/paho.mqtt.golang"
 	"github.com/ralvescosta/gokit/logging"
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/ralvescosta/gokit/messaging/cloudevents"
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
@@ -21,52 +29,114 @@ type (
 	// Dispatcher is an interface for managing MQTT subscriptions and consuming messages.
 	Dispatcher interface {
 		// Register adds a new subscription to the dispatcher with the specified topic, QoS, and handler.
-		// Returns an error if the topic is empty, the handler is nil, or the QoS is invalid.
-		Register(topic string, qos QoS, handler Handler) error
+		// The topic may contain "+" and "#" wildcards; the levels they match are passed to the handler.
+		// The middlewares are applied only to this handler, after the ones registered with Use.
+		// Returns an error if the topic is empty or malformed, the handler is nil, or the QoS is invalid.
+		Register(topic string, qos QoS, handler Handler, middlewares ...messaging.Middleware) error
+
+		// Use registers middlewares applied to every handler of the dispatcher. The middlewares receive
+		// the raw payload ([]byte) as message and a *messaging.Metadata, whose Raw field holds the paho mqtt.Message.
+		// Must be called before ConsumeBlocking.
+		Use(middlewares ...messaging.Middleware)
 
 		// ConsumeBlocking starts consuming messages for all registered subscriptions.
 		// Blocks until a signal is received on the provided channel, at which point it unsubscribes from all topics.
+		// Once consuming, all the registered topics are subscribed again whenever the client reconnects.
 		ConsumeBlocking()
 	}
 
 	subscription struct {
-		qos     QoS
-		topic   string
-		handler Handler
+		qos         QoS
+		topic       string
+		handler     Handler
+		middlewares []messaging.Middleware
+		chain       messaging.ConsumerHandler
+	}
+
+	// Handler is the function executed for each message received in a subscription.
+	// It receives the topic the message was published to, the QoS of the delivery, the raw payload
+	// and the topic levels matched by the subscription wildcards.
+	// The message is only acknowledged when the handler returns nil, unless the handler settles it
+	// through the metadata returned by MetadataFromContext.
+	Handler = func(ctx context.Context, topic string, qos QoS, payload []byte, params TopicParams) error
+
+	// acknowledger settles a message manually, implementing messaging.Acknowledger.
+	acknowledger struct {
+		logger  logging.Logger
+		message myQTT.Message
 	}
 
-	// Updated Handler type to include context.Context as the first argument.
-	Handler = func(ctx context.Context, topic string, qos QoS, payload []byte) error
+	metadataKey struct{}
+
+	// DispatcherOption configures the dispatcher created by NewDispatcher.
+	DispatcherOption func(d *mqttDispatcher)
 
 	// mqttDispatcher is the concrete implementation of the Dispatcher interface.
 	mqttDispatcher struct {
 		logger      logging.Logger
-		client      myQTT.Client
+		client      MQTTClient
 		subscribers []*subscription
+		middlewares []messaging.Middleware
 		signalCh    chan os.Signal
 		tracer      trace.Tracer
+		metrics     *messaging.ConsumerMetrics
+		// codecs holds the codec of the typed handlers by topic filter
+		codecs map[string]Codec
+		// cloudEvents enables the parsing of the structured CloudEvents payloads
+		cloudEvents bool
+
+		mu        sync.Mutex
+		consuming bool
 	}
 )
 
 // NewDispatcher initializes a new mqttDispatcher with the provided logger and MQTT client.
-func NewDispatcher(logger logging.Logger, client myQTT.Client) Dispatcher {
+// The dispatcher registers itself in the client to resubscribe its topics after a reconnection.
+func NewDispatcher(logger logging.Logger, client MQTTClient, opts ...DispatcherOption) Dispatcher {
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 
-	return &mqttDispatcher{
+	metrics, err := messaging.NewConsumerMetrics()
+	if err != nil {
+		logger.Warn(LogMessage("failure to create the consumer metrics"), zap.Error(err))
+	}
+
+	d := &mqttDispatcher{
 		logger:      logger,
 		client:      client,
 		subscribers: []*subscription{},
 		signalCh:    signalCh,
 		tracer:      otel.Tracer("gokit/mqtt"),
+		metrics:     metrics,
+		codecs:      map[string]Codec{},
+	}
+
+	for _, opt := range opts {
+		opt(d)
 	}
+
+	client.AddOnConnectHandler(d.onConnect)
+
+	return d
 }
 
-func (d *mqttDispatcher) Register(topic string, qos QoS, handler Handler) error {
+// WithCloudEvents parses the payloads as structured CloudEvents, published with the cloudevents.Interceptor
+// and the cloudevents.MQTT binding: the handlers receive the event data, and the event through the context.
+// Payloads that are not CloudEvents are passed as they are, and invalid CloudEvents are discarded.
+// Without this option the payloads are never parsed, so JSON payloads holding "specversion" reach the handlers intact.
+func WithCloudEvents() DispatcherOption {
+	return func(d *mqttDispatcher) { d.cloudEvents = true }
+}
+
+func (d *mqttDispatcher) Register(topic string, qos QoS, handler Handler, middlewares ...messaging.Middleware) error {
 	if topic == "" {
 		return EmptyTopicError
 	}
 
+	if !ValidateTopicFilter(topic) {
+		return InvalidTopicFilterError
+	}
+
 	if handler == nil {
 		return NillHandlerError
 	}
@@ -75,44 +145,267 @@ func (d *mqttDispatcher) Register(topic string, qos QoS, handler Handler) error
 		return InvalidQoSError
 	}
 
-	d.subscribers = append(d.subscribers, &subscription{qos, topic, handler})
+	d.subscribers = append(d.subscribers, &subscription{qos: qos, topic: topic, handler: handler, middlewares: middlewares})
 
 	return nil
 }
 
+func (d *mqttDispatcher) Use(middlewares ...messaging.Middleware) {
+	d.middlewares = append(d.middlewares, middlewares...)
+}
+
 func (d *mqttDispatcher) ConsumeBlocking() {
 	for _, s := range d.subscribers {
-		d.logger.Debug(LogMessage("subscribing to topic: ", s.topic))
-		d.client.Subscribe(s.topic, 1, d.defaultMessageHandler(s.handler))
+		s.chain = d.chain(s)
 	}
 
+	d.mu.Lock()
+	d.consuming = true
+	d.subscribeAll(d.client.Client())
+	d.mu.Unlock()
+
 	<-d.signalCh
 
 	d.logger.Warn(LogMessage("received stop signal, unsubscribing..."))
 
+	d.mu.Lock()
+	d.consuming = false
+	d.mu.Unlock()
+
 	for _, s := range d.subscribers {
 		d.logger.Warn(LogMessage("unsubscribing to topic: ", s.topic))
-		d.client.Unsubscribe(s.topic)
+
+		token := d.client.Client().Unsubscribe(s.topic)
+		if token.Wait() && token.Error() != nil {
+			d.logger.Error(LogMessage("failure to unsubscribe to topic: ", s.topic), zap.Error(token.Error()))
+		}
 	}
 
 	d.logger.Debug(LogMessage("stopping consumer..."))
 }
 
-// defaultMessageHandler wraps a Handler with additional functionality, such as tracing.
-func (d *mqttDispatcher) defaultMessageHandler(handler Handler) myQTT.MessageHandler {
+// checkCodec returns CodecMismatchError when a typed handler of a topic filter overlapping
+// the given one uses another codec, since the payloads carry no content type.
+func (d *mqttDispatcher) checkCodec(topic string, codec Codec) error {
+	for filter, registered := range d.codecs {
+		if registered.ContentType() != codec.ContentType() && TopicFiltersOverlap(filter, topic) {
+			return fmt.Errorf("%w: %s uses %s, %s uses %s", CodecMismatchError, filter, registered.ContentType(), topic, codec.ContentType())
+		}
+	}
+
+	return nil
+}
+
+// addCodec records the codec of a typed handler.
+func (d *mqttDispatcher) addCodec(topic string, codec Codec) {
+	d.codecs[topic] = codec
+}
+
+// chain adapts the subscription handler to a messaging.ConsumerHandler wrapped by the global
+// and subscription middlewares.
+func (d *mqttDispatcher) chain(s *subscription) messaging.ConsumerHandler {
+	handler := func(ctx context.Context, msg any, metadata *messaging.Metadata) error {
+		m := metadata.Raw.(myQTT.Message)
+		params, _ := MatchTopic(s.topic, m.Topic())
+
+		return s.handler(context.WithValue(ctx, metadataKey{}, metadata), m.Topic(), QoSFromBytes(m.Qos()), msg.([]byte), params)
+	}
+
+	middlewares := append(append([]messaging.Middleware{}, d.middlewares...), s.middlewares...)
+
+	return messaging.Chain(handler, middlewares...)
+}
+
+// onConnect subscribes again to all the registered topics when the client reconnects to the broker.
+// Brokers using clean sessions drop the subscriptions on disconnection, so they must be recreated.
+func (d *mqttDispatcher) onConnect(client myQTT.Client) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.consuming {
+		return
+	}
+
+	d.logger.Debug(LogMessage("connection reestablished, resubscribing topics..."))
+	d.subscribeAll(client)
+}
+
+// subscribeAll subscribes to all the registered topics, logging the subscriptions that fail.
+func (d *mqttDispatcher) subscribeAll(client myQTT.Client) {
+	for _, s := range d.subscribers {
+		d.logger.Debug(LogMessage("subscribing to topic: ", s.topic), zap.Int("qos", int(s.qos)))
+
+		if err := d.subscribe(client, s); err != nil {
+			d.logger.Error(LogMessage("failure to subscribe to topic: ", s.topic), zap.Error(err))
+		}
+	}
+}
+
+// subscribe subscribes to the subscription topic with its registered QoS and waits for the broker acknowledgment.
+// Returns an error if the subscribe request fails or if the broker rejects the subscription.
+func (d *mqttDispatcher) subscribe(client myQTT.Client, s *subscription) error {
+	token := client.Subscribe(s.topic, byte(s.qos), d.defaultMessageHandler(s))
+	if token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+
+	subToken, ok := token.(*myQTT.SubscribeToken)
+	if !ok {
+		return nil
+	}
+
+	// The broker answers with 0x80 in the SUBACK when the subscription is refused
+	if granted, ok := subToken.Result()[s.topic]; ok && granted == subscriptionFailureCode {
+		return SubscriptionRejectedError
+	}
+
+	return nil
+}
+
+// defaultMessageHandler wraps a subscription Handler with additional functionality, such as tracing,
+// topic parameter extraction and manual acknowledgment.
+// The message is acknowledged only if the handler succeeds, otherwise it is kept unacknowledged.
+// Paho does not redeliver an unacknowledged message while the connection is up: the broker only sends
+// it again after a reconnection, and only to persistent sessions (CleanSession disabled) with QoS 1 or 2.
+// With WithCloudEvents, the payloads holding structured CloudEvents are replaced by the event data.
+// The consumer metrics are labeled with the subscription topic filter,
+// and redelivered messages are counted as retried.
+func (d *mqttDispatcher) defaultMessageHandler(s *subscription) myQTT.MessageHandler {
 	return func(_ myQTT.Client, msg myQTT.Message) {
 		d.logger.Debug(LogMessage("received message from topic: ", msg.Topic()))
-		msg.Ack()
+
+		if _, ok := MatchTopic(s.topic, msg.Topic()); !ok {
+			d.logger.Warn(LogMessage("received message that does not match the subscription: ", s.topic), zap.String("topic", msg.Topic()))
+			msg.Ack()
+			return
+		}
 
 		// Create a new context with an OpenTelemetry span using the dispatcher tracer.
 		ctx, span := d.tracer.Start(context.Background(), msg.Topic())
 		defer span.End()
 
-		err := handler(ctx, msg.Topic(), QoSFromBytes(msg.Qos()), msg.Payload())
+		labels := messaging.ConsumerLabels{System: System, Topic: s.topic}
+		d.metrics.Received(ctx, labels)
+		if msg.Duplicate() {
+			d.metrics.Retried(ctx, labels)
+		}
+
+		var (
+			event   *cloudevents.Event
+			payload = msg.Payload()
+			err     error
+		)
+
+		// CloudEvents are published in structured mode, since MQTT 3.1.1 has no headers
+		if d.cloudEvents {
+			if event, payload, err = cloudevents.FromStructured(msg.Payload()); err != nil {
+				d.logger.Error(LogMessage("discarding invalid cloudevent"), zap.String("topic", msg.Topic()), zap.Error(err))
+				msg.Ack()
+				d.metrics.DeadLettered(ctx, labels)
+				return
+			}
+		}
+
+		metadata := newMetadata(msg, event).WithAcknowledger(&acknowledger{logger: d.logger, message: msg})
+		if event != nil {
+			ctx = cloudevents.NewContext(ctx, event)
+		}
+
+		done := d.metrics.Start(ctx, labels)
+		err = s.chain(ctx, payload, metadata)
+		done(err)
+
+		// Messages settled by the handler are not acknowledged again. The others are acknowledged
+		// through the metadata as well, so a handler still running in background, e.g. after a timeout,
+		// cannot acknowledge them a second time
+		if metadata.Settled() {
+			if err != nil {
+				span.RecordError(err)
+			}
+			return
+		}
+
 		if err != nil {
-			d.logger.Error(LogMessage("failure to execute the topic handler"), zap.Error(err))
+			span.RecordError(err)
+
+			// Payloads that cannot be decoded are discarded, redelivering them would never succeed
+			if errors.Is(err, InvalidPayloadError) {
+				d.logger.Error(LogMessage("discarding message with invalid payload"), zap.String("topic", msg.Topic()), zap.Error(err))
+				_ = metadata.Ack()
+				d.metrics.DeadLettered(ctx, labels)
+				return
+			}
+
+			d.logger.Error(LogMessage("failure to execute the topic handler"), zap.String("topic", msg.Topic()), zap.Error(err))
+			return
 		}
 
+		_ = metadata.Ack()
+		span.SetStatus(codes.Ok, "success")
 		d.logger.Debug(LogMessage("message processed successfully"))
 	}
 }
+
+// MetadataFromContext returns the metadata of the message being handled, used by the handlers
+// to read the delivery properties and to acknowledge the message manually.
+func MetadataFromContext(ctx context.Context) (*messaging.Metadata, bool) {
+	metadata, ok := ctx.Value(metadataKey{}).(*messaging.Metadata)
+	return metadata, ok
+}
+
+// newMetadata builds the metadata of a received message. MQTT 3.1.1 has no message properties,
+// so the message ID is the packet identifier, unless the message is a CloudEvent, and the
+// timestamp is the reception time. Duplicate deliveries are counted as a second attempt.
+func newMetadata(msg myQTT.Message, event *cloudevents.Event) *messaging.Metadata {
+	metadata := &messaging.Metadata{
+		MessageID: strconv.FormatUint(uint64(msg.MessageID()), 10),
+		Source:    msg.Topic(),
+		Timestamp: time.Now(),
+		Attempt:   1,
+		Headers:   map[string]string{},
+		Raw:       msg,
+	}
+
+	if msg.Duplicate() {
+		metadata.Attempt = 2
+	}
+
+	if event != nil {
+		metadata.MessageID = event.ID
+		metadata.Type = event.Type
+		if !event.Time.IsZero() {
+			metadata.Timestamp = event.Time
+		}
+	}
+
+	return metadata
+}
+
+// Ack acknowledges the message.
+func (a *acknowledger) Ack() error {
+	a.message.Ack()
+	return nil
+}
+
+// Nack acknowledges the message to discard it, since MQTT has no dead letter queue.
+// When requeued, the message is left unacknowledged instead, but MQTT has no negative acknowledgment:
+// the broker only delivers it again after a reconnection, and only to persistent sessions
+// (CleanSession disabled) with QoS 1 or 2. A warning is logged, since the message is otherwise not redelivered.
+func (a *acknowledger) Nack(requeue bool) error {
+	if !requeue {
+		a.message.Ack()
+		return nil
+	}
+
+	a.logger.Warn(
+		LogMessage("message left unacknowledged, it is only redelivered after a reconnection of a persistent session"),
+		zap.String("topic", a.message.Topic()),
+	)
+
+	return nil
+}
+
+// Defer returns messaging.AcknowledgmentNotSupportedError, MQTT has no delayed redelivery.
+func (a *acknowledger) Defer() error {
+	return messaging.AcknowledgmentNotSupportedError
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package mqtt
+
+import (
+	"context"
+	"testing"
+
+	myQTT "github.com/eclipse/paho.mqtt.golang"
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/ralvescosta/gokit/messaging/cloudevents"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+// fakeMessage is a received paho message counting its acknowledgments.
+type fakeMessage struct {
+	myQTT.Message
+
+	topic   string
+	payload []byte
+	acks    int
+}
+
+func (m *fakeMessage) Topic() string     { return m.topic }
+func (m *fakeMessage) Payload() []byte   { return m.payload }
+func (m *fakeMessage) Duplicate() bool   { return false }
+func (m *fakeMessage) MessageID() uint16 { return 1 }
+func (m *fakeMessage) Ack()              { m.acks++ }
+
+// received is what a subscription handler was called with.
+type received struct {
+	payload []byte
+	event   *cloudevents.Event
+}
+
+// newTestDispatcher creates a dispatcher, without client, with the given options.
+func newTestDispatcher(opts ...DispatcherOption) *mqttDispatcher {
+	d := &mqttDispatcher{logger: zap.NewNop(), tracer: otel.Tracer("test"), codecs: map[string]Codec{}}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// deliver registers a subscription on the topic and delivers the message to it.
+func deliver(d *mqttDispatcher, topic string, msg *fakeMessage) *received {
+	var got *received
+
+	s := &subscription{qos: AtLeastOnce, topic: topic}
+	s.chain = func(ctx context.Context, message any, _ *messaging.Metadata) error {
+		event, _ := cloudevents.FromContext(ctx)
+		got = &received{payload: message.([]byte), event: event}
+		return nil
+	}
+
+	d.defaultMessageHandler(s)(nil, msg)
+
+	return got
+}
+
+// TestDispatcherCloudEvents verifies that structured CloudEvents are only unwrapped with WithCloudEvents.
+func TestDispatcherCloudEvents(t *testing.T) {
+	event := &cloudevents.Event{ID: "1", Source: "devices", Type: "telemetry", SpecVersion: cloudevents.SpecVersion, DataContentType: JSONContentType}
+	payload, err := event.Structured([]byte(`{"value":1.5}`))
+	assert.NoError(t, err)
+
+	msg := &fakeMessage{topic: "devices/42/telemetry", payload: payload}
+	got := deliver(newTestDispatcher(), "devices/+/telemetry", msg)
+	assert.Equal(t, payload, got.payload)
+	assert.Nil(t, got.event)
+	assert.Equal(t, 1, msg.acks)
+
+	msg = &fakeMessage{topic: "devices/42/telemetry", payload: payload}
+	got = deliver(newTestDispatcher(WithCloudEvents()), "devices/+/telemetry", msg)
+	assert.JSONEq(t, `{"value":1.5}`, string(got.payload))
+	assert.Equal(t, "telemetry", got.event.Type)
+	assert.Equal(t, 1, msg.acks)
+
+	msg = &fakeMessage{topic: "devices/42/telemetry", payload: []byte(`{"specversion":"1.0"}`)}
+	assert.NotNil(t, deliver(newTestDispatcher(), "devices/+/telemetry", msg))
+
+	msg = &fakeMessage{topic: "devices/42/telemetry", payload: []byte(`{"specversion":"1.0"}`)}
+	assert.Nil(t, deliver(newTestDispatcher(WithCloudEvents()), "devices/+/telemetry", msg))
+	assert.Equal(t, 1, msg.acks)
+}
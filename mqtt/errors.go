@@ -30,4 +30,15 @@ var (
 	NillPayloadError = NewError("publish payload cannot be nil")
 	// InvalidQoSError indicates that the provided QoS value is invalid.
 	InvalidQoSError = NewError("qos must be one of: byte(0), byte(1), or byte(2)")
+	// InvalidTopicFilterError indicates that the subscription topic uses the "+" or "#" wildcards incorrectly.
+	InvalidTopicFilterError = NewError("subscribe topic has an invalid wildcard usage")
+	// SubscriptionRejectedError indicates that the broker refused the subscription request.
+	SubscriptionRejectedError = NewError("subscription rejected by the broker")
+	// InvalidPayloadError indicates that a received payload could not be decoded into the registered type.
+	InvalidPayloadError = NewError("received payload could not be decoded")
+	// CodecMismatchError indicates that a typed handler is registered on a topic filter overlapping the filter
+	// of a typed handler using another codec. MQTT 3.1.1 messages carry no content type, so a topic has one codec.
+	CodecMismatchError = NewError("typed handlers of overlapping topics must use the same codec")
+	// InvalidProtobufMessageError indicates that the protobuf codec received a value that is not a proto.Message.
+	InvalidProtobufMessageError = NewError("protobuf codec requires a proto.Message")
 )
@@ -16,6 +16,7 @@ require (
 	github.com/go-logr/stdr v1.2.2 // indirect
 	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
 	go.opentelemetry.io/otel/metric v1.35.0 // indirect
+	google.golang.org/protobuf v1.34.2
 )
 
 require (
@@ -24,7 +25,7 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/ralvescosta/gokit/messaging v0.0.0-20250423125402-05dd81b22867
 	github.com/stretchr/objx v0.5.2 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
+	github.com/stretchr/testify v1.10.0
 	go.uber.org/multierr v1.11.0 // indirect
 	golang.org/x/net v0.39.0 // indirect
 	golang.org/x/sync v0.13.0 // indirect
@@ -34,3 +35,5 @@ require (
 replace github.com/ralvescosta/gokit/configs => ../configs
 
 replace github.com/ralvescosta/gokit/logging => ../logging
+
+replace github.com/ralvescosta/gokit/messaging => ../messaging
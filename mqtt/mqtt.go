@@ -4,6 +4,9 @@
 
 package mqtt
 
+// System identifies MQTT in the "system" attribute of the consumer metrics.
+const System = "mqtt"
+
 // QoS represents the Quality of Service levels for MQTT messages.
 // It defines the reliability of message delivery between the client and broker.
 // - AtMostOnce: Messages are delivered at most once (0).
@@ -18,6 +21,9 @@ const (
 	AtLeastOnce QoS = 1
 	// ExactlyOnce represents QoS level 2.
 	ExactlyOnce QoS = 2
+
+	// subscriptionFailureCode is the SUBACK return code sent by the broker when a subscription fails.
+	subscriptionFailureCode byte = 0x80
 )
 
 // LogMessage formats and returns a log message with a consistent prefix for MQTT operations.
@@ -16,9 +16,13 @@ import (
 )
 
 // mqttPublisher is the concrete implementation of the messaging.Publisher interface.
+// Messages are encoded with the codec returned by CodecFor before being published:
+// protobuf messages are encoded as protobuf, raw payloads ([]byte and string) are sent
+// as they are and any other value is encoded as JSON.
 type mqttPublisher struct {
-	logger logging.Logger
-	client myQTT.Client
+	logger       logging.Logger
+	client       myQTT.Client
+	interceptors []messaging.PublishInterceptor
 }
 
 // NewPublisher creates a new instance of mqttPublisher.
@@ -26,47 +30,85 @@ func NewPublisher(configs *configs.Configs, client myQTT.Client) messaging.Publi
 	return &mqttPublisher{logger: configs.Logger, client: client}
 }
 
+type (
+	// qosKey is the envelope extension key holding the QoS of the message.
+	qosKey struct{}
+	// retainKey is the envelope extension key holding the retain flag of the message.
+	retainKey struct{}
+)
+
+// WithQoS sets the QoS level used to publish the message.
+func WithQoS(qos QoS) messaging.EnvelopeOption {
+	return func(e *messaging.Envelope) { e.SetExtension(qosKey{}, qos) }
+}
+
+// WithRetain sets whether the broker should retain the message.
+func WithRetain(retain bool) messaging.EnvelopeOption {
+	return func(e *messaging.Envelope) { e.SetExtension(retainKey{}, retain) }
+}
+
 // Refactored Publish method to align with messaging.Publisher interface
+// The "qos" and "retain" options are still supported; prefer WithQoS and WithRetain with PublishEnvelope.
 func (p *mqttPublisher) Publish(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
-	if to == nil || *to == "" {
+	return p.PublishEnvelope(ctx, messaging.EnvelopeFromOptions(to, from, key, options...), msg)
+}
+
+// Refactored PublishDeadline method to align with messaging.Publisher interface
+func (p *mqttPublisher) PublishDeadline(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	return p.PublishEnvelope(ctx, messaging.EnvelopeFromOptions(to, from, key, options...), msg)
+}
+
+// PublishEnvelope publishes the message to the envelope destination topic, waiting for the broker
+// acknowledgment until the context is done.
+// MQTT 3.1.1 has no message properties, so only the destination, QoS and retain flag are used.
+// Returns messaging.DelayNotSupportedError if the envelope has a delay.
+func (p *mqttPublisher) PublishEnvelope(ctx context.Context, envelope *messaging.Envelope, msg any) error {
+	if envelope == nil || envelope.To == "" {
 		return EmptyTopicError
 	}
 
-	topic := *to
-	qos := p.qosFromOptions(options...)
-	retain := p.retainFromOptions(options...)
+	if envelope.Delay > 0 {
+		return messaging.DelayNotSupportedError
+	}
+
+	topic := envelope.To
+	qos := p.qosFromEnvelope(envelope)
 
 	if err := p.validate(topic, qos, msg); err != nil {
 		p.logger.Error(LogMessage("validation error"), zap.String("topic", topic), zap.Error(err))
 		return err
 	}
 
-	token := p.client.Publish(topic, byte(qos), retain, msg)
-	if token.Wait() && token.Error() != nil {
-		return token.Error()
+	payload, contentType, err := p.encode(msg)
+	if err != nil {
+		return err
 	}
 
-	return nil
-}
-
-// Refactored PublishDeadline method to align with messaging.Publisher interface
-func (p *mqttPublisher) PublishDeadline(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
-	if to == nil || *to == "" {
-		return EmptyTopicError
+	envelope = envelope.Clone()
+	if envelope.ContentType == "" {
+		envelope.ContentType = contentType
 	}
 
-	topic := *to
-	qos := p.qosFromOptions(options...)
-	retain := p.retainFromOptions(options...)
+	publish := messaging.ChainPublish(p.send, p.interceptors...)
 
-	if err := p.validate(topic, qos, msg); err != nil {
-		p.logger.Error(LogMessage("validation error"), zap.String("topic", topic), zap.Error(err))
-	}
+	return publish(ctx, &messaging.OutgoingMessage{Envelope: envelope, Message: msg, Body: payload})
+}
 
-	ctx, cancel := context.WithTimeout(ctx, time.Second)
-	defer cancel()
+// Intercept registers interceptors executed for every published message.
+// MQTT 3.1.1 has no message properties, so changes to the envelope headers are not sent.
+func (p *mqttPublisher) Intercept(interceptors ...messaging.PublishInterceptor) {
+	p.interceptors = append(p.interceptors, interceptors...)
+}
 
-	token := p.client.Publish(topic, byte(qos), retain, msg)
+// send publishes the outgoing message body and waits for the broker acknowledgment until the context is done.
+func (p *mqttPublisher) send(ctx context.Context, out *messaging.OutgoingMessage) error {
+	qos := p.qosFromEnvelope(out.Envelope)
+	retain := p.retainFromEnvelope(out.Envelope)
+
+	token := p.client.Publish(out.Envelope.To, byte(qos), retain, out.Body)
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -99,33 +141,46 @@ func (p *mqttPublisher) validate(topic string, qos QoS, payload any) error {
 	return nil
 }
 
-func (p *mqttPublisher) qosFromOptions(options ...*messaging.Option) QoS {
-	for _, option := range options {
-		if option.Key == "qos" {
-			switch option.Value {
-			case "0":
-				return QoS(0)
-			case "1":
-				return QoS(1)
-			case "2":
-				return QoS(2)
-			default:
-				return QoS(0)
-			}
-		}
+// encode converts the message into the MQTT payload using the codec that matches the message type.
+// MQTT 3.1.1 has no message properties, so the content type does not travel with the message and
+// subscribers select the codec from the registered type (see RegisterTyped).
+func (p *mqttPublisher) encode(msg any) ([]byte, string, error) {
+	payload, contentType, err := EncodePayload(msg)
+	if err != nil {
+		p.logger.Error(LogMessage("failure to encode the payload"), zap.Error(err))
+		return nil, "", err
 	}
 
-	// Default QoS if not specified
-	return QoS(0)
+	return payload, contentType, nil
 }
 
-func (p *mqttPublisher) retainFromOptions(options ...*messaging.Option) bool {
-	for _, option := range options {
-		if option.Key == "retain" {
-			return option.Value == "true"
-		}
+// qosFromEnvelope returns the QoS set with WithQoS, falling back to the legacy "qos" option.
+func (p *mqttPublisher) qosFromEnvelope(envelope *messaging.Envelope) QoS {
+	if qos, ok := envelope.Extension(qosKey{}); ok {
+		return qos.(QoS)
+	}
+
+	qos, _ := envelope.LegacyOption("qos")
+
+	switch qos {
+	case "1":
+		return QoS(1)
+	case "2":
+		return QoS(2)
+	default:
+		// Default QoS if not specified
+		return QoS(0)
 	}
+}
+
+// retainFromEnvelope returns the retain flag set with WithRetain, falling back to the legacy "retain" option.
+func (p *mqttPublisher) retainFromEnvelope(envelope *messaging.Envelope) bool {
+	if retain, ok := envelope.Extension(retainKey{}); ok {
+		return retain.(bool)
+	}
+
+	retain, _ := envelope.LegacyOption("retain")
 
-	// Default retain value if not specified
-	return false
+	// Default retain value is false if not specified
+	return retain == "true"
 }
@@ -0,0 +1,120 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package mqtt
+
+import "strings"
+
+const (
+	// SingleLevelWildcard matches exactly one topic level (e.g. "devices/+/telemetry").
+	SingleLevelWildcard = "+"
+	// MultiLevelWildcard matches any number of trailing topic levels (e.g. "devices/#").
+	MultiLevelWildcard = "#"
+
+	topicSeparator    = "/"
+	sharedTopicPrefix = "$share"
+)
+
+// TopicParams holds the topic levels matched by the wildcards of a subscription filter,
+// in the order they appear in the filter. A "+" wildcard contributes the single level it
+// matched, while a trailing "#" contributes the remaining levels joined by "/".
+//
+// For the filter "devices/+/sensors/#" and the topic "devices/42/sensors/temp/celsius"
+// the params are ["42", "temp/celsius"].
+type TopicParams []string
+
+// Get returns the parameter at the given position, or an empty string if it does not exist.
+func (p TopicParams) Get(index int) string {
+	if index < 0 || index >= len(p) {
+		return ""
+	}
+
+	return p[index]
+}
+
+// ValidateTopicFilter checks if the provided subscription filter follows the MQTT wildcard rules:
+// "+" must occupy an entire level and "#" must occupy the entire last level.
+func ValidateTopicFilter(filter string) bool {
+	if filter == "" {
+		return false
+	}
+
+	levels := splitTopicFilter(filter)
+	for i, level := range levels {
+		if strings.Contains(level, MultiLevelWildcard) && (level != MultiLevelWildcard || i != len(levels)-1) {
+			return false
+		}
+
+		if strings.Contains(level, SingleLevelWildcard) && level != SingleLevelWildcard {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchTopic reports whether the topic matches the subscription filter and extracts the
+// levels matched by the filter wildcards. Shared subscription filters ("$share/group/...")
+// are matched against the filter without the share prefix.
+func MatchTopic(filter, topic string) (TopicParams, bool) {
+	levels := splitTopicFilter(filter)
+	topicLevels := strings.Split(topic, topicSeparator)
+	params := TopicParams{}
+
+	for i, level := range levels {
+		if level == MultiLevelWildcard {
+			// "#" also matches the parent level, e.g. "devices/#" matches "devices"
+			return append(params, strings.Join(topicLevels[i:], topicSeparator)), true
+		}
+
+		if i >= len(topicLevels) {
+			return nil, false
+		}
+
+		if level == SingleLevelWildcard {
+			params = append(params, topicLevels[i])
+			continue
+		}
+
+		if level != topicLevels[i] {
+			return nil, false
+		}
+	}
+
+	if len(levels) != len(topicLevels) {
+		return nil, false
+	}
+
+	return params, true
+}
+
+// TopicFiltersOverlap reports whether a topic can match both subscription filters,
+// e.g. "devices/+/telemetry" and "devices/42/#". Shared subscription prefixes are ignored.
+func TopicFiltersOverlap(a, b string) bool {
+	aLevels, bLevels := splitTopicFilter(a), splitTopicFilter(b)
+
+	for i := 0; ; i++ {
+		if i < len(aLevels) && aLevels[i] == MultiLevelWildcard || i < len(bLevels) && bLevels[i] == MultiLevelWildcard {
+			return true
+		}
+
+		if i == len(aLevels) || i == len(bLevels) {
+			return len(aLevels) == len(bLevels)
+		}
+
+		if aLevels[i] != bLevels[i] && aLevels[i] != SingleLevelWildcard && bLevels[i] != SingleLevelWildcard {
+			return false
+		}
+	}
+}
+
+// splitTopicFilter splits a filter into its levels, removing the shared subscription prefix.
+func splitTopicFilter(filter string) []string {
+	levels := strings.Split(filter, topicSeparator)
+	if levels[0] == sharedTopicPrefix && len(levels) > 2 {
+		return levels[2:]
+	}
+
+	return levels
+}
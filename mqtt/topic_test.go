@@ -0,0 +1,61 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package mqtt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMatchTopic verifies that MatchTopic applies the MQTT wildcard rules
+// and extracts the levels matched by each wildcard.
+func TestMatchTopic(t *testing.T) {
+	params, ok := MatchTopic("devices/+/telemetry", "devices/42/telemetry")
+	assert.True(t, ok)
+	assert.Equal(t, TopicParams{"42"}, params)
+
+	params, ok = MatchTopic("devices/+/sensors/#", "devices/42/sensors/temp/celsius")
+	assert.True(t, ok)
+	assert.Equal(t, TopicParams{"42", "temp/celsius"}, params)
+
+	params, ok = MatchTopic("$share/group/devices/+", "devices/7")
+	assert.True(t, ok)
+	assert.Equal(t, "7", params.Get(0))
+
+	params, ok = MatchTopic("devices/status", "devices/status")
+	assert.True(t, ok)
+	assert.Empty(t, params)
+
+	_, ok = MatchTopic("devices/+/telemetry", "devices/42/status")
+	assert.False(t, ok)
+
+	_, ok = MatchTopic("devices/+", "devices/42/telemetry")
+	assert.False(t, ok)
+
+	_, ok = MatchTopic("devices/+/telemetry", "devices")
+	assert.False(t, ok)
+}
+
+// TestValidateTopicFilter verifies that malformed wildcard filters are rejected.
+func TestValidateTopicFilter(t *testing.T) {
+	assert.True(t, ValidateTopicFilter("devices/+/telemetry"))
+	assert.True(t, ValidateTopicFilter("devices/#"))
+	assert.True(t, ValidateTopicFilter("#"))
+	assert.False(t, ValidateTopicFilter(""))
+	assert.False(t, ValidateTopicFilter("devices/#/telemetry"))
+	assert.False(t, ValidateTopicFilter("devices/dev+/telemetry"))
+	assert.False(t, ValidateTopicFilter("devices/status#"))
+}
+
+// TestTopicFiltersOverlap verifies the filters matching a common topic are detected.
+func TestTopicFiltersOverlap(t *testing.T) {
+	assert.True(t, TopicFiltersOverlap("devices/42/telemetry", "devices/42/telemetry"))
+	assert.True(t, TopicFiltersOverlap("devices/+/telemetry", "devices/42/#"))
+	assert.True(t, TopicFiltersOverlap("devices/#", "devices"))
+	assert.True(t, TopicFiltersOverlap("$share/group/devices/+", "devices/42"))
+	assert.False(t, TopicFiltersOverlap("devices/+/telemetry", "devices/42/status"))
+	assert.False(t, TopicFiltersOverlap("devices/+", "devices/42/telemetry"))
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package mqtt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/ralvescosta/gokit/messaging/cloudevents"
+)
+
+type (
+	// MessageMetadata contains information about a received message that is delivered
+	// to typed handlers alongside the decoded payload. It embeds the messaging.Metadata
+	// passed to the dispatcher middlewares, used to acknowledge the message manually.
+	MessageMetadata struct {
+		*messaging.Metadata
+
+		// Topic is the topic the message was published to
+		Topic string
+		// QoS is the quality of service of the delivery
+		QoS QoS
+		// Params holds the topic levels matched by the subscription wildcards
+		Params TopicParams
+		// ContentType is the MIME type of the codec used to decode the payload
+		ContentType string
+		// CloudEvent holds the attributes of the messages published as CloudEvents, nil otherwise
+		CloudEvent *cloudevents.Event
+	}
+
+	// TypedHandler is a message handler that receives the payload already decoded into T.
+	TypedHandler[T any] func(ctx context.Context, msg T, metadata *MessageMetadata) error
+
+	// codecRegistry is implemented by the dispatchers checking the codecs of the typed handlers.
+	codecRegistry interface {
+		checkCodec(topic string, codec Codec) error
+		addCodec(topic string, codec Codec)
+	}
+)
+
+// RegisterTyped registers a subscription whose payloads are automatically decoded into T
+// before the handler is executed. Protobuf messages are decoded with ProtobufCodec and any
+// other type with JSONCodec, mirroring the encoding applied by the MQTT publisher.
+//
+// Payloads that cannot be decoded are acknowledged and discarded by the dispatcher,
+// since redelivering them would never succeed.
+//
+// MQTT 3.1.1 messages carry no content type, so the codec cannot be chosen per message: every
+// typed handler of a topic must use the same codec, as must its publishers. Registering a handler
+// on a topic filter overlapping the filter of a handler with another codec, e.g. a protobuf handler
+// on "devices/+/telemetry" and a JSON handler on "devices/#", returns CodecMismatchError.
+//
+// Example:
+//
+//	err := mqtt.RegisterTyped(dispatcher, "devices/+/telemetry", mqtt.AtLeastOnce,
+//		func(ctx context.Context, msg *Telemetry, metadata *mqtt.MessageMetadata) error {
+//			return nil
+//		},
+//	)
+//
+// The middlewares are applied only to this handler, as in Dispatcher.Register.
+func RegisterTyped[T any](dispatcher Dispatcher, topic string, qos QoS, handler TypedHandler[T], middlewares ...messaging.Middleware) error {
+	if handler == nil {
+		return NillHandlerError
+	}
+
+	codec := codecForType[T]()
+
+	registry, checked := dispatcher.(codecRegistry)
+	if checked {
+		if err := registry.checkCodec(topic, codec); err != nil {
+			return err
+		}
+	}
+
+	err := dispatcher.Register(topic, qos, func(ctx context.Context, topic string, qos QoS, payload []byte, params TopicParams) error {
+		msg, err := decodePayload[T](codec, payload)
+		if err != nil {
+			return fmt.Errorf("%w: %s", InvalidPayloadError, err.Error())
+		}
+
+		event, _ := cloudevents.FromContext(ctx)
+		metadata, _ := MetadataFromContext(ctx)
+
+		return handler(ctx, msg, &MessageMetadata{
+			Metadata:    metadata,
+			Topic:       topic,
+			QoS:         qos,
+			Params:      params,
+			ContentType: codec.ContentType(),
+			CloudEvent:  event,
+		})
+	}, middlewares...)
+	if err != nil {
+		return err
+	}
+
+	if checked {
+		registry.addCodec(topic, codec)
+	}
+
+	return nil
+}
@@ -7,15 +7,19 @@ package rabbitmq
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
 	"reflect"
 	"syscall"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 	"github.com/ralvescosta/gokit/configs"
 	"github.com/ralvescosta/gokit/logging"
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/ralvescosta/gokit/messaging/cloudevents"
 	"github.com/ralvescosta/gokit/tracing"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/codes"
@@ -29,8 +33,13 @@ type (
 	Dispatcher interface {
 		// Register associates a queue with a message type and a handler function.
 		// It ensures that messages from the specified queue are processed by the handler.
+		// The middlewares are applied only to this handler, after the ones registered with Use.
 		// Returns an error if the registration parameters are invalid or if the queue definition is not found.
-		Register(queue string, typE any, handler ConsumerHandler) error
+		Register(queue string, typE any, handler ConsumerHandler, middlewares ...messaging.Middleware) error
+
+		// Use registers middlewares applied to every handler of the dispatcher.
+		// Must be called before ConsumeBlocking.
+		Use(middlewares ...messaging.Middleware)
 
 		// ConsumeBlocking starts consuming messages and dispatches them to the registered handlers.
 		// This method blocks execution until the process is terminated by a signal.
@@ -44,14 +53,17 @@ type (
 		channel             AMQPChannel
 		queueDefinitions    map[string]*QueueDefinition
 		consumersDefinition map[string]*ConsumerDefinition
+		middlewares         []messaging.Middleware
 		tracer              trace.Tracer
+		metrics             *messaging.ConsumerMetrics
 		signalCh            chan os.Signal
 	}
 
 	// ConsumerHandler is a function type that defines message handler callbacks.
-	// It receives a context (for tracing), the unmarshaled message, and metadata about the delivery.
+	// It receives a context (for tracing), the unmarshaled message, and metadata about the delivery,
+	// whose Raw field holds the *amqp091.Delivery.
 	// Returns an error if the message processing fails.
-	ConsumerHandler = func(ctx context.Context, msg any, metadata any) error
+	ConsumerHandler = messaging.ConsumerHandler
 
 	// ConsumerDefinition represents the configuration for a consumer.
 	// It holds information about the queue, message type, and handler function.
@@ -61,15 +73,15 @@ type (
 		reflect         *reflect.Value
 		queueDefinition *QueueDefinition
 		handler         ConsumerHandler
+		middlewares     []messaging.Middleware
+		chain           ConsumerHandler
 	}
 
-	// deliveryMetadata contains metadata extracted from an AMQP delivery.
-	// This includes message ID, retry count, message type, and headers.
-	deliveryMetadata struct {
-		MessageId string
-		XCount    int64
-		Type      string
-		Headers   map[string]interface{}
+	// acknowledger settles a delivery manually, implementing messaging.Acknowledger.
+	acknowledger struct {
+		dispatcher *dispatcher
+		definition *ConsumerDefinition
+		delivery   *amqp.Delivery
 	}
 )
 
@@ -79,20 +91,30 @@ func NewDispatcher(cfgs *configs.Configs, channel AMQPChannel, queueDefinitions
 	signalCh := make(chan os.Signal, 1)
 	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM, syscall.SIGQUIT)
 
+	metrics, err := messaging.NewConsumerMetrics()
+	if err != nil {
+		cfgs.Logger.Warn(LogMessage("failure to create the consumer metrics"), zap.Error(err))
+	}
+
 	return &dispatcher{
 		logger:              cfgs.Logger,
 		channel:             channel,
 		queueDefinitions:    queueDefinitions,
 		consumersDefinition: map[string]*ConsumerDefinition{},
 		tracer:              otel.Tracer("rmq-dispatcher"),
+		metrics:             metrics,
 		signalCh:            signalCh,
 	}
 }
 
 // Register associates a queue with a message type and a handler function.
 // It validates the parameters and ensures that the queue definition exists.
+// The message type is named by messaging.TypeName, so the types registered in the
+// messaging.DefaultTypeRegistry are routed by their logical name and its aliases.
+// Messages registered with cloudevents.OfType receive the CloudEvents of the queue with that type.
+// The middlewares are applied only to this handler, after the ones registered with Use.
 // Returns an error if the registration parameters are invalid or if the queue definition is not found.
-func (d *dispatcher) Register(queue string, msg any, handler ConsumerHandler) error {
+func (d *dispatcher) Register(queue string, msg any, handler ConsumerHandler, middlewares ...messaging.Middleware) error {
 	if msg == nil || queue == "" {
 		return InvalidDispatchParamsError
 	}
@@ -102,8 +124,17 @@ func (d *dispatcher) Register(queue string, msg any, handler ConsumerHandler) er
 		return QueueDefinitionNotFoundError
 	}
 
+	msgType := messaging.TypeName(msg)
+	if typed, ok := msg.(*cloudevents.TypedMessage); ok {
+		if typed.Message == nil || typed.Type == "" {
+			return InvalidDispatchParamsError
+		}
+
+		msg = typed.Message
+		msgType = typed.Type
+	}
+
 	ref := reflect.New(reflect.TypeOf(msg))
-	msgType := fmt.Sprintf("%T", msg)
 
 	d.consumersDefinition[msgType] = &ConsumerDefinition{
 		queue:           queue,
@@ -111,15 +142,22 @@ func (d *dispatcher) Register(queue string, msg any, handler ConsumerHandler) er
 		reflect:         &ref,
 		queueDefinition: def,
 		handler:         handler,
+		middlewares:     middlewares,
 	}
 
 	return nil
 }
 
+// Use registers middlewares applied to every handler of the dispatcher.
+func (d *dispatcher) Use(middlewares ...messaging.Middleware) {
+	d.middlewares = append(d.middlewares, middlewares...)
+}
+
 // ConsumeBlocking starts consuming messages from all registered queues.
 // It creates a goroutine for each consumer and blocks until a termination signal is received.
 func (d *dispatcher) ConsumeBlocking() {
 	for _, cd := range d.consumersDefinition {
+		cd.chain = messaging.Chain(cd.handler, append(append([]messaging.Middleware{}, d.middlewares...), cd.middlewares...)...)
 		go d.consume(cd.queue, cd.msgType)
 	}
 
@@ -128,7 +166,8 @@ func (d *dispatcher) ConsumeBlocking() {
 }
 
 // consume starts consuming messages from a specific queue.
-// It handles message unmarshaling, error handling, retries, and dead-letter queuing.
+// It handles message unmarshaling, error handling, retries, and dead-letter queuing,
+// recording the consumer metrics of every delivery.
 func (d *dispatcher) consume(queue, msgType string) {
 	delivery, err := d.channel.Consume(queue, msgType, false, false, false, false, nil)
 	if err != nil {
@@ -141,24 +180,48 @@ func (d *dispatcher) consume(queue, msgType string) {
 	}
 
 	for received := range delivery {
-		metadata, err := d.extractMetadata(&received)
+		labels := messaging.ConsumerLabels{System: System, Queue: queue, Consumer: msgType, Type: received.Type}
+		d.metrics.Received(context.Background(), labels)
+
+		event, body, err := cloudevents.Parse(cloudevents.AMQP, stringHeaders(received.Headers), received.ContentType, received.Body)
+		if err != nil {
+			d.logger.Error(LogMessage("invalid cloudevent"), zap.String("messageId", received.MessageId), zap.Error(err))
+			_ = received.Ack(false)
+			d.metrics.DeadLettered(context.Background(), labels)
+			continue
+		}
+
+		metadata, err := d.extractMetadata(queue, &received, event)
 		if err != nil {
 			_ = received.Ack(false)
+			d.metrics.DeadLettered(context.Background(), labels)
 			continue
 		}
 
 		d.logger.Debug(
 			LogMessage("received message: ", metadata.Type),
-			zap.String("messageId", metadata.MessageId),
+			zap.String("messageId", metadata.MessageID),
 		)
 
-		def, ok := d.consumersDefinition[msgType]
+		// Deliveries are routed by their type, or the event type of CloudEvents, resolving
+		// the registered aliases, when a handler of the queue is registered for it
+		routingType := metadata.Type
+		if event != nil {
+			routingType = event.Type
+		}
+
+		consumerType := msgType
+		if def, ok := d.consumersDefinition[messaging.ResolveTypeName(routingType)]; ok && def.queue == queue {
+			consumerType = def.msgType
+		}
+
+		def, ok := d.consumersDefinition[consumerType]
 
 		if !ok {
 			d.logger.Warn(
 				LogMessage("could not find any consumer for this msg type"),
 				zap.String("type", metadata.Type),
-				zap.String("messageId", metadata.MessageId),
+				zap.String("messageId", metadata.MessageID),
 			)
 			if err := received.Ack(false); err != nil {
 				d.logger.Error(
@@ -166,13 +229,17 @@ func (d *dispatcher) consume(queue, msgType string) {
 					zap.String("messageId", received.MessageId),
 				)
 			}
+			d.metrics.DeadLettered(context.Background(), labels)
 			continue
 		}
 
-		ctx, span := tracing.NewConsumerSpan(d.tracer, received.Headers, received.Type)
+		ctx, span := tracing.NewConsumerSpan(d.tracer, received.Headers, metadata.Type)
+		if event != nil {
+			ctx = cloudevents.NewContext(ctx, event)
+		}
 
 		ptr := def.reflect.Interface()
-		if err = json.Unmarshal(received.Body, ptr); err != nil {
+		if err = json.Unmarshal(body, ptr); err != nil {
 			span.RecordError(err)
 			d.logger.Error(
 				LogMessage("unmarshal error"),
@@ -180,11 +247,12 @@ func (d *dispatcher) consume(queue, msgType string) {
 				tracing.Format(ctx),
 			)
 			_ = received.Nack(true, false)
+			d.metrics.DeadLettered(ctx, labels)
 			span.End()
 			continue
 		}
 
-		if def.queueDefinition.withRetry && metadata.XCount > def.queueDefinition.retires {
+		if def.queueDefinition.withRetry && int64(metadata.Attempt-1) > def.queueDefinition.retires {
 			d.logger.Warn(
 				LogMessage("message reprocessed to many times, sending to dead letter"),
 				tracing.Format(ctx),
@@ -200,20 +268,44 @@ func (d *dispatcher) consume(queue, msgType string) {
 				)
 			}
 
+			d.metrics.DeadLettered(ctx, labels)
+			span.End()
+			continue
+		}
+
+		metadata.WithAcknowledger(&acknowledger{dispatcher: d, definition: def, delivery: &received})
+
+		done := d.metrics.Start(ctx, labels)
+		err = def.chain(ctx, ptr, metadata)
+		done(err)
+
+		// Deliveries settled by the handler are not acknowledged again. The dispatcher settles
+		// the others through the metadata as well, so a handler still running in background,
+		// e.g. after a timeout, cannot acknowledge them a second time
+		if metadata.Settled() {
+			if err != nil {
+				span.RecordError(err)
+			}
 			span.End()
 			continue
 		}
 
-		if err = def.handler(ctx, ptr, metadata); err != nil {
+		if err != nil {
 			d.logger.Error(
 				LogMessage("error to process message"),
 				zap.Error(err),
 				tracing.Format(ctx),
 			)
 
-			if def.queueDefinition.withDLQ || err != RetryableError {
+			if def.queueDefinition.withDLQ || !errors.Is(err, RetryableError) {
 				span.RecordError(err)
-				_ = received.Ack(false)
+
+				// The handler may still settle the delivery, e.g. after a timeout, so it is
+				// only published to the dead letter queue when this acknowledgment wins
+				if err = metadata.Ack(); err != nil {
+					span.End()
+					continue
+				}
 
 				if err = d.publishToDlq(def, &received); err != nil {
 					span.RecordError(err)
@@ -224,6 +316,7 @@ func (d *dispatcher) consume(queue, msgType string) {
 					)
 				}
 
+				d.metrics.DeadLettered(ctx, labels)
 				span.End()
 				continue
 			}
@@ -233,23 +326,43 @@ func (d *dispatcher) consume(queue, msgType string) {
 				tracing.Format(ctx),
 			)
 
-			_ = received.Nack(false, false)
+			_ = metadata.Nack(false)
+			d.metrics.Retried(ctx, labels)
 			span.End()
 			continue
 		}
 
 		d.logger.Debug(LogMessage("message processed properly"), zap.String("messageId", received.MessageId), tracing.Format(ctx))
-		_ = received.Ack(true)
+		_ = metadata.Ack()
 		span.SetStatus(codes.Ok, "success")
 		span.End()
 	}
 }
 
 // extractMetadata extracts relevant metadata from an AMQP delivery.
-// This includes the message ID, type, and retry count.
+// This includes the message ID, type, timestamp and the delivery attempt, counted from the x-death header.
+// CloudEvents deliveries without the AMQP type, ID or timestamp use the event attributes.
 // Returns an error if the message has unformatted headers.
-func (d *dispatcher) extractMetadata(delivery *amqp.Delivery) (*deliveryMetadata, error) {
+func (d *dispatcher) extractMetadata(queue string, delivery *amqp.Delivery, event *cloudevents.Event) (*messaging.Metadata, error) {
 	typ := delivery.Type
+	messageID := delivery.MessageId
+	timestamp := delivery.Timestamp
+	if event != nil {
+		if typ == "" {
+			typ = event.Type
+		}
+		if messageID == "" {
+			messageID = event.ID
+		}
+		if timestamp.IsZero() {
+			timestamp = event.Time
+		}
+	}
+
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
 	if typ == "" {
 		d.logger.Error(
 			LogMessage("unformatted amqp delivery - missing type parameter"),
@@ -266,14 +379,68 @@ func (d *dispatcher) extractMetadata(delivery *amqp.Delivery) (*deliveryMetadata
 		xCount = count
 	}
 
-	return &deliveryMetadata{
-		MessageId: delivery.MessageId,
-		Type:      typ,
-		XCount:    xCount,
-		Headers:   delivery.Headers,
+	return &messaging.Metadata{
+		MessageID:     messageID,
+		CorrelationID: delivery.CorrelationId,
+		Type:          typ,
+		Source:        queue,
+		Timestamp:     timestamp,
+		Attempt:       int(xCount) + 1,
+		Headers:       stringHeaders(delivery.Headers),
+		Raw:           delivery,
 	}, nil
 }
 
+// Ack acknowledges the delivery.
+func (a *acknowledger) Ack() error {
+	return a.delivery.Ack(false)
+}
+
+// Nack requeues the delivery, or sends it to the dead letter queue of the queue, when it has one.
+func (a *acknowledger) Nack(requeue bool) error {
+	if requeue || !a.definition.queueDefinition.withDLQ {
+		return a.delivery.Nack(false, requeue)
+	}
+
+	if err := a.dispatcher.publishToDlq(a.definition, a.delivery); err != nil {
+		return err
+	}
+
+	return a.delivery.Ack(false)
+}
+
+// Defer rejects the delivery to the retry queue, which delivers it again after the retry TTL.
+// Returns messaging.AcknowledgmentNotSupportedError if the queue was declared without retries.
+func (a *acknowledger) Defer() error {
+	if !a.definition.queueDefinition.withRetry {
+		return messaging.AcknowledgmentNotSupportedError
+	}
+
+	return a.delivery.Nack(false, false)
+}
+
+// stringHeaders returns the AMQP headers as strings. Timestamps are formatted as RFC 3339, as the
+// CloudEvents time attribute, byte arrays as their content and the other values with fmt.Sprint.
+// Void (nil) values are skipped.
+func stringHeaders(headers amqp.Table) map[string]string {
+	values := make(map[string]string, len(headers))
+	for k, v := range headers {
+		switch value := v.(type) {
+		case nil:
+		case string:
+			values[k] = value
+		case []byte:
+			values[k] = string(value)
+		case time.Time:
+			values[k] = value.UTC().Format(time.RFC3339Nano)
+		default:
+			values[k] = fmt.Sprint(value)
+		}
+	}
+
+	return values
+}
+
 // publishToDlq publishes a message to the dead-letter queue.
 // It preserves the original message properties and headers.
 func (m *dispatcher) publishToDlq(definition *ConsumerDefinition, received *amqp.Delivery) error {
@@ -0,0 +1,130 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package rabbitmq
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/ralvescosta/gokit/messaging/middlewares"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type (
+	DispatcherTestSuite struct {
+		suite.Suite
+
+		channel      *consumerChannelMock
+		acknowledger *acknowledgerMock
+		dispatcher   *dispatcher
+	}
+
+	// consumerChannelMock delivers the given deliveries to the consumers.
+	consumerChannelMock struct {
+		channelMock
+
+		deliveries chan amqp.Delivery
+	}
+
+	// acknowledgerMock records the settlements of the deliveries.
+	acknowledgerMock struct {
+		mu      sync.Mutex
+		acks    int
+		nacks   int
+		settled chan struct{}
+	}
+)
+
+func TestDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}
+
+func (s *DispatcherTestSuite) SetupTest() {
+	s.channel = &consumerChannelMock{deliveries: make(chan amqp.Delivery, 1)}
+	s.acknowledger = &acknowledgerMock{settled: make(chan struct{}, 1)}
+	s.dispatcher = NewDispatcher(
+		&configs.Configs{Logger: zap.NewNop()},
+		s.channel,
+		map[string]*QueueDefinition{"orders": NewQueue("orders").WithDQL()},
+	)
+}
+
+func (s *DispatcherTestSuite) TestLateAckAfterTimeout() {
+	late := make(chan error, 1)
+	handler := func(ctx context.Context, _ any, metadata *messaging.Metadata) error {
+		<-ctx.Done()
+
+		// Acknowledges once the dispatcher settled the timed out delivery
+		<-s.acknowledger.settled
+		late <- metadata.Ack()
+		return nil
+	}
+
+	s.Require().NoError(s.dispatcher.Register("orders", order{}, handler, middlewares.Timeout(10*time.Millisecond)))
+	def := s.dispatcher.consumersDefinition[messaging.TypeName(order{})]
+	def.chain = messaging.Chain(def.handler, def.middlewares...)
+
+	s.channel.deliveries <- amqp.Delivery{Acknowledger: s.acknowledger, Type: def.msgType, Body: []byte(`{"id":"1"}`)}
+	close(s.channel.deliveries)
+	s.dispatcher.consume("orders", def.msgType)
+
+	s.ErrorIs(<-late, messaging.AlreadySettledError)
+	s.Equal(1, s.acknowledger.acks)
+	s.Zero(s.acknowledger.nacks)
+	s.Require().Len(s.channel.publishing, 1)
+}
+
+func (s *DispatcherTestSuite) TestStringHeaders() {
+	headers := stringHeaders(amqp.Table{
+		"tenant":           "acme",
+		"x-count":          int64(2),
+		"x-retry":          true,
+		"x-ratio":          1.5,
+		"x-raw":            []byte("raw"),
+		"cloudEvents:time": time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		"x-void":           nil,
+	})
+
+	s.Equal(map[string]string{
+		"tenant":           "acme",
+		"x-count":          "2",
+		"x-retry":          "true",
+		"x-ratio":          "1.5",
+		"x-raw":            "raw",
+		"cloudEvents:time": "2024-01-01T12:00:00Z",
+	}, headers)
+}
+
+func (c *consumerChannelMock) Consume(string, string, bool, bool, bool, bool, amqp.Table) (<-chan amqp.Delivery, error) {
+	return c.deliveries, nil
+}
+
+func (a *acknowledgerMock) Ack(uint64, bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.acks++
+	a.settled <- struct{}{}
+	return nil
+}
+
+func (a *acknowledgerMock) Nack(uint64, bool, bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nacks++
+	a.settled <- struct{}{}
+	return nil
+}
+
+func (a *acknowledgerMock) Reject(uint64, bool) error {
+	return a.Nack(0, false, false)
+}
@@ -4,6 +4,8 @@
 
 package rabbitmq
 
+import "github.com/ralvescosta/gokit/messaging"
+
 // RabbitMQError represents a custom error type for RabbitMQ-related operations.
 // It encapsulates an error message describing the specific error condition.
 type RabbitMQError struct {
@@ -44,5 +46,6 @@ var (
 	ReceivedMessageWithUnformattedHeaderError = NewRabbitMQError("received message with unformatted headers")
 
 	// RetryableError indicates that a message processing failed but can be retried later.
-	RetryableError = NewRabbitMQError("error to process this message, retry latter")
+	// It is the same error used by the other messaging dispatchers.
+	RetryableError = messaging.RetryableError
 )
@@ -34,8 +34,16 @@ var (
 	// DirectExchange represents a direct exchange type.
 	// Direct exchanges route messages to queues based on a matching routing key.
 	DirectExchange ExchangeKind = "direct"
+
+	// DelayedExchange represents the exchange type provided by the rabbitmq_delayed_message_exchange plugin.
+	// Delayed exchanges hold the messages published with a delay until it expires, and then route them
+	// as the exchange type defined in the x-delayed-type argument.
+	DelayedExchange ExchangeKind = "x-delayed-message"
 )
 
+// DelayHeader is the header read by delayed exchanges holding the delivery delay in milliseconds.
+const DelayHeader = "x-delay"
+
 // NewDirectExchange creates a new direct exchange definition with the given name.
 // Direct exchanges route messages to queues based on exact matching of routing keys.
 func NewDirectExchange(name string) *ExchangeDefinition {
@@ -48,6 +56,15 @@ func NewFanoutExchange(name string) *ExchangeDefinition {
 	return defaultExchange(name, FanoutExchange)
 }
 
+// NewDelayedExchange creates a new delayed exchange definition with the given name, routing
+// the messages as the given kind once their delay expires. The broker must have the
+// rabbitmq_delayed_message_exchange plugin enabled.
+func NewDelayedExchange(name string, kind ExchangeKind) *ExchangeDefinition {
+	e := defaultExchange(name, DelayedExchange)
+	e.params = map[string]any{"x-delayed-type": kind.String()}
+	return e
+}
+
 // NewDirectExchanges creates multiple direct exchange definitions from a list of names.
 // This is a convenience function for creating multiple direct exchanges at once.
 func NewDirectExchanges(names []string) []*ExchangeDefinition {
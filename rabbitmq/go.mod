@@ -8,6 +8,7 @@ require (
 	github.com/ralvescosta/gokit/logging v1.20.0
 	github.com/ralvescosta/gokit/messaging v0.0.0-20250423125402-05dd81b22867
 	github.com/ralvescosta/gokit/tracing v1.20.0
+	github.com/stretchr/testify v1.10.0
 	go.opentelemetry.io/otel v1.27.0
 	go.opentelemetry.io/otel/trace v1.27.0
 	go.uber.org/zap v1.27.0
@@ -22,7 +23,6 @@ require (
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rabbitmq/amqp091-go v1.10.0
 	github.com/stretchr/objx v0.5.2 // indirect
-	github.com/stretchr/testify v1.10.0 // indirect
 	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.27.0 // indirect
 	go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc v1.27.0 // indirect
 	go.opentelemetry.io/otel/metric v1.27.0 // indirect
@@ -44,3 +44,5 @@ replace github.com/ralvescosta/gokit/configs => ../configs
 replace github.com/ralvescosta/gokit/logging => ../logging
 
 replace github.com/ralvescosta/gokit/tracing => ../tracing
+
+replace github.com/ralvescosta/gokit/messaging => ../messaging
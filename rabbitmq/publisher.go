@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -23,10 +24,15 @@ type (
 	// publisher is the concrete implementation of the Publisher interface.
 	// It handles the details of marshaling messages, setting headers, and publishing to RabbitMQ.
 	publisher struct {
-		logger  logging.Logger
-		configs *configs.Configs
-		channel AMQPChannel
+		logger           logging.Logger
+		configs          *configs.Configs
+		channel          AMQPChannel
+		interceptors     []messaging.PublishInterceptor
+		delayedExchanges map[string]bool
 	}
+
+	// PublisherOption configures the publisher created by NewPublisher.
+	PublisherOption func(p *publisher)
 )
 
 // JsonContentType is the MIME type used for JSON message content.
@@ -35,68 +41,136 @@ const (
 )
 
 // NewPublisher creates a new publisher instance with the provided configuration and AMQP channel.
-func NewPublisher(configs *configs.Configs, channel AMQPChannel) messaging.Publisher {
-	return &publisher{configs.Logger, configs, channel}
+func NewPublisher(configs *configs.Configs, channel AMQPChannel, opts ...PublisherOption) messaging.Publisher {
+	p := &publisher{logger: configs.Logger, configs: configs, channel: channel, delayedExchanges: map[string]bool{}}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// WithDelayedExchanges declares the exchanges created with NewDelayedExchange, the only ones
+// the publisher sends the messages with a delay to.
+func WithDelayedExchanges(names ...string) PublisherOption {
+	return func(p *publisher) {
+		for _, name := range names {
+			p.delayedExchanges[name] = true
+		}
+	}
+}
+
+// persistentKey is the envelope extension key holding the persistent delivery mode flag.
+type persistentKey struct{}
+
+// WithPersistent publishes the message with the persistent delivery mode,
+// so it survives broker restarts when routed to durable queues.
+func WithPersistent() messaging.EnvelopeOption {
+	return func(e *messaging.Envelope) { e.SetExtension(persistentKey{}, true) }
 }
 
 // SimplePublish publishes a message directly to a target queue.
 // The exchange is left empty, which means the default exchange is used.
 func (p *publisher) SimplePublish(ctx context.Context, target string, msg any) error {
-	return p.publish(ctx, target, "", msg)
+	return p.publish(ctx, &messaging.Envelope{To: target}, msg)
 }
 
 // Refactored Publish method to align with messaging.Publisher interface
 func (p *publisher) Publish(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
-	if to == nil || *to == "" {
-		return fmt.Errorf("exchange cannot be empty")
-	}
-
-	exchange := *to
-	routingKey := ""
-	if key != nil {
-		routingKey = *key
-	}
-
-	return p.publish(ctx, exchange, routingKey, msg)
+	return p.PublishEnvelope(ctx, messaging.EnvelopeFromOptions(to, from, key, options...), msg)
 }
 
 // Refactored PublishDeadline method to align with messaging.Publisher interface
 func (p *publisher) PublishDeadline(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
-	if to == nil || *to == "" {
-		return fmt.Errorf("exchange cannot be empty")
-	}
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
 
-	exchange := *to
-	routingKey := ""
-	if key != nil {
-		routingKey = *key
+	return p.PublishEnvelope(ctx, messaging.EnvelopeFromOptions(to, from, key, options...), msg)
+}
+
+// PublishEnvelope publishes the message to the exchange defined in the envelope destination,
+// using the envelope key as routing key. The envelope headers and properties are mapped to
+// the AMQP message properties.
+//
+// Envelopes with a delay are only published to the exchanges given to WithDelayedExchanges,
+// any other exchange would route the message right away: messaging.DelayNotSupportedError is returned.
+func (p *publisher) PublishEnvelope(ctx context.Context, envelope *messaging.Envelope, msg any) error {
+	if envelope == nil || envelope.To == "" {
+		return fmt.Errorf("exchange cannot be empty")
 	}
 
-	ctx, cancel := context.WithTimeout(ctx, time.Second)
-	defer cancel()
+	return p.publish(ctx, envelope, msg)
+}
 
-	return p.publish(ctx, exchange, routingKey, msg)
+// Intercept registers interceptors executed for every published message, including SimplePublish.
+func (p *publisher) Intercept(interceptors ...messaging.PublishInterceptor) {
+	p.interceptors = append(p.interceptors, interceptors...)
 }
 
 // publish is the internal method that handles the details of publishing a message.
-// It marshals the message to JSON, sets headers for tracing, and publishes to RabbitMQ.
-func (p *publisher) publish(ctx context.Context, exchange, key string, msg any) error {
+// It marshals the message to JSON and executes the interceptors before sending it to RabbitMQ.
+func (p *publisher) publish(ctx context.Context, envelope *messaging.Envelope, msg any) error {
+	if envelope.Delay > 0 && !p.delayedExchanges[envelope.To] {
+		return fmt.Errorf("%w: %s is not a delayed exchange", messaging.DelayNotSupportedError, envelope.To)
+	}
+
 	byt, err := json.Marshal(msg)
 	if err != nil {
 		p.logger.Error(LogMessage("publisher marshal"), zap.Error(err))
 		return err
 	}
 
+	envelope = envelope.Clone()
+	if envelope.ContentType == "" {
+		envelope.ContentType = JsonContentType
+	}
+
+	publish := messaging.ChainPublish(p.send, p.interceptors...)
+
+	return publish(ctx, &messaging.OutgoingMessage{Envelope: envelope, Message: msg, Body: byt})
+}
+
+// send maps the outgoing message to an AMQP publishing, injecting the trace context in the headers,
+// and publishes it to the envelope exchange using the envelope key as routing key.
+func (p *publisher) send(ctx context.Context, out *messaging.OutgoingMessage) error {
+	envelope := out.Envelope
+
 	headers := amqp.Table{}
+	for k, v := range envelope.Headers {
+		headers[k] = v
+	}
 	tracing.AMQPPropagator.Inject(ctx, tracing.AMQPHeader(headers))
 
-	return p.channel.Publish(exchange, key, false, false, amqp.Publishing{
-		Headers:     headers,
-		Type:        fmt.Sprintf("%T", msg),
-		ContentType: JsonContentType,
-		MessageId:   uuid.NewString(),
-		UserId:      p.configs.RabbitMQConfigs.User,
-		AppId:       p.configs.AppConfigs.AppName,
-		Body:        byt,
-	})
+	publishing := amqp.Publishing{
+		Headers:       headers,
+		Type:          messaging.TypeName(out.Message),
+		ContentType:   envelope.ContentType,
+		MessageId:     envelope.MessageID,
+		CorrelationId: envelope.CorrelationID,
+		Priority:      envelope.Priority,
+		Timestamp:     time.Now(),
+		UserId:        p.configs.RabbitMQConfigs.User,
+		AppId:         p.configs.AppConfigs.AppName,
+		Body:          out.Body,
+	}
+
+	if publishing.MessageId == "" {
+		publishing.MessageId = uuid.NewString()
+	}
+
+	if envelope.Expiration > 0 {
+		publishing.Expiration = strconv.FormatInt(envelope.Expiration.Milliseconds(), 10)
+	}
+
+	// The delay is honored by the exchanges declared with NewDelayedExchange
+	if envelope.Delay > 0 {
+		headers[DelayHeader] = envelope.Delay.Milliseconds()
+	}
+
+	if persistent, ok := envelope.Extension(persistentKey{}); ok && persistent.(bool) {
+		publishing.DeliveryMode = amqp.Persistent
+	}
+
+	return p.channel.Publish(envelope.To, envelope.Key, false, false, publishing)
 }
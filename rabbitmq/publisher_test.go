@@ -0,0 +1,88 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package rabbitmq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type (
+	PublisherTestSuite struct {
+		suite.Suite
+
+		channel *channelMock
+		cfgs    *configs.Configs
+	}
+
+	// channelMock records the published messages.
+	channelMock struct {
+		AMQPChannel
+
+		exchanges  []string
+		publishing []amqp.Publishing
+	}
+
+	order struct {
+		ID string `json:"id"`
+	}
+)
+
+func TestPublisherTestSuite(t *testing.T) {
+	suite.Run(t, new(PublisherTestSuite))
+}
+
+func (s *PublisherTestSuite) SetupTest() {
+	s.channel = &channelMock{}
+	s.cfgs = &configs.Configs{
+		Logger:          zap.NewNop(),
+		AppConfigs:      &configs.AppConfigs{AppName: "orders"},
+		RabbitMQConfigs: &configs.RabbitMQConfigs{User: "guest"},
+	}
+}
+
+func (s *PublisherTestSuite) TestPublishDelayedToDelayedExchange() {
+	publisher := NewPublisher(s.cfgs, s.channel, WithDelayedExchanges("payments"))
+
+	err := messaging.Publish(context.Background(), publisher, "payments", order{ID: "1"}, messaging.WithDelay(time.Minute))
+
+	s.NoError(err)
+	s.Require().Len(s.channel.publishing, 1)
+	s.Equal("payments", s.channel.exchanges[0])
+	s.Equal(int64(60000), s.channel.publishing[0].Headers[DelayHeader])
+}
+
+func (s *PublisherTestSuite) TestPublishDelayedToPlainExchange() {
+	publisher := NewPublisher(s.cfgs, s.channel, WithDelayedExchanges("payments"))
+
+	err := messaging.Publish(context.Background(), publisher, "orders", order{ID: "1"}, messaging.WithDelay(time.Minute))
+
+	s.ErrorIs(err, messaging.DelayNotSupportedError)
+	s.Empty(s.channel.publishing)
+}
+
+func (s *PublisherTestSuite) TestPublishWithoutDelay() {
+	publisher := NewPublisher(s.cfgs, s.channel)
+
+	err := messaging.Publish(context.Background(), publisher, "orders", order{ID: "1"}, messaging.WithKey("created"))
+
+	s.NoError(err)
+	s.Require().Len(s.channel.publishing, 1)
+	s.NotContains(s.channel.publishing[0].Headers, DelayHeader)
+	s.JSONEq(`{"id":"1"}`, string(s.channel.publishing[0].Body))
+}
+
+func (c *channelMock) Publish(exchange, _ string, _, _ bool, msg amqp.Publishing) error {
+	c.exchanges = append(c.exchanges, exchange)
+	c.publishing = append(c.publishing, msg)
+	return nil
+}
@@ -6,6 +6,9 @@
 // It offers abstractions for connections, channels, exchanges, queues, bindings, publishers, and message consumers.
 package rabbitmq
 
+// System identifies RabbitMQ in the "system" attribute of the consumer metrics.
+const System = "rabbitmq"
+
 // LogMessage formats a consistent log message with a RabbitMQ package prefix.
 // It concatenates all provided strings with the "[gokit::rabbitmq]" prefix.
 // This helps with identifying RabbitMQ-related log entries throughout the application.
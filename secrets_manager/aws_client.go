@@ -41,11 +41,12 @@ func NewAwsSecretClient(cfgs *configs.Configs) (SecretClient, error) {
 
 	appSecretId := fmt.Sprintf("%s/%s", cfgs.AppConfigs.GoEnv.ToString(), cfgs.AppConfigs.SecretKey)
 
-	return &awsSecretClient{client: secretsmanager.NewFromConfig(awsCfg), appSecretId: appSecretId}, nil
+	return &awsSecretClient{logger: logger, client: secretsmanager.NewFromConfig(awsCfg), appSecretId: appSecretId}, nil
 }
 
 // LoadSecrets retrieves all secrets from AWS Secrets Manager for the configured secret ID.
-// It fetches the secret value as a JSON blob and unmarshals it into a map of string keys to string values.
+// It fetches the secret value as a JSON object, stored either as SecretString or SecretBinary,
+// and unmarshals it into a map of string keys to string values.
 // The secrets are stored in memory for fast access by the GetSecret method.
 // Returns an error if the secret cannot be fetched or parsed.
 func (c *awsSecretClient) LoadSecrets(ctx context.Context) error {
@@ -58,9 +59,16 @@ func (c *awsSecretClient) LoadSecrets(ctx context.Context) error {
 		return err
 	}
 
+	// Secrets created from the console or the CLI are stored as SecretString,
+	// SecretBinary only holds the binary secrets
+	value := res.SecretBinary
+	if res.SecretString != nil {
+		value = []byte(*res.SecretString)
+	}
+
 	c.secrets = map[string]string{}
 
-	err = json.Unmarshal(res.SecretBinary, &c.secrets)
+	err = json.Unmarshal(value, &c.secrets)
 	if err != nil {
 		c.logger.Error("error get secret from aws", zap.Error(err))
 		return err
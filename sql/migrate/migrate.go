@@ -0,0 +1,435 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package migrate applies versioned SQL migrations to PostgreSQL databases.
+//
+// The migrations are read from an fs.FS, usually an embed.FS or os.DirFS, holding one file per
+// version and direction named <version>_<name>.up.sql and <version>_<name>.down.sql. The applied
+// versions are tracked in a table, and the migrations run while holding a PostgreSQL advisory lock,
+// so several replicas of a service starting together do not race to apply them.
+//
+// Example:
+//
+//	//go:embed migrations/*.sql
+//	var migrations embed.FS
+//
+//	db, _ := pg.New(cfgs).Connect()
+//	steps, err := migrate.New(cfgs, migrations, "migrations").Migrate(ctx, db)
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"sort"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/logging"
+	"go.uber.org/zap"
+)
+
+type (
+	// Direction tells whether a step applies or reverts a migration.
+	Direction string
+
+	// Migration is a version of the database schema, read from its up and down files.
+	Migration struct {
+		// Version orders the migrations, it is the numeric prefix of the file names
+		Version int64
+		// Name is the file name part between the version and the direction
+		Name string
+		// Up holds the statements applying the migration
+		Up string
+		// Down holds the statements reverting the migration, empty when there is no down file
+		Down string
+	}
+
+	// Step is a migration applied or reverted by Migrate.
+	Step struct {
+		Migration
+		Direction Direction
+	}
+
+	// MigrationStatus reports whether a migration is applied.
+	MigrationStatus struct {
+		Version int64
+		Name    string
+		Applied bool
+		// AppliedAt is the time the migration was applied, zero when it is pending
+		AppliedAt time.Time
+		// Missing tells the migration is applied but has no file in the source
+		Missing bool
+	}
+
+	// Migrator applies the migrations of a source to a PostgreSQL database.
+	Migrator struct {
+		logger logging.Logger
+		source fs.FS
+		dir    string
+		table  string
+		lockID int64
+		target *int64
+		dryRun bool
+	}
+
+	// applied is a migration recorded in the table.
+	applied struct {
+		name      string
+		appliedAt time.Time
+	}
+
+	// querier is implemented by *sql.Conn and *sql.DB.
+	querier interface {
+		QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+		QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	}
+)
+
+const (
+	// DirectionUp applies a migration.
+	DirectionUp Direction = "up"
+	// DirectionDown reverts a migration.
+	DirectionDown Direction = "down"
+
+	// DefaultTable is the name of the table tracking the applied migrations.
+	DefaultTable = "schema_migrations"
+)
+
+var (
+	// InvalidFileNameError is returned when a SQL file of the source is not named <version>_<name>.<up|down>.sql.
+	InvalidFileNameError = errors.New("invalid migration file name")
+	// DuplicateMigrationError is returned when two files of the source have the same version and direction.
+	DuplicateMigrationError = errors.New("duplicate migration")
+	// MissingUpMigrationError is returned when a version of the source has a down file but no up file.
+	MissingUpMigrationError = errors.New("migration without up file")
+	// MissingDownMigrationError is returned when reverting a migration that has no down file.
+	MissingDownMigrationError = errors.New("migration without down file")
+	// UnknownMigrationError is returned when reverting an applied migration that is not in the source.
+	UnknownMigrationError = errors.New("applied migration not found in the source")
+)
+
+// New creates a Migrator reading the migrations of the directory of the source,
+// tracking them in the DefaultTable and applying every pending migration.
+//
+// Parameters:
+//   - cfgs: Application configurations including the logger.
+//   - source: The file system holding the migrations, usually an embed.FS or os.DirFS.
+//   - dir: The directory of the migrations in the source, "." for its root.
+//
+// Returns:
+//   - A new Migrator instance.
+func New(cfgs *configs.Configs, source fs.FS, dir string) *Migrator {
+	return &Migrator{
+		logger: cfgs.Logger,
+		source: source,
+		dir:    dir,
+		table:  DefaultTable,
+	}
+}
+
+// WithTable sets the name of the table tracking the applied migrations.
+func (m *Migrator) WithTable(table string) *Migrator {
+	m.table = table
+	return m
+}
+
+// WithLockID sets the key of the advisory lock held while migrating.
+// By default the key is derived from the table name.
+func (m *Migrator) WithLockID(id int64) *Migrator {
+	m.lockID = id
+	return m
+}
+
+// WithTarget sets the version to migrate to. The migrations up to the version are applied
+// and the ones after it are reverted, 0 reverting every migration. Without target, Migrate only
+// applies the pending migrations and never reverts any.
+func (m *Migrator) WithTarget(version int64) *Migrator {
+	m.target = &version
+	return m
+}
+
+// WithDryRun makes Migrate log and return the steps without running them.
+func (m *Migrator) WithDryRun() *Migrator {
+	m.dryRun = true
+	return m
+}
+
+// CreateTableStatement returns the PostgreSQL statement creating the table tracking the applied migrations.
+//
+// Parameters:
+//   - table: The name of the table, usually DefaultTable.
+//
+// Returns:
+//   - The CREATE TABLE statement.
+func CreateTableStatement(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	version    BIGINT PRIMARY KEY,
+	name       TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+)`, table)
+}
+
+// Migrate applies the pending migrations up to the target version and reverts the applied ones after it,
+// holding the advisory lock. The reverted migrations run first, from the latest version, followed by the
+// applied ones, from the earliest version. Each migration runs in its own transaction, together with the
+// update of the table. Without target, every pending migration is applied and the applied versions that
+// are not in the source are ignored.
+//
+// Parameters:
+//   - ctx: The context of the database operations.
+//   - db: The database to migrate.
+//
+// Returns:
+//   - The steps run, or the steps that would run in dry-run mode.
+//   - An error if the source is invalid or a migration failed, the steps run before it being returned.
+func (m *Migrator) Migrate(ctx context.Context, db *sql.DB) ([]Step, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", m.lockKey()); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", m.lockKey()); err != nil {
+			m.logger.Warn(LogMessage("failure to release the advisory lock"), zap.Error(err))
+		}
+	}()
+
+	if !m.dryRun {
+		if _, err := conn.ExecContext(ctx, CreateTableStatement(m.table)); err != nil {
+			return nil, err
+		}
+	}
+
+	applied, err := m.applied(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	steps, err := m.plan(migrations, applied)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.dryRun {
+		for _, step := range steps {
+			m.logger.Info(LogMessage("dry-run migration"), stepFields(step)...)
+		}
+
+		return steps, nil
+	}
+
+	done := []Step{}
+	for _, step := range steps {
+		if err := m.run(ctx, conn, step); err != nil {
+			m.logger.Error(LogMessage("failure to run migration"), append(stepFields(step), zap.Error(err))...)
+			return done, fmt.Errorf("migration %d_%s %s: %w", step.Version, step.Name, step.Direction, err)
+		}
+
+		m.logger.Info(LogMessage("migration run"), stepFields(step)...)
+		done = append(done, step)
+	}
+
+	return done, nil
+}
+
+// Status reports the migrations of the source and whether they are applied, ordered by version.
+// Applied migrations without file in the source are reported as missing.
+func (m *Migrator) Status(ctx context.Context, db *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := m.Migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	status := []MigrationStatus{}
+	for _, migration := range migrations {
+		a, ok := applied[migration.Version]
+		status = append(status, MigrationStatus{
+			Version:   migration.Version,
+			Name:      migration.Name,
+			Applied:   ok,
+			AppliedAt: a.appliedAt,
+		})
+
+		delete(applied, migration.Version)
+	}
+
+	for version, a := range applied {
+		status = append(status, MigrationStatus{Version: version, Name: a.name, Applied: true, AppliedAt: a.appliedAt, Missing: true})
+	}
+
+	sort.Slice(status, func(i, j int) bool { return status[i].Version < status[j].Version })
+
+	return status, nil
+}
+
+// Statement returns the statements run by the step.
+func (s Step) Statement() string {
+	if s.Direction == DirectionDown {
+		return s.Down
+	}
+
+	return s.Up
+}
+
+// LogMessage formats a log message with the migrate package prefix.
+func LogMessage(msg ...string) string {
+	f := "[gokit::migrate] "
+
+	for _, s := range msg {
+		f += s
+	}
+
+	return f
+}
+
+// lockKey returns the key of the advisory lock, the lock ID or a hash of the table name.
+func (m *Migrator) lockKey() int64 {
+	if m.lockID != 0 {
+		return m.lockID
+	}
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte("gokit::migrate::" + m.table))
+
+	return int64(h.Sum64())
+}
+
+// applied reads the migrations recorded in the table, none when the table does not exist.
+func (m *Migrator) applied(ctx context.Context, q querier) (map[int64]applied, error) {
+	var exists bool
+	if err := q.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", m.table).Scan(&exists); err != nil {
+		return nil, err
+	}
+
+	migrations := map[int64]applied{}
+	if !exists {
+		return migrations, nil
+	}
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT version, name, applied_at FROM %s", m.table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			version int64
+			a       applied
+		)
+
+		if err := rows.Scan(&version, &a.name, &a.appliedAt); err != nil {
+			return nil, err
+		}
+
+		migrations[version] = a
+	}
+
+	return migrations, rows.Err()
+}
+
+// plan returns the steps migrating from the applied migrations to the target version.
+// Without target, the pending migrations are applied and the applied versions missing from the
+// source are ignored, e.g. the ones applied by a newer release of the service during a rolling deploy.
+func (m *Migrator) plan(migrations []Migration, applied map[int64]applied) ([]Step, error) {
+	if m.target == nil {
+		steps := []Step{}
+		for _, migration := range migrations {
+			if _, ok := applied[migration.Version]; !ok {
+				steps = append(steps, Step{Migration: migration, Direction: DirectionUp})
+			}
+		}
+
+		return steps, nil
+	}
+
+	target := *m.target
+
+	byVersion := map[int64]Migration{}
+	for _, migration := range migrations {
+		byVersion[migration.Version] = migration
+	}
+
+	reverted := []int64{}
+	for version := range applied {
+		if version > target {
+			reverted = append(reverted, version)
+		}
+	}
+	sort.Slice(reverted, func(i, j int) bool { return reverted[i] > reverted[j] })
+
+	steps := []Step{}
+	for _, version := range reverted {
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d_%s", UnknownMigrationError, version, applied[version].name)
+		}
+
+		if migration.Down == "" {
+			return nil, fmt.Errorf("%w: %d_%s", MissingDownMigrationError, version, migration.Name)
+		}
+
+		steps = append(steps, Step{Migration: migration, Direction: DirectionDown})
+	}
+
+	for _, migration := range migrations {
+		if _, ok := applied[migration.Version]; !ok && migration.Version <= target {
+			steps = append(steps, Step{Migration: migration, Direction: DirectionUp})
+		}
+	}
+
+	return steps, nil
+}
+
+// run runs the step and records it in the table within a transaction.
+func (m *Migrator) run(ctx context.Context, conn *sql.Conn, step Step) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, step.Statement()); err != nil {
+		return err
+	}
+
+	if step.Direction == DirectionUp {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (version, name) VALUES ($1, $2)", m.table), step.Version, step.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE version = $1", m.table), step.Version)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// stepFields returns the log fields describing the step.
+func stepFields(step Step) []zap.Field {
+	return []zap.Field{
+		zap.Int64("version", step.Version),
+		zap.String("name", step.Name),
+		zap.String("direction", string(step.Direction)),
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type MigrateTestSuite struct {
+	suite.Suite
+
+	db       *sql.DB
+	sqlMock  sqlmock.Sqlmock
+	source   fstest.MapFS
+	migrator *Migrator
+	now      time.Time
+}
+
+func TestMigrateTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrateTestSuite))
+}
+
+func (s *MigrateTestSuite) SetupTest() {
+	db, sqlMock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	s.db = db
+	s.sqlMock = sqlMock
+	s.source = fstest.MapFS{
+		"migrations/1_create_orders.up.sql":   {Data: []byte("CREATE TABLE orders (id BIGINT)")},
+		"migrations/1_create_orders.down.sql": {Data: []byte("DROP TABLE orders")},
+		"migrations/2_add_status.up.sql":      {Data: []byte("ALTER TABLE orders ADD status TEXT")},
+		"migrations/2_add_status.down.sql":    {Data: []byte("ALTER TABLE orders DROP status")},
+		"migrations/3_add_index.up.sql":       {Data: []byte("CREATE INDEX orders_status_idx ON orders (status)")},
+		"migrations/README.md":                {Data: []byte("ignored")},
+	}
+	s.migrator = New(&configs.Configs{Logger: zap.NewNop()}, s.source, "migrations")
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (s *MigrateTestSuite) TearDownTest() {
+	s.NoError(s.sqlMock.ExpectationsWereMet())
+}
+
+func (s *MigrateTestSuite) TestMigrations() {
+	migrations, err := s.migrator.Migrations()
+
+	s.Require().NoError(err)
+	s.Require().Len(migrations, 3)
+	s.Equal(Migration{Version: 1, Name: "create_orders", Up: "CREATE TABLE orders (id BIGINT)", Down: "DROP TABLE orders"}, migrations[0])
+	s.Equal(int64(2), migrations[1].Version)
+	s.Equal("add_index", migrations[2].Name)
+	s.Empty(migrations[2].Down)
+}
+
+func (s *MigrateTestSuite) TestMigrationsErrors() {
+	for err, source := range map[error]fstest.MapFS{
+		InvalidFileNameError:    {"create_orders.up.sql": {Data: []byte("SELECT 1")}},
+		DuplicateMigrationError: {"1_a.up.sql": {Data: []byte("SELECT 1")}, "1_b.up.sql": {Data: []byte("SELECT 1")}},
+		MissingUpMigrationError: {"1_a.down.sql": {Data: []byte("SELECT 1")}},
+	} {
+		_, e := New(&configs.Configs{}, source, ".").Migrations()
+		s.ErrorIs(e, err)
+	}
+}
+
+func (s *MigrateTestSuite) TestMigrate() {
+	s.expectLock()
+	s.expectApplied(1)
+	s.expectStep("ALTER TABLE orders ADD status TEXT", "INSERT INTO schema_migrations", 2, "add_status")
+	s.expectStep("CREATE INDEX orders_status_idx ON orders (status)", "INSERT INTO schema_migrations", 3, "add_index")
+	s.expectUnlock()
+
+	steps, err := s.migrator.Migrate(context.Background(), s.db)
+
+	s.Require().NoError(err)
+	s.Require().Len(steps, 2)
+	s.Equal(int64(2), steps[0].Version)
+	s.Equal(DirectionUp, steps[0].Direction)
+	s.Equal(int64(3), steps[1].Version)
+}
+
+func (s *MigrateTestSuite) TestMigrateIgnoresNewerAppliedVersions() {
+	s.expectLock()
+	s.expectApplied(1, 4)
+	s.expectStep("ALTER TABLE orders ADD status TEXT", "INSERT INTO schema_migrations", 2, "add_status")
+	s.expectStep("CREATE INDEX orders_status_idx ON orders (status)", "INSERT INTO schema_migrations", 3, "add_index")
+	s.expectUnlock()
+
+	steps, err := s.migrator.Migrate(context.Background(), s.db)
+
+	s.Require().NoError(err)
+	s.Require().Len(steps, 2)
+	s.Equal(DirectionUp, steps[0].Direction)
+	s.Equal(DirectionUp, steps[1].Direction)
+}
+
+func (s *MigrateTestSuite) TestMigrateUnknownVersionToTarget() {
+	s.expectLock()
+	s.expectApplied(1, 4)
+	s.expectUnlock()
+
+	_, err := s.migrator.WithTarget(3).Migrate(context.Background(), s.db)
+
+	s.ErrorIs(err, UnknownMigrationError)
+}
+
+func (s *MigrateTestSuite) TestMigrateDownToTarget() {
+	s.source["migrations/3_add_index.down.sql"] = &fstest.MapFile{Data: []byte("DROP INDEX orders_status_idx")}
+
+	s.expectLock()
+	s.expectApplied(1, 2, 3)
+	s.expectStep("DROP INDEX orders_status_idx", "DELETE FROM schema_migrations", 3)
+	s.expectStep("ALTER TABLE orders DROP status", "DELETE FROM schema_migrations", 2)
+	s.expectUnlock()
+
+	steps, err := s.migrator.WithTarget(1).Migrate(context.Background(), s.db)
+
+	s.Require().NoError(err)
+	s.Require().Len(steps, 2)
+	s.Equal(DirectionDown, steps[0].Direction)
+	s.Equal("DROP INDEX orders_status_idx", steps[0].Statement())
+	s.Equal(int64(2), steps[1].Version)
+}
+
+func (s *MigrateTestSuite) TestMigrateWithoutDownFile() {
+	s.expectLock()
+	s.expectApplied(1, 2, 3)
+	s.expectUnlock()
+
+	_, err := s.migrator.WithTarget(2).Migrate(context.Background(), s.db)
+
+	s.ErrorIs(err, MissingDownMigrationError)
+}
+
+func (s *MigrateTestSuite) TestMigrateDryRun() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass($1) IS NOT NULL")).
+		WithArgs("schema_migrations").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	s.expectUnlock()
+
+	steps, err := s.migrator.WithDryRun().Migrate(context.Background(), s.db)
+
+	s.Require().NoError(err)
+	s.Len(steps, 3)
+}
+
+func (s *MigrateTestSuite) TestMigrateFailure() {
+	s.expectLock()
+	s.expectApplied()
+	s.expectStep("CREATE TABLE orders (id BIGINT)", "INSERT INTO schema_migrations", 1, "create_orders")
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("ALTER TABLE orders ADD status TEXT")).WillReturnError(errors.New("syntax error"))
+	s.sqlMock.ExpectRollback()
+	s.expectUnlock()
+
+	steps, err := s.migrator.Migrate(context.Background(), s.db)
+
+	s.EqualError(err, "migration 2_add_status up: syntax error")
+	s.Len(steps, 1)
+}
+
+func (s *MigrateTestSuite) TestMigrateLockID() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).
+		WithArgs(int64(42)).
+		WillReturnError(errors.New("lock err"))
+
+	_, err := s.migrator.WithLockID(42).Migrate(context.Background(), s.db)
+
+	s.EqualError(err, "lock err")
+}
+
+func (s *MigrateTestSuite) TestStatus() {
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass($1) IS NOT NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT version, name, applied_at FROM schema_migrations")).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "name", "applied_at"}).
+			AddRow(1, "create_orders", s.now).
+			AddRow(4, "removed", s.now))
+
+	status, err := s.migrator.Status(context.Background(), s.db)
+
+	s.Require().NoError(err)
+	s.Equal([]MigrationStatus{
+		{Version: 1, Name: "create_orders", Applied: true, AppliedAt: s.now},
+		{Version: 2, Name: "add_status"},
+		{Version: 3, Name: "add_index"},
+		{Version: 4, Name: "removed", Applied: true, AppliedAt: s.now, Missing: true},
+	}, status)
+}
+
+func (s *MigrateTestSuite) expectLock() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).
+		WithArgs(s.migrator.lockKey()).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("CREATE TABLE IF NOT EXISTS schema_migrations")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (s *MigrateTestSuite) expectUnlock() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_unlock($1)")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func (s *MigrateTestSuite) expectApplied(versions ...int64) {
+	rows := sqlmock.NewRows([]string{"version", "name", "applied_at"})
+	for _, version := range versions {
+		rows.AddRow(version, "", s.now)
+	}
+
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT to_regclass($1) IS NOT NULL")).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT version, name, applied_at FROM schema_migrations")).
+		WillReturnRows(rows)
+}
+
+func (s *MigrateTestSuite) expectStep(statement, record string, args ...any) {
+	values := make([]driver.Value, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg)
+	}
+
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectExec(regexp.QuoteMeta(statement)).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta(record)).WithArgs(values...).WillReturnResult(sqlmock.NewResult(0, 1))
+	s.sqlMock.ExpectCommit()
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// fileName matches the migration files, capturing the version, the name and the direction.
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Migrations reads the migrations of the source, ordered by version.
+// Files without the .sql extension and sub directories are ignored.
+//
+// Returns:
+//   - The migrations of the source.
+//   - An error if a file name is invalid, a version is duplicated or has no up file.
+func (m *Migrator) Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(m.source, m.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("%w: %s", InvalidFileNameError, entry.Name())
+		}
+
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", InvalidFileNameError, entry.Name())
+		}
+
+		content, err := fs.ReadFile(m.source, path.Join(m.dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("%w: %d_%s and %d_%s", DuplicateMigrationError, version, migration.Name, version, match[2])
+		}
+
+		statement := &migration.Up
+		if match[3] == string(DirectionDown) {
+			statement = &migration.Down
+		}
+
+		if *statement != "" {
+			return nil, fmt.Errorf("%w: %s", DuplicateMigrationError, entry.Name())
+		}
+
+		*statement = string(content)
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("%w: %d_%s", MissingUpMigrationError, migration.Version, migration.Name)
+		}
+
+		migrations = append(migrations, *migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
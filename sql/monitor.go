@@ -0,0 +1,243 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/logging"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+type (
+	// Monitor pings a database in background, keeping the result of the last ping to report the
+	// database availability, and exports the connection pool statistics as OpenTelemetry gauges.
+	// It implements the Checker interface of the httpw/health registry.
+	Monitor struct {
+		logger   logging.Logger
+		db       *sql.DB
+		name     string
+		interval time.Duration
+		metrics  bool
+
+		mu     sync.RWMutex
+		err    error
+		pinged bool
+	}
+
+	// poolGauges holds the gauges and the cumulative counters of the connection pool statistics.
+	poolGauges struct {
+		open         metric.Int64ObservableGauge
+		inUse        metric.Int64ObservableGauge
+		idle         metric.Int64ObservableGauge
+		waitCount    metric.Int64ObservableCounter
+		waitDuration metric.Float64ObservableCounter
+		up           metric.Int64ObservableGauge
+	}
+)
+
+const (
+	// DefaultPingInterval is the interval between the pings when SQLConfigs.SecondsToPing is not set
+	DefaultPingInterval = 30 * time.Second
+	// DefaultMonitorName is the pool name of the metrics when no name is set
+	DefaultMonitorName = "default"
+
+	// meterName is the instrumentation name of the pool gauges
+	meterName = "github.com/ralvescosta/gokit/sql"
+)
+
+// NotPingedError is returned by Monitor.Check before the first ping.
+var NotPingedError = errors.New("database not pinged yet")
+
+// NewMonitor creates a Monitor pinging the database every SQLConfigs.SecondsToPing seconds,
+// or every DefaultPingInterval when it is not set.
+//
+// Parameters:
+//   - cfgs: Application configurations including the logger and the SQL settings
+//   - db: The database to monitor, usually returned by pg.Connect
+//
+// Returns:
+//   - A new Monitor instance, started with Run
+func NewMonitor(cfgs *configs.Configs, db *sql.DB) *Monitor {
+	interval := DefaultPingInterval
+	if cfgs.SQLConfigs != nil && cfgs.SQLConfigs.SecondsToPing > 0 {
+		interval = time.Duration(cfgs.SQLConfigs.SecondsToPing) * time.Second
+	}
+
+	return &Monitor{
+		logger:   cfgs.Logger,
+		db:       db,
+		name:     DefaultMonitorName,
+		interval: interval,
+	}
+}
+
+// WithName sets the pool name attribute of the metrics, e.g. to distinguish the primary from the replicas.
+func (m *Monitor) WithName(name string) *Monitor {
+	m.name = name
+	return m
+}
+
+// WithInterval sets the time between the pings. Non-positive intervals are ignored.
+func (m *Monitor) WithInterval(interval time.Duration) *Monitor {
+	if interval > 0 {
+		m.interval = interval
+	}
+	return m
+}
+
+// WithMetrics exports the connection pool statistics through the global meter provider,
+// configured by the metrics package, while the monitor runs. The wait statistics are cumulative, so
+// they are exported as counters, the others as gauges:
+//   - db.client.connections.open: open connections
+//   - db.client.connections.in_use: connections in use
+//   - db.client.connections.idle: idle connections
+//   - db.client.connections.wait_count: counter of the connections waited for
+//   - db.client.connections.wait_duration: counter of the time waited for new connections, in seconds
+//   - db.client.up: 1 when the last ping succeeded, 0 otherwise
+func (m *Monitor) WithMetrics() *Monitor {
+	m.metrics = true
+	return m
+}
+
+// Run pings the database right away and then at every interval, until the context is canceled.
+// The failures and recoveries of the database are logged.
+//
+// Returns:
+//   - An error if the gauges could not be registered, or the context error once it is canceled.
+func (m *Monitor) Run(ctx context.Context) error {
+	if m.metrics {
+		registration, err := m.registerGauges(otel.Meter(meterName))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = registration.Unregister() }()
+	}
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		_ = m.Ping(ctx)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Ping pings the database, within the interval, and keeps the result reported by Check.
+//
+// Returns:
+//   - The ping error, nil when the database is available.
+func (m *Monitor) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, m.interval)
+	defer cancel()
+
+	err := m.db.PingContext(ctx)
+
+	m.mu.Lock()
+	previous := m.err
+	m.err, m.pinged = err, true
+	m.mu.Unlock()
+
+	switch {
+	case err != nil && previous == nil:
+		m.logger.Error(LogMessage("database unavailable"), zap.String("name", m.name), zap.Error(err))
+	case err == nil && previous != nil:
+		m.logger.Info(LogMessage("database available again"), zap.String("name", m.name))
+	}
+
+	return err
+}
+
+// Check reports the result of the last ping without reaching the database,
+// so readiness probes do not add load to the connection pool.
+//
+// Returns:
+//   - The error of the last ping, NotPingedError before the first ping, or nil.
+func (m *Monitor) Check(_ context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.pinged {
+		return NotPingedError
+	}
+
+	return m.err
+}
+
+// LogMessage formats a log message with the sql package prefix.
+func LogMessage(msg ...string) string {
+	f := "[gokit::sql] "
+
+	for _, s := range msg {
+		f += s
+	}
+
+	return f
+}
+
+// registerGauges creates the pool gauges and counters and registers the callback observing db.Stats.
+func (m *Monitor) registerGauges(meter metric.Meter) (metric.Registration, error) {
+	var (
+		g   poolGauges
+		err error
+	)
+
+	if g.open, err = meter.Int64ObservableGauge("db.client.connections.open", metric.WithDescription("Number of open connections.")); err != nil {
+		return nil, err
+	}
+
+	if g.inUse, err = meter.Int64ObservableGauge("db.client.connections.in_use", metric.WithDescription("Number of connections in use.")); err != nil {
+		return nil, err
+	}
+
+	if g.idle, err = meter.Int64ObservableGauge("db.client.connections.idle", metric.WithDescription("Number of idle connections.")); err != nil {
+		return nil, err
+	}
+
+	if g.waitCount, err = meter.Int64ObservableCounter("db.client.connections.wait_count", metric.WithDescription("Total number of connections waited for.")); err != nil {
+		return nil, err
+	}
+
+	if g.waitDuration, err = meter.Float64ObservableCounter("db.client.connections.wait_duration", metric.WithDescription("Total time waited for new connections."), metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+
+	if g.up, err = meter.Int64ObservableGauge("db.client.up", metric.WithDescription("Whether the last database ping succeeded.")); err != nil {
+		return nil, err
+	}
+
+	attrs := metric.WithAttributes(attribute.String("db.client.connections.pool.name", m.name))
+
+	return meter.RegisterCallback(func(_ context.Context, observer metric.Observer) error {
+		stats := m.db.Stats()
+
+		observer.ObserveInt64(g.open, int64(stats.OpenConnections), attrs)
+		observer.ObserveInt64(g.inUse, int64(stats.InUse), attrs)
+		observer.ObserveInt64(g.idle, int64(stats.Idle), attrs)
+		observer.ObserveInt64(g.waitCount, stats.WaitCount, attrs)
+		observer.ObserveFloat64(g.waitDuration, stats.WaitDuration.Seconds(), attrs)
+
+		up := int64(0)
+		if m.Check(context.Background()) == nil {
+			up = 1
+		}
+		observer.ObserveInt64(g.up, up, attrs)
+
+		return nil
+	}, g.open, g.inUse, g.idle, g.waitCount, g.waitDuration, g.up)
+}
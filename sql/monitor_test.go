@@ -0,0 +1,158 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.uber.org/zap"
+)
+
+// MonitorTestSuite defines the test suite for the database monitor.
+type MonitorTestSuite struct {
+	suite.Suite
+
+	db      *sql.DB
+	sqlMock sqlmock.Sqlmock
+	monitor *Monitor
+}
+
+// TestMonitorTestSuite runs the database monitor test suite.
+func TestMonitorTestSuite(t *testing.T) {
+	suite.Run(t, new(MonitorTestSuite))
+}
+
+// SetupTest creates the database mock monitoring the pings.
+func (s *MonitorTestSuite) SetupTest() {
+	db, sqlMock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	s.Require().NoError(err)
+
+	s.db = db
+	s.sqlMock = sqlMock
+	s.monitor = NewMonitor(&configs.Configs{Logger: zap.NewNop(), SQLConfigs: &configs.SQLConfigs{SecondsToPing: 5}}, db)
+}
+
+// TestNewMonitor verifies the ping interval is read from the configurations.
+func (s *MonitorTestSuite) TestNewMonitor() {
+	s.Equal(5*time.Second, s.monitor.interval)
+	s.Equal(DefaultPingInterval, NewMonitor(&configs.Configs{SQLConfigs: &configs.SQLConfigs{}}, s.db).interval)
+}
+
+// TestWithInterval verifies the non-positive intervals are ignored.
+func (s *MonitorTestSuite) TestWithInterval() {
+	s.Equal(5*time.Second, s.monitor.WithInterval(0).interval)
+	s.Equal(5*time.Second, s.monitor.WithInterval(-time.Second).interval)
+	s.Equal(time.Second, s.monitor.WithInterval(time.Second).interval)
+}
+
+// TestCheck verifies the check reports the result of the last ping.
+func (s *MonitorTestSuite) TestCheck() {
+	s.ErrorIs(s.monitor.Check(context.Background()), NotPingedError)
+
+	s.sqlMock.ExpectPing().WillReturnError(errors.New("connection refused"))
+	s.EqualError(s.monitor.Ping(context.Background()), "connection refused")
+	s.EqualError(s.monitor.Check(context.Background()), "connection refused")
+
+	s.sqlMock.ExpectPing()
+	s.NoError(s.monitor.Ping(context.Background()))
+	s.NoError(s.monitor.Check(context.Background()))
+
+	s.NoError(s.sqlMock.ExpectationsWereMet())
+}
+
+// TestRun verifies the database is pinged at every interval until the context is canceled.
+func (s *MonitorTestSuite) TestRun() {
+	s.sqlMock.ExpectPing()
+	s.sqlMock.ExpectPing()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- s.monitor.WithInterval(10 * time.Millisecond).Run(ctx) }()
+
+	s.Eventually(func() bool { return s.sqlMock.ExpectationsWereMet() == nil }, time.Second, 5*time.Millisecond)
+	cancel()
+
+	s.ErrorIs(<-done, context.Canceled)
+}
+
+// TestRunWithMetrics verifies the pool statistics are exported through the global meter provider.
+func (s *MonitorTestSuite) TestRunWithMetrics() {
+	reader := sdkmetric.NewManualReader()
+	previous := otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(previous)
+
+	s.sqlMock.ExpectPing()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() { done <- s.monitor.WithName("primary").WithMetrics().Run(ctx) }()
+
+	s.Eventually(func() bool { return s.monitor.Check(ctx) == nil }, time.Second, 5*time.Millisecond)
+
+	var data metricdata.ResourceMetrics
+	s.Require().NoError(reader.Collect(context.Background(), &data))
+	s.Require().Len(data.ScopeMetrics, 1)
+
+	values := map[string]any{}
+	for _, m := range data.ScopeMetrics[0].Metrics {
+		switch v := m.Data.(type) {
+		case metricdata.Gauge[int64]:
+			values[m.Name] = v.DataPoints[0].Value
+
+			pool, _ := v.DataPoints[0].Attributes.Value("db.client.connections.pool.name")
+			s.Equal("primary", pool.AsString())
+		case metricdata.Gauge[float64]:
+			values[m.Name] = v.DataPoints[0].Value
+		case metricdata.Sum[int64]:
+			s.True(v.IsMonotonic)
+			values[m.Name] = v.DataPoints[0].Value
+		case metricdata.Sum[float64]:
+			s.True(v.IsMonotonic)
+			values[m.Name] = v.DataPoints[0].Value
+		}
+	}
+
+	s.Equal(map[string]any{
+		"db.client.connections.open":          int64(1),
+		"db.client.connections.in_use":        int64(0),
+		"db.client.connections.idle":          int64(1),
+		"db.client.connections.wait_count":    int64(0),
+		"db.client.connections.wait_duration": float64(0),
+		"db.client.up":                        int64(1),
+	}, values)
+
+	cancel()
+	s.ErrorIs(<-done, context.Canceled)
+}
+
+// TestRegisterGaugesErr verifies the gauges registration errors are returned.
+func (s *MonitorTestSuite) TestRegisterGaugesErr() {
+	_, err := s.monitor.registerGauges(failingMeter{})
+
+	s.Error(err)
+}
+
+// failingMeter is a meter failing to create the gauges.
+type failingMeter struct {
+	metric.Meter
+}
+
+func (failingMeter) Int64ObservableGauge(string, ...metric.Int64ObservableGaugeOption) (metric.Int64ObservableGauge, error) {
+	return nil, errors.New("meter err")
+}
@@ -0,0 +1,348 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package mysql provides MySQL database integration for the GoKit framework.
+// It implements connection and interaction with MySQL databases using both
+// standard and OpenTelemetry-instrumented connections.
+package mysql
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/logging"
+	"github.com/uptrace/opentelemetry-go-extra/otelsql"
+	semconv "go.opentelemetry.io/otel/semconv/v1.8.0"
+	"go.uber.org/zap"
+
+	pkgSql "github.com/ralvescosta/gokit/sql"
+)
+
+type (
+	// MySQLConnection handles connection to MySQL databases.
+	// It supports both standard and OpenTelemetry-instrumented connections.
+	MySQLConnection struct {
+		// Err holds any error that occurred preparing the connection string
+		Err error
+		// logger handles structured logging
+		logger logging.Logger
+		// connectionString holds the formatted MySQL DSN
+		connectionString string
+		// cfg holds the application configurations
+		cfg *configs.Configs
+	}
+)
+
+// Variables for dependency injection during testing
+var sqlOpen = sql.Open
+var otelOpen = otelsql.Open
+
+const (
+	// FailureConnErrorMessage is the standard error message used when connection fails
+	FailureConnErrorMessage = "[MySQL::Connect] failure to connect to the database"
+
+	// DefaultPort is the port used when SQLConfigs.Port is not set
+	DefaultPort = "3306"
+)
+
+// New creates a new MySQL connection instance with the provided configurations.
+// It prepares the connection string but does not establish the connection.
+// Errors preparing the connection string, such as unreadable certificates,
+// are kept in Err and returned by Connect.
+//
+// Parameters:
+//   - cfgs: Application configurations including SQL and tracing settings
+//
+// Returns:
+//   - A new MySQLConnection instance ready to connect
+func New(cfgs *configs.Configs) *MySQLConnection {
+	connString, err := GetConnectionString(cfgs.SQLConfigs)
+
+	return &MySQLConnection{
+		Err:              err,
+		logger:           cfgs.Logger,
+		connectionString: connString,
+		cfg:              cfgs,
+	}
+}
+
+// GetConnectionString creates a MySQL DSN using the provided SQL configurations.
+// When the DSN is set, it is returned as it is. Otherwise the DSN parses the time values
+// into time.Time in UTC, applies the connect timeout, limits the execution time of the
+// read-only statements to the statement timeout, and maps the SSL mode to the driver TLS settings:
+//   - disable: no TLS
+//   - allow, prefer: TLS when the server supports it
+//   - require: TLS without verifying the server certificate
+//   - verify-ca: TLS verifying the server certificate chain
+//   - verify-full: TLS verifying the server certificate chain and host name
+//
+// The certificate authorities and the client certificate files, when set, are loaded into
+// a TLS configuration registered in the driver.
+//
+// Parameters:
+//   - cfg: SQL configuration containing host, port, user, password, and database name.
+//
+// Returns:
+//   - A MySQL DSN ready to be used with the MySQL driver, and any error loading the certificates
+func GetConnectionString(cfg *configs.SQLConfigs) (string, error) {
+	if cfg.DSN != "" {
+		return cfg.DSN, nil
+	}
+
+	port := cfg.Port
+	if port == "" {
+		port = DefaultPort
+	}
+
+	dsnCfg := driver.NewConfig()
+	dsnCfg.User = cfg.User
+	dsnCfg.Passwd = cfg.Password
+	dsnCfg.Net = "tcp"
+	dsnCfg.Addr = net.JoinHostPort(cfg.Host, port)
+	dsnCfg.DBName = cfg.DbName
+	dsnCfg.ParseTime = true
+	dsnCfg.Loc = time.UTC
+	dsnCfg.Timeout = cfg.ConnectTimeout
+
+	if cfg.StatementTimeout > 0 {
+		dsnCfg.Params = map[string]string{"max_execution_time": strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)}
+	}
+
+	tlsConfig, err := registerTLSConfig(cfg)
+	if err != nil {
+		return "", err
+	}
+
+	dsnCfg.TLSConfig = tlsConfig
+
+	return dsnCfg.FormatDSN(), nil
+}
+
+// GetReplicaConnectionStrings creates the DSN of the read replicas, one per SQLConfigs.ReplicaHosts
+// entry, sharing every setting of the primary but the address. A replica without port uses
+// the port of the primary.
+//
+// Parameters:
+//   - cfg: SQL configuration containing the read replicas
+//
+// Returns:
+//   - The DSN of the read replicas, empty when there is no replica, and any error that occurred
+func GetReplicaConnectionStrings(cfg *configs.SQLConfigs) ([]string, error) {
+	primary, err := GetConnectionString(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dsns := make([]string, 0, len(cfg.ReplicaHosts))
+
+	for _, replica := range cfg.ReplicaHosts {
+		dsnCfg, err := driver.ParseDSN(primary)
+		if err != nil {
+			return nil, err
+		}
+
+		_, port, _ := net.SplitHostPort(dsnCfg.Addr)
+		host := replica
+		if h, p, err := net.SplitHostPort(replica); err == nil {
+			host, port = h, p
+		}
+
+		dsnCfg.Addr = net.JoinHostPort(host, port)
+
+		dsns = append(dsns, dsnCfg.FormatDSN())
+	}
+
+	return dsns, nil
+}
+
+// open establishes a database connection using either standard or
+// OpenTelemetry-instrumented connection methods based on configuration.
+//
+// Parameters:
+//   - connString: The DSN of the primary or of a read replica
+//
+// Returns:
+//   - A database connection and any error that occurred
+func (m *MySQLConnection) open(connString string) (*sql.DB, error) {
+	if m.cfg.TracingConfigs != nil && m.cfg.TracingConfigs.Enabled {
+		return otelOpen(
+			"mysql",
+			connString,
+			otelsql.WithAttributes(semconv.DBSystemMySQL),
+			otelsql.WithDBName(m.cfg.SQLConfigs.DbName),
+		)
+	}
+
+	return sqlOpen("mysql", connString)
+}
+
+// Connect establishes a connection to the MySQL database, applies the
+// connection pool settings and verifies connectivity with a ping.
+//
+// Returns:
+//   - A connected database instance and any error that occurred
+func (m *MySQLConnection) Connect() (*sql.DB, error) {
+	if m.Err != nil {
+		m.logger.Error(FailureConnErrorMessage, zap.Error(m.Err))
+		return nil, m.Err
+	}
+
+	return m.connect(m.connectionString)
+}
+
+// ConnectReplicas establishes a connection to each read replica of the configurations,
+// sharing the settings of the primary, and verifies their connectivity with a ping.
+// The connections already established are closed when a replica fails.
+//
+// Returns:
+//   - The connected replicas, empty when there is no replica, and any error that occurred
+func (m *MySQLConnection) ConnectReplicas() ([]*sql.DB, error) {
+	connStrings, err := GetReplicaConnectionStrings(m.cfg.SQLConfigs)
+	if err != nil {
+		m.logger.Error(FailureConnErrorMessage, zap.Error(err))
+		return nil, err
+	}
+
+	replicas := []*sql.DB{}
+
+	for _, connString := range connStrings {
+		db, err := m.connect(connString)
+		if err != nil {
+			for _, replica := range replicas {
+				_ = replica.Close()
+			}
+
+			return nil, err
+		}
+
+		replicas = append(replicas, db)
+	}
+
+	return replicas, nil
+}
+
+// connect opens the connection, applies the pool settings and pings the database
+func (m *MySQLConnection) connect(connString string) (*sql.DB, error) {
+	db, err := m.open(connString)
+	if err != nil {
+		m.logger.Error(FailureConnErrorMessage, zap.Error(err))
+		return db, err
+	}
+
+	pkgSql.ConfigurePool(db, m.cfg.SQLConfigs)
+
+	err = db.Ping()
+	if err != nil {
+		m.logger.Error(FailureConnErrorMessage, zap.Error(err))
+		return db, err
+	}
+
+	return db, nil
+}
+
+// registerTLSConfig maps the SSL mode to the name of a driver TLS configuration.
+// When certificate files are set, or the server certificate chain is verified without
+// verifying the host name, a TLS configuration is registered in the driver, named after its settings.
+func registerTLSConfig(cfg *configs.SQLConfigs) (string, error) {
+	custom := cfg.SSLRootCert != "" || cfg.SSLCert != ""
+
+	switch cfg.SSLMode {
+	case "", "disable":
+		return "false", nil
+	case "allow", "prefer":
+		return "preferred", nil
+	case "require":
+		if !custom {
+			return "skip-verify", nil
+		}
+	case "verify-full":
+		if !custom {
+			return "true", nil
+		}
+	case "verify-ca":
+	default:
+		return "", fmt.Errorf("invalid ssl mode %q", cfg.SSLMode)
+	}
+
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.SSLRootCert != "" {
+		pem, err := os.ReadFile(cfg.SSLRootCert)
+		if err != nil {
+			return "", err
+		}
+
+		tlsCfg.RootCAs = x509.NewCertPool()
+		if !tlsCfg.RootCAs.AppendCertsFromPEM(pem) {
+			return "", fmt.Errorf("no certificate found in %s", cfg.SSLRootCert)
+		}
+	}
+
+	if cfg.SSLCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.SSLCert, cfg.SSLKey)
+		if err != nil {
+			return "", err
+		}
+
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	switch cfg.SSLMode {
+	case "require":
+		// As in PostgreSQL, require encrypts the connection without verifying the server
+		tlsCfg.InsecureSkipVerify = true
+	case "verify-ca":
+		// The default verification also checks the host name, so the chain is verified on its own
+		tlsCfg.InsecureSkipVerify = true
+		tlsCfg.VerifyPeerCertificate = verifyChain(tlsCfg.RootCAs)
+	}
+
+	h := fnv.New32a()
+	_, _ = fmt.Fprintf(h, "%s|%s|%s|%s", cfg.SSLMode, cfg.SSLRootCert, cfg.SSLCert, cfg.SSLKey)
+	name := fmt.Sprintf("gokit-%x", h.Sum32())
+
+	if err := driver.RegisterTLSConfig(name, tlsCfg); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// verifyChain verifies the server certificate chain against the certificate authorities,
+// or the system ones when roots is nil, without verifying the host name
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("no server certificate")
+		}
+
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs = append(certs, cert)
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+
+		return err
+	}
+}
@@ -0,0 +1,242 @@
+// Package mysql contains MySQL database integration tests for the GoKit framework.
+// These tests verify the proper functionality of MySQL connection and operations.
+package mysql
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uptrace/opentelemetry-go-extra/otelsql"
+	"go.uber.org/zap"
+
+	mSQL "github.com/ralvescosta/gokit/sql"
+)
+
+// MySQLTestSuite defines the test suite for MySQL database operations.
+// It provides setup and teardown for testing MySQL connections with mocks.
+type MySQLTestSuite struct {
+	suite.Suite
+
+	connector  *mSQL.MockConnector
+	driverConn *mSQL.MockPingDriverConn
+}
+
+// TestMySQLTestSuite runs the MySQL database test suite.
+func TestMySQLTestSuite(t *testing.T) {
+	suite.Run(t, new(MySQLTestSuite))
+}
+
+// SetupTest initializes the mock objects before each test.
+func (s *MySQLTestSuite) SetupTest() {
+	s.connector = &mSQL.MockConnector{}
+	s.driverConn = &mSQL.MockPingDriverConn{}
+}
+
+// TearDownTest restores the open functions replaced by the tests.
+func (s *MySQLTestSuite) TearDownTest() {
+	sqlOpen = sql.Open
+	otelOpen = otelsql.Open
+}
+
+// TestGetConnectionString verifies the DSN built from the configurations.
+func (s *MySQLTestSuite) TestGetConnectionString() {
+	dsn, err := GetConnectionString(&configs.SQLConfigs{
+		Host:             "localhost",
+		User:             "user",
+		Password:         "password",
+		DbName:           "orders",
+		ConnectTimeout:   5 * time.Second,
+		StatementTimeout: 30 * time.Second,
+	})
+	s.Require().NoError(err)
+
+	parsed, err := driver.ParseDSN(dsn)
+	s.Require().NoError(err)
+	s.Equal("user", parsed.User)
+	s.Equal("password", parsed.Passwd)
+	s.Equal("localhost:3306", parsed.Addr)
+	s.Equal("orders", parsed.DBName)
+	s.True(parsed.ParseTime)
+	s.Equal(time.UTC, parsed.Loc)
+	s.Equal(5*time.Second, parsed.Timeout)
+	s.Equal(map[string]string{"max_execution_time": "30000"}, parsed.Params)
+	s.Nil(parsed.TLS)
+
+	dsn, err = GetConnectionString(&configs.SQLConfigs{DSN: "user:password@tcp(db:3306)/orders"})
+	s.Require().NoError(err)
+	s.Equal("user:password@tcp(db:3306)/orders", dsn)
+}
+
+// TestGetConnectionStringTLS verifies the SSL modes are mapped to the driver TLS settings.
+func (s *MySQLTestSuite) TestGetConnectionStringTLS() {
+	for mode, expected := range map[string]string{"prefer": "preferred", "require": "skip-verify", "verify-full": "true"} {
+		dsn, err := GetConnectionString(&configs.SQLConfigs{Host: "db", Port: "3307", SSLMode: mode})
+		s.Require().NoError(err, mode)
+		s.Contains(dsn, "tls="+expected, mode)
+	}
+
+	rootCert := s.writeCertificate()
+
+	dsn, err := GetConnectionString(&configs.SQLConfigs{Host: "db", SSLMode: "verify-ca", SSLRootCert: rootCert})
+	s.Require().NoError(err)
+
+	parsed, err := driver.ParseDSN(dsn)
+	s.Require().NoError(err)
+	s.Require().NotNil(parsed.TLS)
+	s.True(parsed.TLS.InsecureSkipVerify)
+	s.NotNil(parsed.TLS.VerifyPeerCertificate)
+	s.NotNil(parsed.TLS.RootCAs)
+
+	dsn, err = GetConnectionString(&configs.SQLConfigs{Host: "db", SSLMode: "verify-full", SSLRootCert: rootCert})
+	s.Require().NoError(err)
+
+	parsed, err = driver.ParseDSN(dsn)
+	s.Require().NoError(err)
+	s.False(parsed.TLS.InsecureSkipVerify)
+	s.Equal("db", parsed.TLS.ServerName)
+}
+
+// TestGetConnectionStringTLSErrors verifies the invalid SSL settings are reported.
+func (s *MySQLTestSuite) TestGetConnectionStringTLSErrors() {
+	_, err := GetConnectionString(&configs.SQLConfigs{SSLMode: "strict"})
+	s.Error(err)
+
+	_, err = GetConnectionString(&configs.SQLConfigs{SSLMode: "verify-ca", SSLRootCert: filepath.Join(s.T().TempDir(), "missing.pem")})
+	s.Error(err)
+
+	invalid := filepath.Join(s.T().TempDir(), "invalid.pem")
+	s.Require().NoError(os.WriteFile(invalid, []byte("invalid"), 0o600))
+
+	_, err = GetConnectionString(&configs.SQLConfigs{SSLMode: "verify-ca", SSLRootCert: invalid})
+	s.Error(err)
+}
+
+// TestGetReplicaConnectionStrings verifies the DSN of the read replicas.
+func (s *MySQLTestSuite) TestGetReplicaConnectionStrings() {
+	dsns, err := GetReplicaConnectionStrings(&configs.SQLConfigs{
+		Host:         "primary",
+		Port:         "3307",
+		User:         "user",
+		DbName:       "orders",
+		ReplicaHosts: []string{"replica-1", "replica-2:3308"},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(dsns, 2)
+
+	first, err := driver.ParseDSN(dsns[0])
+	s.Require().NoError(err)
+	s.Equal("replica-1:3307", first.Addr)
+	s.Equal("orders", first.DBName)
+
+	second, err := driver.ParseDSN(dsns[1])
+	s.Require().NoError(err)
+	s.Equal("replica-2:3308", second.Addr)
+}
+
+// TestConnect tests the OpenTelemetry-enabled connection process.
+func (s *MySQLTestSuite) TestConnect() {
+	s.driverConn.On("Ping", mock.AnythingOfType("context.backgroundCtx")).Return(nil)
+	s.connector.On("Connect", mock.AnythingOfType("context.backgroundCtx")).Return(s.driverConn, nil)
+
+	otelOpen = func(driverName, dsn string, opts ...otelsql.Option) (*sql.DB, error) {
+		s.Equal("mysql", driverName)
+		return sql.OpenDB(s.connector), nil
+	}
+
+	conn := New(&configs.Configs{
+		TracingConfigs: &configs.TracingConfigs{Enabled: true},
+		SQLConfigs:     &configs.SQLConfigs{Host: "localhost", MaxOpenConns: 10},
+	})
+
+	db, err := conn.Connect()
+
+	s.NoError(err)
+	s.Equal(10, db.Stats().MaxOpenConnections)
+	s.driverConn.AssertExpectations(s.T())
+	s.connector.AssertExpectations(s.T())
+}
+
+// TestConnectPingErr tests error handling when the database ping fails.
+func (s *MySQLTestSuite) TestConnectPingErr() {
+	s.driverConn.On("Ping", mock.AnythingOfType("context.backgroundCtx")).Return(errors.New("ping err"))
+	s.connector.On("Connect", mock.AnythingOfType("context.backgroundCtx")).Return(s.driverConn, nil)
+
+	sqlOpen = func(driverName, dsn string) (*sql.DB, error) {
+		return sql.OpenDB(s.connector), nil
+	}
+
+	conn := New(&configs.Configs{Logger: zap.NewNop(), SQLConfigs: &configs.SQLConfigs{Host: "localhost"}})
+
+	_, err := conn.Connect()
+
+	s.Error(err)
+}
+
+// TestConnectConnectionStringErr tests that Connect returns the error of the connection string.
+func (s *MySQLTestSuite) TestConnectConnectionStringErr() {
+	conn := New(&configs.Configs{Logger: zap.NewNop(), SQLConfigs: &configs.SQLConfigs{SSLMode: "strict"}})
+
+	_, err := conn.Connect()
+
+	s.Error(err)
+	s.Equal(conn.Err, err)
+}
+
+// TestConnectReplicas verifies a connection is established for each read replica.
+func (s *MySQLTestSuite) TestConnectReplicas() {
+	s.driverConn.On("Ping", mock.AnythingOfType("context.backgroundCtx")).Return(nil)
+	s.connector.On("Connect", mock.AnythingOfType("context.backgroundCtx")).Return(s.driverConn, nil)
+
+	dsns := []string{}
+	sqlOpen = func(driverName, dsn string) (*sql.DB, error) {
+		dsns = append(dsns, dsn)
+		return sql.OpenDB(s.connector), nil
+	}
+
+	conn := New(&configs.Configs{SQLConfigs: &configs.SQLConfigs{Host: "primary", ReplicaHosts: []string{"replica-1"}}})
+
+	replicas, err := conn.ConnectReplicas()
+
+	s.NoError(err)
+	s.Len(replicas, 1)
+	s.Contains(dsns[0], "tcp(replica-1:3306)")
+}
+
+// writeCertificate writes a self-signed certificate authority and returns its path.
+func (s *MySQLTestSuite) writeCertificate() string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "gokit-test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	s.Require().NoError(err)
+
+	path := filepath.Join(s.T().TempDir(), "ca.pem")
+	s.Require().NoError(os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	return path
+}
@@ -8,6 +8,7 @@
 package pg
 
 import (
+	"context"
 	"database/sql"
 
 	_ "github.com/lib/pq"
@@ -18,6 +19,7 @@ import (
 	"go.uber.org/zap"
 
 	pkgSql "github.com/ralvescosta/gokit/sql"
+	"github.com/ralvescosta/gokit/sql/migrate"
 )
 
 type (
@@ -34,6 +36,8 @@ type (
 		conn *sql.DB
 		// cfg holds the application configurations
 		cfg *configs.Configs
+		// migrator applies the migrations once connected, when set
+		migrator *migrate.Migrator
 	}
 )
 
@@ -44,6 +48,8 @@ var otelOpen = otelsql.Open
 const (
 	// FailureConnErrorMessage is the standard error message used when connection fails
 	FailureConnErrorMessage = "[PostgreSQL::Connect] failure to connect to the database"
+	// FailureMigrationErrorMessage is the standard error message used when the migrations fail
+	FailureMigrationErrorMessage = "[PostgreSQL::Connect] failure to migrate the database"
 )
 
 // New creates a new PostgreSQL connection instance with the provided configurations.
@@ -67,33 +73,92 @@ func New(cfgs *configs.Configs) *PostgresSqlConnection {
 // open establishes a database connection using either standard or
 // OpenTelemetry-instrumented connection methods based on configuration.
 //
+// Parameters:
+//   - connString: The connection string of the primary or of a read replica
+//
 // Returns:
 //   - A database connection and any error that occurred
-func (pg *PostgresSqlConnection) open() (*sql.DB, error) {
-	if pg.cfg.TracingConfigs.Enabled {
+func (pg *PostgresSqlConnection) open(connString string) (*sql.DB, error) {
+	if pg.cfg.TracingConfigs != nil && pg.cfg.TracingConfigs.Enabled {
 		return otelOpen(
 			"postgres",
-			pg.connectionString,
+			connString,
 			otelsql.WithAttributes(semconv.DBSystemSqlite),
 			otelsql.WithDBName(pg.cfg.SQLConfigs.DbName),
 		)
 	}
 
-	return sqlOpen("postgres", pg.connectionString)
+	return sqlOpen("postgres", connString)
+}
+
+// WithMigrations makes Connect apply the migrations with the migrator once connected.
+// The read replicas are not migrated.
+//
+// Parameters:
+//   - migrator: The migrator holding the migrations, target version and dry-run mode
+//
+// Returns:
+//   - The PostgresSqlConnection instance
+func (pg *PostgresSqlConnection) WithMigrations(migrator *migrate.Migrator) *PostgresSqlConnection {
+	pg.migrator = migrator
+	return pg
 }
 
-// Connect establishes a connection to the PostgreSQL database and
-// verifies connectivity with a ping.
+// Connect establishes a connection to the PostgreSQL database, applies the
+// connection pool settings, verifies connectivity with a ping and, when
+// enabled with WithMigrations, migrates the database.
 //
 // Returns:
 //   - A connected database instance and any error that occurred
 func (pg *PostgresSqlConnection) Connect() (*sql.DB, error) {
-	db, err := pg.open()
+	db, err := pg.connect(pg.connectionString)
+	if err != nil || pg.migrator == nil {
+		return db, err
+	}
+
+	if _, err := pg.migrator.Migrate(context.Background(), db); err != nil {
+		pg.logger.Error(FailureMigrationErrorMessage, zap.Error(err))
+		return db, err
+	}
+
+	return db, nil
+}
+
+// ConnectReplicas establishes a connection to each read replica of the configurations,
+// sharing the settings of the primary, and verifies their connectivity with a ping.
+// The connections already established are closed when a replica fails.
+//
+// Returns:
+//   - The connected replicas, empty when there is no replica, and any error that occurred
+func (pg *PostgresSqlConnection) ConnectReplicas() ([]*sql.DB, error) {
+	replicas := []*sql.DB{}
+
+	for _, connString := range pkgSql.GetReplicaConnectionStrings(pg.cfg.SQLConfigs) {
+		db, err := pg.connect(connString)
+		if err != nil {
+			for _, replica := range replicas {
+				_ = replica.Close()
+			}
+
+			return nil, err
+		}
+
+		replicas = append(replicas, db)
+	}
+
+	return replicas, nil
+}
+
+// connect opens the connection, applies the pool settings and pings the database
+func (pg *PostgresSqlConnection) connect(connString string) (*sql.DB, error) {
+	db, err := pg.open(connString)
 	if err != nil {
 		pg.logger.Error(FailureConnErrorMessage, zap.Error(err))
 		return db, err
 	}
 
+	pkgSql.ConfigurePool(db, pg.cfg.SQLConfigs)
+
 	err = db.Ping()
 	if err != nil {
 		pg.logger.Error(FailureConnErrorMessage, zap.Error(err))
@@ -4,14 +4,20 @@ package pg
 
 import (
 	"database/sql"
+	"errors"
+	"regexp"
 	"testing"
+	"testing/fstest"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/ralvescosta/gokit/configs"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"github.com/uptrace/opentelemetry-go-extra/otelsql"
+	"go.uber.org/zap"
 
 	mSQL "github.com/ralvescosta/gokit/sql"
+	"github.com/ralvescosta/gokit/sql/migrate"
 )
 
 // PostgresSqlTestSuite defines the test suite for PostgreSQL database operations.
@@ -62,6 +68,82 @@ func (s *PostgresSqlTestSuite) TestOpen() {
 	s.connector.AssertExpectations(s.T())
 }
 
+// TestConnectAppliesPool verifies the pool settings are applied to the connected database.
+func (s *PostgresSqlTestSuite) TestConnectAppliesPool() {
+	s.driverConn.On("Ping", mock.AnythingOfType("context.backgroundCtx")).Return(nil)
+	s.connector.On("Connect", mock.AnythingOfType("context.backgroundCtx")).Return(s.driverConn, nil)
+
+	sqlOpen = func(driverName, dsn string) (*sql.DB, error) {
+		return sql.OpenDB(s.connector), nil
+	}
+	defer func() { sqlOpen = sql.Open }()
+
+	conn := New(&configs.Configs{SQLConfigs: &configs.SQLConfigs{MaxOpenConns: 10}})
+
+	db, err := conn.Connect()
+
+	s.NoError(err)
+	s.Equal(10, db.Stats().MaxOpenConnections)
+}
+
+// TestConnectReplicas verifies a connection is established for each read replica.
+func (s *PostgresSqlTestSuite) TestConnectReplicas() {
+	s.driverConn.On("Ping", mock.AnythingOfType("context.backgroundCtx")).Return(nil)
+	s.connector.On("Connect", mock.AnythingOfType("context.backgroundCtx")).Return(s.driverConn, nil)
+
+	dsns := []string{}
+	sqlOpen = func(driverName, dsn string) (*sql.DB, error) {
+		dsns = append(dsns, dsn)
+		return sql.OpenDB(s.connector), nil
+	}
+	defer func() { sqlOpen = sql.Open }()
+
+	conn := New(&configs.Configs{SQLConfigs: &configs.SQLConfigs{Host: "primary", Port: "5432", ReplicaHosts: []string{"replica-1", "replica-2"}}})
+
+	replicas, err := conn.ConnectReplicas()
+
+	s.NoError(err)
+	s.Len(replicas, 2)
+	s.Len(dsns, 2)
+	s.Contains(dsns[0], "host=replica-1 port=5432")
+	s.Contains(dsns[1], "host=replica-2 port=5432")
+}
+
+// TestConnectReplicasErr verifies the replica connection errors are returned.
+func (s *PostgresSqlTestSuite) TestConnectReplicasErr() {
+	sqlOpen = func(driverName, dsn string) (*sql.DB, error) {
+		return nil, errors.New("open err")
+	}
+	defer func() { sqlOpen = sql.Open }()
+
+	conn := New(&configs.Configs{Logger: zap.NewNop(), SQLConfigs: &configs.SQLConfigs{ReplicaHosts: []string{"replica-1"}}})
+
+	_, err := conn.ConnectReplicas()
+
+	s.EqualError(err, "open err")
+}
+
+// TestConnectWithMigrations verifies the migrations are applied once connected.
+func (s *PostgresSqlTestSuite) TestConnectWithMigrations() {
+	db, sqlMock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	sqlOpen = func(driverName, dsn string) (*sql.DB, error) {
+		return db, nil
+	}
+	defer func() { sqlOpen = sql.Open }()
+
+	sqlMock.ExpectExec(regexp.QuoteMeta("SELECT pg_advisory_lock($1)")).WillReturnError(errors.New("lock err"))
+
+	cfgs := &configs.Configs{Logger: zap.NewNop(), SQLConfigs: &configs.SQLConfigs{}}
+	conn := New(cfgs).WithMigrations(migrate.New(cfgs, fstest.MapFS{}, "."))
+
+	_, err = conn.Connect()
+
+	s.EqualError(err, "lock err")
+	s.NoError(sqlMock.ExpectationsWereMet())
+}
+
 // TestConnectionPing tests the database ping functionality.
 // Currently disabled/commented out.
 func (s *PostgresSqlTestSuite) TestConnectionPing() {
@@ -0,0 +1,198 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package sagastore provides a PostgreSQL implementation of the saga.Store interface,
+// persisting the state of the saga executions of the messaging/saga package.
+//
+// Example:
+//
+//	db, _ := pg.New(cfgs).Connect()
+//	_, _ = db.Exec(sagastore.CreateTableStatement(sagastore.DefaultTable))
+//
+//	orchestrator := saga.NewOrchestrator(cfgs, definition, sagastore.New(db), publisher)
+package sagastore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ralvescosta/gokit/messaging/saga"
+)
+
+// DefaultTable is the name of the table holding the saga states.
+const DefaultTable = "saga_states"
+
+// Store is a saga.Store persisting the states in a PostgreSQL table.
+// Updates use the state version for optimistic locking.
+type Store struct {
+	db    *sql.DB
+	table string
+}
+
+// New creates a Store using the DefaultTable.
+func New(db *sql.DB) *Store {
+	return &Store{db: db, table: DefaultTable}
+}
+
+// WithTable sets the name of the table holding the saga states.
+func (s *Store) WithTable(table string) *Store {
+	s.table = table
+	return s
+}
+
+// CreateTableStatement returns the PostgreSQL statement creating the table holding the saga states.
+//
+// Parameters:
+//   - table: The name of the table, usually DefaultTable.
+//
+// Returns:
+//   - The CREATE TABLE and CREATE INDEX statements.
+func CreateTableStatement(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	saga       TEXT NOT NULL,
+	id         TEXT NOT NULL,
+	status     TEXT NOT NULL,
+	step       INTEGER NOT NULL,
+	data       TEXT NOT NULL DEFAULT '{}',
+	deadline   TIMESTAMPTZ,
+	error      TEXT NOT NULL DEFAULT '',
+	version    INTEGER NOT NULL DEFAULT 0,
+	created_at TIMESTAMPTZ NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (saga, id)
+);
+CREATE INDEX IF NOT EXISTS %[1]s_deadline_idx ON %[1]s (saga, deadline) WHERE status = 'running';`, table)
+}
+
+func (s *Store) Create(ctx context.Context, state *saga.State) error {
+	data, err := json.Marshal(state.Data)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (saga, id, status, step, data, deadline, error, version, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (saga, id) DO NOTHING`, s.table),
+		state.Saga, state.ID, string(state.Status), state.Step, string(data),
+		nullTime(state.Deadline), state.Error, state.Version, state.CreatedAt, state.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return saga.StateAlreadyExistsError
+	}
+
+	return nil
+}
+
+func (s *Store) Load(ctx context.Context, sagaName, id string) (*saga.State, error) {
+	row := s.db.QueryRowContext(ctx, fmt.Sprintf(
+		`SELECT saga, id, status, step, data, deadline, error, version, created_at, updated_at
+		FROM %s WHERE saga = $1 AND id = $2`, s.table),
+		sagaName, id,
+	)
+
+	state, err := scan(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, saga.StateNotFoundError
+	}
+
+	return state, err
+}
+
+func (s *Store) Update(ctx context.Context, state *saga.State) error {
+	data, err := json.Marshal(state.Data)
+	if err != nil {
+		return err
+	}
+
+	result, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		`UPDATE %s SET status = $1, step = $2, data = $3, deadline = $4, error = $5, version = version + 1, updated_at = $6
+		WHERE saga = $7 AND id = $8 AND version = $9`, s.table),
+		string(state.Status), state.Step, string(data), nullTime(state.Deadline), state.Error, state.UpdatedAt,
+		state.Saga, state.ID, state.Version,
+	)
+	if err != nil {
+		return err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return saga.ConcurrentUpdateError
+	}
+
+	state.Version++
+
+	return nil
+}
+
+func (s *Store) Expired(ctx context.Context, sagaName string, now time.Time) ([]*saga.State, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(
+		`SELECT saga, id, status, step, data, deadline, error, version, created_at, updated_at
+		FROM %s WHERE saga = $1 AND status = $2 AND deadline IS NOT NULL AND deadline <= $3`, s.table),
+		sagaName, string(saga.Running), now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	states := []*saga.State{}
+	for rows.Next() {
+		state, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		states = append(states, state)
+	}
+
+	return states, rows.Err()
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scan reads a state from the row.
+func scan(row scanner) (*saga.State, error) {
+	var (
+		state    saga.State
+		status   string
+		data     string
+		deadline sql.NullTime
+	)
+
+	if err := row.Scan(
+		&state.Saga, &state.ID, &status, &state.Step, &data, &deadline,
+		&state.Error, &state.Version, &state.CreatedAt, &state.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(data), &state.Data); err != nil {
+		return nil, err
+	}
+
+	state.Status = saga.Status(status)
+	state.Deadline = deadline.Time
+
+	return &state, nil
+}
+
+// nullTime stores the zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
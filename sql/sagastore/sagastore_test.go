@@ -0,0 +1,126 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sagastore
+
+import (
+	"context"
+	"database/sql"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ralvescosta/gokit/messaging/saga"
+	"github.com/stretchr/testify/suite"
+)
+
+type SagaStoreTestSuite struct {
+	suite.Suite
+
+	sqlMock sqlmock.Sqlmock
+	store   *Store
+	now     time.Time
+	columns []string
+}
+
+func TestSagaStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(SagaStoreTestSuite))
+}
+
+func (s *SagaStoreTestSuite) SetupTest() {
+	db, sqlMock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	s.sqlMock = sqlMock
+	s.store = New(db)
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.columns = []string{"saga", "id", "status", "step", "data", "deadline", "error", "version", "created_at", "updated_at"}
+}
+
+func (s *SagaStoreTestSuite) TearDownTest() {
+	s.NoError(s.sqlMock.ExpectationsWereMet())
+}
+
+func (s *SagaStoreTestSuite) TestCreate() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO saga_states")).
+		WithArgs("order", "1", "running", 0, `{"amount":10}`, sql.NullTime{}, "", 0, s.now, s.now).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := s.store.Create(context.Background(), &saga.State{
+		Saga: "order", ID: "1", Status: saga.Running, Data: map[string]any{"amount": 10}, CreatedAt: s.now, UpdatedAt: s.now,
+	})
+
+	s.NoError(err)
+}
+
+func (s *SagaStoreTestSuite) TestCreateExisting() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO saga_states")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := s.store.Create(context.Background(), &saga.State{Saga: "order", ID: "1"})
+
+	s.ErrorIs(err, saga.StateAlreadyExistsError)
+}
+
+func (s *SagaStoreTestSuite) TestLoad() {
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT saga, id")).
+		WithArgs("order", "1").
+		WillReturnRows(sqlmock.NewRows(s.columns).
+			AddRow("order", "1", "running", 1, `{"charged":true}`, s.now, "", 2, s.now, s.now))
+
+	state, err := s.store.Load(context.Background(), "order", "1")
+
+	s.Require().NoError(err)
+	s.Equal(saga.Running, state.Status)
+	s.Equal(1, state.Step)
+	s.Equal(2, state.Version)
+	s.Equal(true, state.Data["charged"])
+	s.Equal(s.now, state.Deadline)
+}
+
+func (s *SagaStoreTestSuite) TestLoadNotFound() {
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT saga, id")).
+		WillReturnRows(sqlmock.NewRows(s.columns))
+
+	_, err := s.store.Load(context.Background(), "order", "1")
+
+	s.ErrorIs(err, saga.StateNotFoundError)
+}
+
+func (s *SagaStoreTestSuite) TestUpdate() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE saga_states SET")).
+		WithArgs("completed", 1, `{}`, sql.NullTime{}, "", s.now, "order", "1", 3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	state := &saga.State{Saga: "order", ID: "1", Status: saga.Completed, Step: 1, Data: map[string]any{}, Version: 3, UpdatedAt: s.now}
+	err := s.store.Update(context.Background(), state)
+
+	s.NoError(err)
+	s.Equal(4, state.Version)
+}
+
+func (s *SagaStoreTestSuite) TestUpdateConcurrent() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE saga_states SET")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	state := &saga.State{Saga: "order", ID: "1", Version: 3}
+	err := s.store.Update(context.Background(), state)
+
+	s.ErrorIs(err, saga.ConcurrentUpdateError)
+	s.Equal(3, state.Version)
+}
+
+func (s *SagaStoreTestSuite) TestExpired() {
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT saga, id")).
+		WithArgs("order", "running", s.now).
+		WillReturnRows(sqlmock.NewRows(s.columns).
+			AddRow("order", "1", "running", 0, `{}`, s.now, "", 1, s.now, s.now).
+			AddRow("order", "2", "running", 0, `{}`, s.now, "", 1, s.now, s.now))
+
+	states, err := s.store.Expired(context.Background(), "order", s.now)
+
+	s.NoError(err)
+	s.Len(states, 2)
+}
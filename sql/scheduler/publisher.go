@@ -0,0 +1,143 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/logging"
+	"github.com/ralvescosta/gokit/messaging"
+	"go.uber.org/zap"
+)
+
+// Publisher is a messaging.Publisher storing the messages published with a delay in the
+// scheduled messages table. Messages without a delay are sent right away by the decorated publisher.
+//
+// The broker-specific envelope properties (e.g. MQTT QoS) are not stored, so the scheduled
+// messages are published with the decorated publisher defaults.
+type Publisher struct {
+	logger    logging.Logger
+	db        *sql.DB
+	publisher messaging.Publisher
+	table     string
+	now       func() time.Time
+}
+
+// NewPublisher creates a Publisher scheduling the delayed messages in the DefaultTable.
+//
+// Parameters:
+//   - cfgs: Application configurations including the logger.
+//   - db: The database holding the scheduled messages table.
+//   - publisher: The publisher used to send the messages without delay.
+//
+// Returns:
+//   - A new Publisher instance.
+func NewPublisher(cfgs *configs.Configs, db *sql.DB, publisher messaging.Publisher) *Publisher {
+	return &Publisher{
+		logger:    cfgs.Logger,
+		db:        db,
+		publisher: publisher,
+		table:     DefaultTable,
+		now:       time.Now,
+	}
+}
+
+// WithTable sets the name of the table holding the scheduled messages.
+func (p *Publisher) WithTable(table string) *Publisher {
+	p.table = table
+	return p
+}
+
+// Publish schedules or sends the message, adapting the arguments into an envelope.
+// Messages published through this method are always sent right away, since they carry no delay.
+func (p *Publisher) Publish(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
+	return p.PublishEnvelope(ctx, messaging.EnvelopeFromOptions(to, from, key, options...), msg)
+}
+
+// PublishDeadline schedules or sends the message with a 10 seconds deadline.
+func (p *Publisher) PublishDeadline(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Second*10)
+	defer cancel()
+
+	return p.Publish(ctx, to, from, key, msg, options...)
+}
+
+// PublishEnvelope stores the message in the scheduled messages table when the envelope has a delay,
+// otherwise sends it with the decorated publisher.
+//
+// Raw payloads ([]byte and string) are stored as they are, and relayed as []byte, any other value is encoded as JSON and published
+// by the relay as a messaging.EncodedMessage, keeping the type name returned by messaging.TypeName.
+//
+// Returns:
+//   - An error if the message could not be encoded or stored, or the decorated publisher error.
+func (p *Publisher) PublishEnvelope(ctx context.Context, envelope *messaging.Envelope, msg any) error {
+	if envelope == nil || envelope.Delay <= 0 {
+		return p.publisher.PublishEnvelope(ctx, envelope, msg)
+	}
+
+	if envelope.To == "" {
+		return EmptyDestinationError
+	}
+
+	var body []byte
+	raw := true
+	switch m := msg.(type) {
+	case []byte:
+		body = m
+	case string:
+		body = []byte(m)
+	default:
+		var err error
+		if body, err = json.Marshal(msg); err != nil {
+			p.logger.Error(LogMessage("failure to encode the scheduled message"), zap.Error(err))
+			return err
+		}
+		raw = false
+	}
+
+	headers, err := json.Marshal(envelope.Headers)
+	if err != nil {
+		return err
+	}
+
+	deliverAt := p.now().Add(envelope.Delay)
+
+	_, err = p.db.ExecContext(ctx, fmt.Sprintf(
+		`INSERT INTO %s (destination, source, routing_key, headers, message_id, correlation_id, content_type, priority, expiration_ms, message_type, raw, body, deliver_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)`, p.table),
+		envelope.To,
+		envelope.From,
+		envelope.Key,
+		string(headers),
+		envelope.MessageID,
+		envelope.CorrelationID,
+		envelope.ContentType,
+		int16(envelope.Priority),
+		envelope.Expiration.Milliseconds(),
+		messaging.TypeName(msg),
+		raw,
+		body,
+		deliverAt,
+	)
+	if err != nil {
+		p.logger.Error(LogMessage("failure to schedule message"), zap.String("destination", envelope.To), zap.Error(err))
+		return err
+	}
+
+	p.logger.Debug(LogMessage("message scheduled"), zap.String("destination", envelope.To), zap.Time("deliverAt", deliverAt))
+
+	return nil
+}
+
+// Intercept registers the interceptors in the decorated publisher, so they are executed
+// when the messages are sent, including the ones published by the relay.
+func (p *Publisher) Intercept(interceptors ...messaging.PublishInterceptor) {
+	p.publisher.Intercept(interceptors...)
+}
@@ -0,0 +1,266 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/logging"
+	"github.com/ralvescosta/gokit/messaging"
+	"go.uber.org/zap"
+)
+
+// Relay polls the scheduled messages table and publishes the due messages.
+// Messages that fail to be published are kept in the table, with the number of attempts
+// and the last error, and are published again after an exponential backoff. Messages failing
+// the maximum number of attempts are parked: they stay in the table but are no longer published.
+type Relay struct {
+	logger      logging.Logger
+	db          *sql.DB
+	publisher   messaging.Publisher
+	table       string
+	interval    time.Duration
+	batchSize   int
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	now         func() time.Time
+}
+
+// NewRelay creates a Relay publishing the due messages of the DefaultTable with the publisher,
+// polling every DefaultInterval up to DefaultBatchSize messages. Failed messages are published
+// again after DefaultBackoff, doubled after each failure up to DefaultMaxBackoff, and parked
+// after DefaultMaxAttempts attempts.
+//
+// Parameters:
+//   - cfgs: Application configurations including the logger.
+//   - db: The database holding the scheduled messages table.
+//   - publisher: The publisher used to send the due messages.
+//
+// Returns:
+//   - A new Relay instance.
+func NewRelay(cfgs *configs.Configs, db *sql.DB, publisher messaging.Publisher) *Relay {
+	return &Relay{
+		logger:      cfgs.Logger,
+		db:          db,
+		publisher:   publisher,
+		table:       DefaultTable,
+		interval:    DefaultInterval,
+		batchSize:   DefaultBatchSize,
+		maxAttempts: DefaultMaxAttempts,
+		backoff:     DefaultBackoff,
+		maxBackoff:  DefaultMaxBackoff,
+		now:         time.Now,
+	}
+}
+
+// WithTable sets the name of the table holding the scheduled messages.
+func (r *Relay) WithTable(table string) *Relay {
+	r.table = table
+	return r
+}
+
+// WithInterval sets the time between the polls.
+func (r *Relay) WithInterval(interval time.Duration) *Relay {
+	r.interval = interval
+	return r
+}
+
+// WithBatchSize sets the maximum number of messages published on each poll.
+func (r *Relay) WithBatchSize(size int) *Relay {
+	r.batchSize = size
+	return r
+}
+
+// WithMaxAttempts sets the number of times a message is published before being parked.
+func (r *Relay) WithMaxAttempts(attempts int) *Relay {
+	r.maxAttempts = attempts
+	return r
+}
+
+// WithBackoff sets the delay before a failed message is published again,
+// doubled after each failure up to the maximum delay.
+func (r *Relay) WithBackoff(backoff, maxBackoff time.Duration) *Relay {
+	r.backoff = backoff
+	r.maxBackoff = maxBackoff
+	return r
+}
+
+// Run polls the table until the context is canceled.
+// A poll returning a full batch is followed immediately by another one.
+//
+// Returns:
+//   - The context error once it is canceled.
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := r.RelayDue(ctx)
+		if err != nil {
+			r.logger.Error(LogMessage("failure to relay the scheduled messages"), zap.Error(err))
+		}
+
+		if err == nil && published == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayDue publishes the messages whose delivery time, or next attempt after a failure, has passed,
+// up to the batch size, within a transaction locking the selected rows.
+//
+// Returns:
+//   - The number of messages published.
+//   - An error if the messages could not be read or the transaction could not be committed.
+func (r *Relay) RelayDue(ctx context.Context) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	messages, err := r.due(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, m := range messages {
+		if err := r.publisher.PublishEnvelope(ctx, m.envelope, m.message()); err != nil {
+			if err := r.failed(ctx, tx, m, err); err != nil {
+				return published, err
+			}
+
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"UPDATE %s SET attempts = attempts + 1, published_at = $1 WHERE id = $2", r.table),
+			r.now(), m.id,
+		); err != nil {
+			return published, err
+		}
+
+		published++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return published, nil
+}
+
+// failed records the failure to publish the message, scheduling the next attempt after the backoff,
+// or parking the message once the maximum number of attempts is reached.
+func (r *Relay) failed(ctx context.Context, tx *sql.Tx, m *scheduledMessage, publishErr error) error {
+	attempts := m.attempts + 1
+
+	if attempts >= r.maxAttempts {
+		r.logger.Error(LogMessage("scheduled message parked after the maximum attempts"),
+			zap.Int64("id", m.id), zap.Int("attempts", attempts), zap.Error(publishErr))
+
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(
+			"UPDATE %s SET attempts = attempts + 1, last_error = $1, parked_at = $2 WHERE id = $3", r.table),
+			publishErr.Error(), r.now(), m.id,
+		)
+
+		return err
+	}
+
+	backoff := r.backoff
+	for i := 1; i < attempts && backoff < r.maxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, r.maxBackoff)
+
+	r.logger.Warn(LogMessage("failure to publish scheduled message"),
+		zap.Int64("id", m.id), zap.Int("attempts", attempts), zap.Duration("backoff", backoff), zap.Error(publishErr))
+
+	_, err := tx.ExecContext(ctx, fmt.Sprintf(
+		"UPDATE %s SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2 WHERE id = $3", r.table),
+		publishErr.Error(), r.now().Add(backoff), m.id,
+	)
+
+	return err
+}
+
+// scheduledMessage is a due message read from the table.
+type scheduledMessage struct {
+	id       int64
+	attempts int
+	envelope *messaging.Envelope
+	msgType  string
+	raw      bool
+	body     []byte
+}
+
+// message returns the payload published to the broker, named after the type of the scheduled message.
+func (m *scheduledMessage) message() any {
+	if m.raw {
+		return m.body
+	}
+
+	return messaging.EncodedMessage{Type: m.msgType, Body: m.body}
+}
+
+// due reads and locks the due messages.
+func (r *Relay) due(ctx context.Context, tx *sql.Tx) ([]*scheduledMessage, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(
+		`SELECT id, attempts, destination, source, routing_key, headers, message_id, correlation_id, content_type, priority, expiration_ms, message_type, raw, body
+		FROM %s
+		WHERE published_at IS NULL AND parked_at IS NULL AND next_attempt_at <= $1
+		ORDER BY next_attempt_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`, r.table),
+		r.now(), r.batchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*scheduledMessage{}
+	for rows.Next() {
+		var (
+			m            scheduledMessage
+			headers      string
+			priority     int16
+			expirationMs int64
+		)
+
+		e := messaging.NewEnvelope("")
+		if err := rows.Scan(
+			&m.id, &m.attempts, &e.To, &e.From, &e.Key, &headers, &e.MessageID, &e.CorrelationID,
+			&e.ContentType, &priority, &expirationMs, &m.msgType, &m.raw, &m.body,
+		); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(headers), &e.Headers); err != nil {
+			return nil, err
+		}
+
+		e.Priority = uint8(priority)
+		e.Expiration = time.Duration(expirationMs) * time.Millisecond
+		m.envelope = e
+
+		messages = append(messages, &m)
+	}
+
+	return messages, rows.Err()
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+// Package scheduler provides delayed message publishing for the brokers that cannot delay
+// the delivery of a message, such as Kafka and MQTT.
+//
+// The Publisher decorates a messaging.Publisher, storing the envelopes published with a delay
+// (messaging.WithDelay or messaging.WithDeliverAt) in a PostgreSQL table instead of sending them.
+// The Relay polls the table and publishes the due messages with the decorated publisher.
+// Several relays can poll the same table, since the due messages are locked with FOR UPDATE SKIP LOCKED.
+//
+// Example:
+//
+//	db, _ := pg.New(cfgs).Connect()
+//	_, _ = db.Exec(scheduler.CreateTableStatement(scheduler.DefaultTable))
+//
+//	publisher := scheduler.NewPublisher(cfgs, db, kafka.NewPublisher(cfgs))
+//	err := messaging.Publish(ctx, publisher, "payments", timeout, messaging.WithDelay(15*time.Minute))
+//
+//	relay := scheduler.NewRelay(cfgs, db, kafka.NewPublisher(cfgs)).WithInterval(time.Second)
+//	go relay.Run(ctx)
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultTable is the name of the table holding the scheduled messages.
+	DefaultTable = "scheduled_messages"
+	// DefaultInterval is the default time between the relay polls.
+	DefaultInterval = time.Second
+	// DefaultBatchSize is the default number of messages published by the relay on each poll.
+	DefaultBatchSize = 100
+	// DefaultMaxAttempts is the default number of times the relay publishes a message before parking it.
+	DefaultMaxAttempts = 10
+	// DefaultBackoff is the default delay before the relay publishes a failed message again.
+	DefaultBackoff = time.Second
+	// DefaultMaxBackoff is the default limit of the delay, doubled after each failure.
+	DefaultMaxBackoff = 10 * time.Minute
+)
+
+// EmptyDestinationError is returned when a message is scheduled without a destination.
+var EmptyDestinationError = errors.New("scheduled message destination cannot be empty")
+
+// CreateTableStatement returns the PostgreSQL statement creating the table holding the scheduled messages.
+//
+// Parameters:
+//   - table: The name of the table, usually DefaultTable.
+//
+// Returns:
+//   - The CREATE TABLE and CREATE INDEX statements.
+func CreateTableStatement(table string) string {
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %[1]s (
+	id              BIGSERIAL PRIMARY KEY,
+	destination     TEXT NOT NULL,
+	source          TEXT NOT NULL DEFAULT '',
+	routing_key     TEXT NOT NULL DEFAULT '',
+	headers         TEXT NOT NULL DEFAULT '{}',
+	message_id      TEXT NOT NULL DEFAULT '',
+	correlation_id  TEXT NOT NULL DEFAULT '',
+	content_type    TEXT NOT NULL DEFAULT '',
+	priority        SMALLINT NOT NULL DEFAULT 0,
+	expiration_ms   BIGINT NOT NULL DEFAULT 0,
+	message_type    TEXT NOT NULL DEFAULT '',
+	raw             BOOLEAN NOT NULL DEFAULT FALSE,
+	body            BYTEA NOT NULL,
+	deliver_at      TIMESTAMPTZ NOT NULL,
+	attempts        INTEGER NOT NULL DEFAULT 0,
+	next_attempt_at TIMESTAMPTZ NOT NULL,
+	last_error      TEXT NOT NULL DEFAULT '',
+	published_at    TIMESTAMPTZ,
+	parked_at       TIMESTAMPTZ,
+	created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX IF NOT EXISTS %[1]s_due_idx ON %[1]s (next_attempt_at) WHERE published_at IS NULL AND parked_at IS NULL;`, table)
+}
+
+// LogMessage formats a log message with the scheduler package prefix.
+func LogMessage(msg ...string) string {
+	f := "[gokit::scheduler] "
+
+	for _, s := range msg {
+		f += s
+	}
+
+	return f
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/ralvescosta/gokit/configs"
+	"github.com/ralvescosta/gokit/messaging"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type SchedulerTestSuite struct {
+	suite.Suite
+
+	cfgs      *configs.Configs
+	sqlMock   sqlmock.Sqlmock
+	publisher *fakePublisher
+	scheduler *Publisher
+	relay     *Relay
+	now       time.Time
+}
+
+func TestSchedulerTestSuite(t *testing.T) {
+	suite.Run(t, new(SchedulerTestSuite))
+}
+
+func (s *SchedulerTestSuite) SetupTest() {
+	db, sqlMock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	s.now = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.sqlMock = sqlMock
+	s.cfgs = &configs.Configs{Logger: zap.NewNop()}
+	s.publisher = &fakePublisher{}
+
+	s.scheduler = NewPublisher(s.cfgs, db, s.publisher)
+	s.scheduler.now = func() time.Time { return s.now }
+
+	s.relay = NewRelay(s.cfgs, db, s.publisher).WithBatchSize(10)
+	s.relay.now = func() time.Time { return s.now }
+}
+
+func (s *SchedulerTestSuite) TearDownTest() {
+	s.NoError(s.sqlMock.ExpectationsWereMet())
+}
+
+func (s *SchedulerTestSuite) TestPublishWithoutDelay() {
+	err := messaging.Publish(context.Background(), s.scheduler, "orders", map[string]string{"id": "1"})
+
+	s.NoError(err)
+	s.Len(s.publisher.published, 1)
+}
+
+func (s *SchedulerTestSuite) TestPublishWithDelay() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO scheduled_messages")).
+		WithArgs("orders", "", "created", `{"tenant":"a"}`, "", "", "", int16(0), int64(0), "map[string]string", false, []byte(`{"id":"1"}`), s.now.Add(time.Minute)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := messaging.Publish(context.Background(), s.scheduler, "orders", map[string]string{"id": "1"},
+		messaging.WithKey("created"),
+		messaging.WithHeader("tenant", "a"),
+		messaging.WithDelay(time.Minute),
+	)
+
+	s.NoError(err)
+	s.Empty(s.publisher.published)
+}
+
+func (s *SchedulerTestSuite) TestPublishWithDelayAndEmptyDestination() {
+	err := messaging.Publish(context.Background(), s.scheduler, "", []byte("raw"), messaging.WithDelay(time.Minute))
+
+	s.ErrorIs(err, EmptyDestinationError)
+}
+
+func (s *SchedulerTestSuite) TestRelayDue() {
+	columns := []string{"id", "attempts", "destination", "source", "routing_key", "headers", "message_id", "correlation_id", "content_type", "priority", "expiration_ms", "message_type", "raw", "body"}
+
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, attempts, destination")).
+		WithArgs(s.now, 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, 0, "orders", "", "created", `{"tenant":"a"}`, "id-1", "", "", 0, 0, "orders.created.v1", false, []byte(`{"id":"1"}`)).
+			AddRow(2, 0, "orders", "", "created", `{}`, "id-2", "", "", 0, 1000, "[]uint8", true, []byte("raw")))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE scheduled_messages SET attempts = attempts + 1, published_at")).
+		WithArgs(s.now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE scheduled_messages SET attempts = attempts + 1, published_at")).
+		WithArgs(s.now, int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.sqlMock.ExpectCommit()
+
+	published, err := s.relay.RelayDue(context.Background())
+
+	s.NoError(err)
+	s.Equal(2, published)
+	s.Require().Len(s.publisher.published, 2)
+	s.Equal("a", s.publisher.published[0].envelope.Headers["tenant"])
+	s.Equal(`{"id":"1"}`, string(s.publisher.published[0].msg.([]byte)))
+	s.Equal("orders.created.v1", s.publisher.published[0].msgType)
+	s.Equal([]byte("raw"), s.publisher.published[1].msg)
+	s.Equal(time.Second, s.publisher.published[1].envelope.Expiration)
+}
+
+func (s *SchedulerTestSuite) TestRelayStringPayload() {
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO scheduled_messages")).
+		WithArgs("orders", "", "", "{}", "", "", "", int16(0), int64(0), "string", true, []byte("raw"), s.now.Add(time.Minute)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := messaging.Publish(context.Background(), s.scheduler, "orders", "raw", messaging.WithDelay(time.Minute))
+	s.Require().NoError(err)
+
+	columns := []string{"id", "attempts", "destination", "source", "routing_key", "headers", "message_id", "correlation_id", "content_type", "priority", "expiration_ms", "message_type", "raw", "body"}
+
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, attempts, destination")).
+		WithArgs(s.now, 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, 0, "orders", "", "", `{}`, "", "", "", 0, 0, "string", true, []byte("raw")))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE scheduled_messages SET attempts = attempts + 1, published_at")).
+		WithArgs(s.now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.sqlMock.ExpectCommit()
+
+	published, err := s.relay.RelayDue(context.Background())
+
+	s.NoError(err)
+	s.Equal(1, published)
+	s.Require().Len(s.publisher.published, 1)
+	s.Equal([]byte("raw"), s.publisher.published[0].msg)
+}
+
+func (s *SchedulerTestSuite) TestRelayDueWithPublishFailure() {
+	columns := []string{"id", "attempts", "destination", "source", "routing_key", "headers", "message_id", "correlation_id", "content_type", "priority", "expiration_ms", "message_type", "raw", "body"}
+	s.publisher.err = errors.New("broker unavailable")
+
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, attempts, destination")).
+		WithArgs(s.now, 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, 0, "orders", "", "", `{}`, "", "", "", 0, 0, "[]uint8", true, []byte("raw")).
+			AddRow(2, 3, "orders", "", "", `{}`, "", "", "", 0, 0, "[]uint8", true, []byte("raw")))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE scheduled_messages SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2")).
+		WithArgs("broker unavailable", s.now.Add(time.Second), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE scheduled_messages SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2")).
+		WithArgs("broker unavailable", s.now.Add(8*time.Second), int64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.sqlMock.ExpectCommit()
+
+	published, err := s.relay.RelayDue(context.Background())
+
+	s.NoError(err)
+	s.Equal(0, published)
+}
+
+func (s *SchedulerTestSuite) TestRelayDueWithMaxBackoff() {
+	columns := []string{"id", "attempts", "destination", "source", "routing_key", "headers", "message_id", "correlation_id", "content_type", "priority", "expiration_ms", "message_type", "raw", "body"}
+	s.publisher.err = errors.New("broker unavailable")
+	s.relay.WithMaxAttempts(20).WithBackoff(time.Second, time.Minute)
+
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, attempts, destination")).
+		WithArgs(s.now, 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, 15, "orders", "", "", `{}`, "", "", "", 0, 0, "[]uint8", true, []byte("raw")))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE scheduled_messages SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2")).
+		WithArgs("broker unavailable", s.now.Add(time.Minute), int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.sqlMock.ExpectCommit()
+
+	_, err := s.relay.RelayDue(context.Background())
+
+	s.NoError(err)
+}
+
+func (s *SchedulerTestSuite) TestRelayDueParksAfterMaxAttempts() {
+	columns := []string{"id", "attempts", "destination", "source", "routing_key", "headers", "message_id", "correlation_id", "content_type", "priority", "expiration_ms", "message_type", "raw", "body"}
+	s.publisher.err = errors.New("broker unavailable")
+	s.relay.WithMaxAttempts(3)
+
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectQuery(regexp.QuoteMeta("SELECT id, attempts, destination")).
+		WithArgs(s.now, 10).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(1, 2, "orders", "", "", `{}`, "", "", "", 0, 0, "[]uint8", true, []byte("raw")))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("UPDATE scheduled_messages SET attempts = attempts + 1, last_error = $1, parked_at = $2")).
+		WithArgs("broker unavailable", s.now, int64(1)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	s.sqlMock.ExpectCommit()
+
+	published, err := s.relay.RelayDue(context.Background())
+
+	s.NoError(err)
+	s.Equal(0, published)
+}
+
+type (
+	fakePublisher struct {
+		published []*fakePublished
+		err       error
+	}
+
+	fakePublished struct {
+		envelope *messaging.Envelope
+		msgType  string
+		msg      any
+	}
+)
+
+func (f *fakePublisher) Publish(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
+	return f.PublishEnvelope(ctx, messaging.EnvelopeFromOptions(to, from, key, options...), msg)
+}
+
+func (f *fakePublisher) PublishDeadline(ctx context.Context, to, from, key *string, msg any, options ...*messaging.Option) error {
+	return f.Publish(ctx, to, from, key, msg, options...)
+}
+
+func (f *fakePublisher) PublishEnvelope(_ context.Context, envelope *messaging.Envelope, msg any) error {
+	if f.err != nil {
+		return f.err
+	}
+
+	msgType := messaging.TypeName(msg)
+	if raw, ok := msg.(interface{ MarshalJSON() ([]byte, error) }); ok {
+		msg, _ = raw.MarshalJSON()
+	}
+
+	f.published = append(f.published, &fakePublished{envelope: envelope, msgType: msgType, msg: msg})
+	return nil
+}
+
+func (f *fakePublisher) Intercept(...messaging.PublishInterceptor) {}
@@ -8,13 +8,28 @@
 package sql
 
 import (
+	"database/sql"
 	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"strings"
 
 	"github.com/ralvescosta/gokit/configs"
 )
 
+// DefaultSSLMode is the SSL mode used when SQLConfigs.SSLMode is not set
+const DefaultSSLMode = "disable"
+
+// pqSSLModes maps the SSL modes rejected by lib/pq, which cannot fall back from an encrypted to a plain
+// connection or the opposite, to the mode of the connection tried first: allow to disable and prefer to require.
+var pqSSLModes = map[string]string{"allow": "disable", "prefer": "require"}
+
 // GetConnectionString creates a formatted connection string using the provided SQL configurations.
-// This function returns a connection string compatible with PostgreSQL drivers.
+// This function returns a connection string compatible with PostgreSQL drivers. When the DSN is set,
+// it is returned as it is. Otherwise the connection string holds the connection parameters, the SSL
+// settings, the timeouts and the search path, quoting the values containing spaces or quotes.
+// The allow and prefer SSL modes, not supported by lib/pq, are replaced by disable and require.
 //
 // Parameters:
 //   - cfg: SQL configuration containing host, port, user, password, and database name.
@@ -22,12 +37,136 @@ import (
 // Returns:
 //   - A formatted connection string ready to be used with SQL drivers.
 func GetConnectionString(cfg *configs.SQLConfigs) string {
-	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		cfg.Host,
-		cfg.Port,
-		cfg.User,
-		cfg.Password,
-		cfg.DbName,
-	)
+	if cfg.DSN != "" {
+		return cfg.DSN
+	}
+
+	sslMode := cfg.SSLMode
+	if sslMode == "" {
+		sslMode = DefaultSSLMode
+	}
+	if mode, ok := pqSSLModes[sslMode]; ok {
+		sslMode = mode
+	}
+
+	params := []string{
+		param("host", cfg.Host),
+		param("port", cfg.Port),
+		param("user", cfg.User),
+		param("password", cfg.Password),
+		param("dbname", cfg.DbName),
+		param("sslmode", sslMode),
+	}
+
+	optional := []struct{ key, value string }{
+		{"sslrootcert", cfg.SSLRootCert},
+		{"sslcert", cfg.SSLCert},
+		{"sslkey", cfg.SSLKey},
+		{"search_path", cfg.SearchPath},
+	}
+
+	for _, p := range optional {
+		if p.value != "" {
+			params = append(params, param(p.key, p.value))
+		}
+	}
+
+	if cfg.ConnectTimeout > 0 {
+		// connect_timeout is expressed in seconds, rounded up so sub-second timeouts are not disabled
+		params = append(params, fmt.Sprintf("connect_timeout=%d", int(math.Ceil(cfg.ConnectTimeout.Seconds()))))
+	}
+
+	if cfg.StatementTimeout > 0 {
+		params = append(params, fmt.Sprintf("statement_timeout=%d", cfg.StatementTimeout.Milliseconds()))
+	}
+
+	return strings.Join(params, " ")
+}
+
+// GetReplicaConnectionStrings creates the connection strings of the read replicas, one per
+// SQLConfigs.ReplicaHosts entry, sharing every setting of the primary but the host and the port.
+// A replica without port uses the port of the primary. When the DSN is set, the host of the
+// DSN URL is replaced, or the host and port parameters are appended to the key/value DSN.
+//
+// Parameters:
+//   - cfg: SQL configuration containing the read replicas
+//
+// Returns:
+//   - The connection strings of the read replicas, empty when there is no replica
+func GetReplicaConnectionStrings(cfg *configs.SQLConfigs) []string {
+	connStrings := make([]string, 0, len(cfg.ReplicaHosts))
+
+	for _, replica := range cfg.ReplicaHosts {
+		host, port := replica, cfg.Port
+		if h, p, err := net.SplitHostPort(replica); err == nil {
+			host, port = h, p
+		}
+
+		if cfg.DSN == "" {
+			replicaCfg := *cfg
+			replicaCfg.Host, replicaCfg.Port = host, port
+			connStrings = append(connStrings, GetConnectionString(&replicaCfg))
+			continue
+		}
+
+		if u, err := url.Parse(cfg.DSN); err == nil && u.Scheme != "" {
+			if port == "" {
+				port = u.Port()
+			}
+
+			u.Host = host
+			if port != "" {
+				u.Host = net.JoinHostPort(host, port)
+			}
+
+			connStrings = append(connStrings, u.String())
+			continue
+		}
+
+		// The last occurrence of a parameter wins in the key/value connection strings
+		connString := cfg.DSN + " " + param("host", host)
+		if port != "" {
+			connString += " " + param("port", port)
+		}
+
+		connStrings = append(connStrings, connString)
+	}
+
+	return connStrings
+}
+
+// ConfigurePool applies the connection pool settings of the configurations to the database.
+// The settings left zero keep the database/sql defaults.
+//
+// Parameters:
+//   - db: The database connection pool
+//   - cfg: SQL configuration containing the pool settings
+func ConfigurePool(db *sql.DB, cfg *configs.SQLConfigs) {
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+
+	if cfg.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+}
+
+// param formats a key/value connection parameter, quoting the empty values
+// and the values containing spaces, quotes or backslashes
+func param(key, value string) string {
+	if value != "" && !strings.ContainsAny(value, " '\\") {
+		return key + "=" + value
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+
+	return key + "='" + escaped + "'"
 }
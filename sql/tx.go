@@ -0,0 +1,221 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// TxFunc is the function run within a transaction by WithTx.
+	// The context holds the transaction, so the nested WithTx calls reuse it.
+	TxFunc func(ctx context.Context, tx *sql.Tx) error
+
+	// TxOptions holds the settings of the transactions started by WithTx.
+	TxOptions struct {
+		// Isolation is the isolation level, the driver default when zero
+		Isolation sql.IsolationLevel
+		// ReadOnly starts a read-only transaction
+		ReadOnly bool
+		// MaxAttempts is the number of times the transaction is run, DefaultTxMaxAttempts when zero
+		MaxAttempts int
+		// Backoff is the delay before the first retry, doubled on each retry, DefaultTxBackoff when zero
+		Backoff time.Duration
+	}
+
+	// txState is the transaction stored in the context.
+	txState struct {
+		tx    *sql.Tx
+		depth int
+	}
+
+	// txKey is the context key of the transaction.
+	txKey struct{}
+
+	// sqlStateError is implemented by the driver errors exposing the SQLSTATE code, such as *pq.Error.
+	sqlStateError interface {
+		SQLState() string
+	}
+)
+
+const (
+	// DefaultTxMaxAttempts is the default number of times WithTx runs a transaction
+	DefaultTxMaxAttempts = 3
+	// DefaultTxBackoff is the default delay before WithTx retries a transaction
+	DefaultTxBackoff = 50 * time.Millisecond
+
+	// tracerName is the instrumentation name of the transaction spans
+	tracerName = "github.com/ralvescosta/gokit/sql"
+)
+
+// retryableSQLStates are the PostgreSQL serialization failure and deadlock detected codes.
+var retryableSQLStates = map[string]bool{"40001": true, "40P01": true}
+
+// WithTx runs the function within a transaction, committing it when the function succeeds and
+// rolling it back when the function fails or panics. The transaction is stored in the context
+// passed to the function, so a nested WithTx call reuses it, running its function within a savepoint
+// rolled back on failure. The options of the nested calls are ignored.
+//
+// When the transaction fails with a PostgreSQL serialization failure (40001) or deadlock (40P01),
+// the whole transaction is run again, up to the maximum number of attempts, waiting an exponential
+// backoff between the attempts. The function must therefore be safe to retry.
+//
+// Each transaction and savepoint is recorded as a span of the global tracer provider.
+//
+// Parameters:
+//   - ctx: The context of the transaction
+//   - db: The database the transaction is started on
+//   - opts: The transaction settings, nil for the defaults
+//   - fn: The function run within the transaction
+//
+// Returns:
+//   - The error of the function, or of the transaction when it could not be started or committed
+func WithTx(ctx context.Context, db *sql.DB, opts *TxOptions, fn TxFunc) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return withSavepoint(ctx, state, fn)
+	}
+
+	if opts == nil {
+		opts = &TxOptions{}
+	}
+
+	maxAttempts := opts.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultTxMaxAttempts
+	}
+
+	backoff := opts.Backoff
+	if backoff <= 0 {
+		backoff = DefaultTxBackoff
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "sql.transaction", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	for attempt := 1; ; attempt++ {
+		span.SetAttributes(attribute.Int("db.transaction.attempts", attempt))
+
+		err := runTx(ctx, db, opts, fn)
+		if err == nil {
+			span.SetStatus(codes.Ok, "")
+			return nil
+		}
+
+		if !IsRetryableTxError(err) || attempt == maxAttempts {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			return err
+		}
+
+		span.AddEvent("retry", trace.WithAttributes(attribute.String("error", err.Error())))
+
+		select {
+		case <-ctx.Done():
+			span.RecordError(ctx.Err())
+			span.SetStatus(codes.Error, ctx.Err().Error())
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// TxFromContext returns the transaction stored in the context by WithTx.
+//
+// Returns:
+//   - The transaction and true, or nil and false when the context holds no transaction
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+
+	return state.tx, true
+}
+
+// IsRetryableTxError reports whether the error is a PostgreSQL serialization failure (40001)
+// or deadlock detected (40P01), after which the transaction can be run again.
+func IsRetryableTxError(err error) bool {
+	var stateErr sqlStateError
+	if !errors.As(err, &stateErr) {
+		return false
+	}
+
+	return retryableSQLStates[stateErr.SQLState()]
+}
+
+// runTx runs the function within a new transaction, committing it on success
+func runTx(ctx context.Context, db *sql.DB, opts *TxOptions, fn TxFunc) (err error) {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: opts.Isolation, ReadOnly: opts.ReadOnly})
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: tx}), tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// withSavepoint runs the function within a savepoint of the transaction, rolling back to it on failure
+func withSavepoint(ctx context.Context, state *txState, fn TxFunc) (err error) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, "sql.savepoint", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	savepoint := fmt.Sprintf("gokit_savepoint_%d", state.depth+1)
+	span.SetAttributes(attribute.String("db.savepoint", savepoint))
+
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}()
+
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint)
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{tx: state.tx, depth: state.depth + 1}), state.tx); err != nil {
+		if _, rollbackErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rollbackErr != nil {
+			return errors.Join(err, rollbackErr)
+		}
+
+		return err
+	}
+
+	_, err = state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint)
+
+	return err
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2023, The GoKit Authors
+// MIT License
+// All rights reserved.
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TxTestSuite defines the test suite for the transaction helper.
+type TxTestSuite struct {
+	suite.Suite
+
+	db       *sql.DB
+	sqlMock  sqlmock.Sqlmock
+	recorder *tracetest.SpanRecorder
+	previous trace.TracerProvider
+	opts     *TxOptions
+}
+
+// TestTxTestSuite runs the transaction helper test suite.
+func TestTxTestSuite(t *testing.T) {
+	suite.Run(t, new(TxTestSuite))
+}
+
+// SetupTest creates the database mock and records the spans.
+func (s *TxTestSuite) SetupTest() {
+	db, sqlMock, err := sqlmock.New()
+	s.Require().NoError(err)
+
+	s.db = db
+	s.sqlMock = sqlMock
+	s.recorder = tracetest.NewSpanRecorder()
+	s.previous = otel.GetTracerProvider()
+	s.opts = &TxOptions{Backoff: time.Millisecond}
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(s.recorder)))
+}
+
+// TearDownTest verifies the database expectations and restores the tracer provider.
+func (s *TxTestSuite) TearDownTest() {
+	s.NoError(s.sqlMock.ExpectationsWereMet())
+	otel.SetTracerProvider(s.previous)
+}
+
+// TestWithTx verifies the transaction is committed and stored in the context.
+func (s *TxTestSuite) TestWithTx() {
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("INSERT INTO orders")).WillReturnResult(sqlmock.NewResult(1, 1))
+	s.sqlMock.ExpectCommit()
+
+	err := WithTx(context.Background(), s.db, nil, func(ctx context.Context, tx *sql.Tx) error {
+		stored, ok := TxFromContext(ctx)
+		s.True(ok)
+		s.Same(tx, stored)
+
+		_, err := tx.ExecContext(ctx, "INSERT INTO orders (id) VALUES (1)")
+		return err
+	})
+
+	s.NoError(err)
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 1)
+	s.Equal("sql.transaction", spans[0].Name())
+	s.Equal(codes.Ok, spans[0].Status().Code)
+}
+
+// TestWithTxRollback verifies the transaction is rolled back when the function fails.
+func (s *TxTestSuite) TestWithTxRollback() {
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectRollback()
+
+	err := WithTx(context.Background(), s.db, s.opts, func(ctx context.Context, tx *sql.Tx) error {
+		return errors.New("fn err")
+	})
+
+	s.EqualError(err, "fn err")
+	s.Equal(codes.Error, s.recorder.Ended()[0].Status().Code)
+}
+
+// TestWithTxPanic verifies the transaction is rolled back when the function panics.
+func (s *TxTestSuite) TestWithTxPanic() {
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectRollback()
+
+	s.PanicsWithValue("fn panic", func() {
+		_ = WithTx(context.Background(), s.db, s.opts, func(ctx context.Context, tx *sql.Tx) error {
+			panic("fn panic")
+		})
+	})
+}
+
+// TestWithTxRetry verifies the transaction is run again after a serialization failure.
+func (s *TxTestSuite) TestWithTxRetry() {
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectRollback()
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectCommit().WillReturnError(&pq.Error{Code: "40P01"})
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectCommit()
+
+	attempts := 0
+	err := WithTx(context.Background(), s.db, s.opts, func(ctx context.Context, tx *sql.Tx) error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("update: %w", &pq.Error{Code: "40001"})
+		}
+
+		return nil
+	})
+
+	s.NoError(err)
+	s.Equal(3, attempts)
+	s.Len(s.recorder.Ended()[0].Events(), 2)
+}
+
+// TestWithTxMaxAttempts verifies the error is returned once the attempts are exhausted.
+func (s *TxTestSuite) TestWithTxMaxAttempts() {
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectRollback()
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectRollback()
+
+	s.opts.MaxAttempts = 2
+	attempts := 0
+	err := WithTx(context.Background(), s.db, s.opts, func(ctx context.Context, tx *sql.Tx) error {
+		attempts++
+		return &pq.Error{Code: "40001"}
+	})
+
+	s.True(IsRetryableTxError(err))
+	s.Equal(2, attempts)
+}
+
+// TestWithTxNested verifies the nested calls reuse the transaction within savepoints.
+func (s *TxTestSuite) TestWithTxNested() {
+	s.sqlMock.ExpectBegin()
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("SAVEPOINT gokit_savepoint_1")).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("SAVEPOINT gokit_savepoint_2")).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("ROLLBACK TO SAVEPOINT gokit_savepoint_2")).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.sqlMock.ExpectExec(regexp.QuoteMeta("RELEASE SAVEPOINT gokit_savepoint_1")).WillReturnResult(sqlmock.NewResult(0, 0))
+	s.sqlMock.ExpectCommit()
+
+	err := WithTx(context.Background(), s.db, nil, func(ctx context.Context, outer *sql.Tx) error {
+		return WithTx(ctx, s.db, nil, func(ctx context.Context, tx *sql.Tx) error {
+			s.Same(outer, tx)
+
+			nestedErr := WithTx(ctx, s.db, nil, func(ctx context.Context, tx *sql.Tx) error {
+				return errors.New("nested err")
+			})
+			s.EqualError(nestedErr, "nested err")
+
+			return nil
+		})
+	})
+
+	s.NoError(err)
+
+	spans := s.recorder.Ended()
+	s.Require().Len(spans, 3)
+	s.Equal("sql.savepoint", spans[0].Name())
+	s.Equal(codes.Error, spans[0].Status().Code)
+	s.Equal("sql.transaction", spans[2].Name())
+}
+
+// TestIsRetryableTxError verifies the retryable SQLSTATE codes.
+func (s *TxTestSuite) TestIsRetryableTxError() {
+	s.True(IsRetryableTxError(&pq.Error{Code: "40001"}))
+	s.True(IsRetryableTxError(fmt.Errorf("wrapped: %w", &pq.Error{Code: "40P01"})))
+	s.False(IsRetryableTxError(&pq.Error{Code: "23505"}))
+	s.False(IsRetryableTxError(errors.New("err")))
+}